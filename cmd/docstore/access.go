@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+//==============================================================================================================================
+//	 Access checks - The document store holds no permissions of its own. A caller may see a document only if the chaincode
+//					 lets them see the program or invoice it belongs to, so the role/account rules stay in one place.
+//==============================================================================================================================
+
+var ErrAccessDenied = errors.New("access denied")
+
+//==============================================================================================================================
+//	Record - The program or invoice a document is requested through.
+//==============================================================================================================================
+type Record struct {
+	Kind string // "program" or "invoice"
+	ID   string
+}
+
+//==============================================================================================================================
+//	Visibility - Runs the chaincode's own detail query for a record as the caller. Returns the document hash recorded on
+//			  the record ("" if none yet), or ErrAccessDenied if the chaincode refused the caller.
+//==============================================================================================================================
+type Visibility interface {
+	RecordedHash(enrollmentID string, record Record) (string, error)
+}
+
+//==============================================================================================================================
+//	PeerVisibility - Visibility backed by the JSON-RPC /chaincode endpoint of a Fabric 0.6 peer. The query is sent with the
+//			  caller's enrollment ID as the secure context and with the role and account attributes requested, so
+//			  the chaincode sees exactly the identity it would for a direct query.
+//==============================================================================================================================
+type PeerVisibility struct {
+	PeerURL   string
+	Chaincode string
+	Client    *http.Client
+}
+
+type rpcRequest struct {
+	JSONRPC string    `json:"jsonrpc"`
+	Method  string    `json:"method"`
+	Params  rpcParams `json:"params"`
+	ID      int       `json:"id"`
+}
+
+type rpcParams struct {
+	Type          int          `json:"type"`
+	ChaincodeID   rpcChaincode `json:"chaincodeID"`
+	CtorMsg       rpcCtorMsg   `json:"ctorMsg"`
+	SecureContext string       `json:"secureContext"`
+	Attributes    []string     `json:"attributes"`
+}
+
+type rpcChaincode struct {
+	Name string `json:"name"`
+}
+
+type rpcCtorMsg struct {
+	Function string   `json:"function"`
+	Args     []string `json:"args"`
+}
+
+type rpcResponse struct {
+	Result *struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	} `json:"result"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    string `json:"data"`
+	} `json:"error"`
+}
+
+func NewPeerVisibility(peerURL, chaincode string) *PeerVisibility {
+	return &PeerVisibility{
+		PeerURL:   peerURL,
+		Chaincode: chaincode,
+		Client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *PeerVisibility) RecordedHash(enrollmentID string, record Record) (string, error) {
+
+	function := "get_anchorprogram_details"
+	if record.Kind == "invoice" {
+		function = "get_invoice_details"
+	}
+
+	request := rpcRequest{
+		JSONRPC: "2.0",
+		Method:  "query",
+		Params: rpcParams{
+			Type:          1,
+			ChaincodeID:   rpcChaincode{Name: p.Chaincode},
+			CtorMsg:       rpcCtorMsg{Function: function, Args: []string{record.ID}},
+			SecureContext: enrollmentID,
+			Attributes:    []string{"role", "account"},
+		},
+		ID: 1,
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := p.Client.Post(p.PeerURL+"/chaincode", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("querying peer: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var reply rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return "", fmt.Errorf("decoding peer response: %v", err)
+	}
+
+	if reply.Error != nil || reply.Result == nil || reply.Result.Status != "OK" {
+		return "", ErrAccessDenied // Permission Denied and unknown records look the same to the caller
+	}
+
+	var details struct {
+		AnchorPoDocument struct {
+			Hash string `json:"hash"`
+		} `json:"anchorpodocument"`
+		InvoiceDocument struct {
+			Hash string `json:"hash"`
+		} `json:"invoicedocument"`
+	}
+
+	if err := json.Unmarshal([]byte(reply.Result.Message), &details); err != nil {
+		return "", fmt.Errorf("decoding %s %s: %v", record.Kind, record.ID, err)
+	}
+
+	if record.Kind == "invoice" {
+		return details.InvoiceDocument.Hash, nil
+	}
+
+	return details.AnchorPoDocument.Hash, nil
+}
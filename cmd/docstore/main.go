@@ -0,0 +1,163 @@
+// Command docstore keeps the PO and invoice files that the chaincode only records by SHA-256 hash.
+//
+// Documents are uploaded and fetched by hash through the program or invoice they belong to:
+//
+//	PUT /documents/<sha256>?program=<anchorProgramID>   (or ?invoice=<moID>)
+//	GET /documents/<sha256>?program=<anchorProgramID>   (or ?invoice=<moID>)
+//
+// The caller's enrollment ID is taken from the X-Enrollment-ID header, which must be set by the authenticating proxy in
+// front of this service. Every request is checked by querying get_anchorprogram_details or get_invoice_details on the
+// peer as that enrollment ID. A request is only served if that query succeeds and the record names the requested hash,
+// so a document is recorded on its program or invoice before it is uploaded.
+//
+// Only the hash a record names now is served. When a program or invoice records a new document, or an invoice is
+// revised, the earlier document stays in the store but can no longer be fetched through this service; the chaincode's
+// verify_document query with the transaction ID still proves what the record held at that point.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const maxDocumentSize = 32 << 20
+
+//==============================================================================================================================
+//	Server - HTTP handlers for the document store.
+//==============================================================================================================================
+type Server struct {
+	Store      Store
+	Visibility Visibility
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	hash := strings.ToLower(strings.TrimPrefix(r.URL.Path, "/documents/"))
+	if hash == r.URL.Path || !valid_hash(hash) {
+		http.Error(w, "expected /documents/<sha256>", http.StatusNotFound)
+		return
+	}
+
+	enrollmentID := r.Header.Get("X-Enrollment-ID")
+	if enrollmentID == "" {
+		http.Error(w, "missing X-Enrollment-ID", http.StatusUnauthorized)
+		return
+	}
+
+	var record Record
+	if id := r.URL.Query().Get("program"); id != "" {
+		record = Record{Kind: "program", ID: id}
+	} else if id := r.URL.Query().Get("invoice"); id != "" {
+		record = Record{Kind: "invoice", ID: id}
+	} else {
+		http.Error(w, "a program or invoice must be named", http.StatusBadRequest)
+		return
+	}
+
+	recorded, err := s.Visibility.RecordedHash(enrollmentID, record)
+	if err == ErrAccessDenied {
+		http.Error(w, "access denied", http.StatusForbidden)
+		return
+	} else if err != nil {
+		log.Printf("checking %s %s for %s: %v", record.Kind, record.ID, enrollmentID, err)
+		http.Error(w, "unable to check access", http.StatusBadGateway)
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if recorded != hash { // Seeing a record gives no access to documents it does not reference, nor lets one be stored under it
+		http.Error(w, "access denied", http.StatusForbidden)
+		return
+	}
+
+	if r.Method == http.MethodPut {
+		s.put(w, r, hash, record)
+	} else {
+		s.get(w, hash)
+	}
+}
+
+func (s *Server) put(w http.ResponseWriter, r *http.Request, hash string, record Record) {
+
+	mediaType := r.Header.Get("Content-Type")
+	if mediaType == "" {
+		mediaType = "application/octet-stream"
+	}
+
+	err := s.Store.Put(hash, mediaType, http.MaxBytesReader(w, r.Body, maxDocumentSize))
+	if err == ErrHashMismatch {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Printf("storing %s for %s %s: %v", hash, record.Kind, record.ID, err)
+		http.Error(w, "unable to store document", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) get(w http.ResponseWriter, hash string) {
+
+	body, mediaType, err := s.Store.Get(hash)
+	if err == ErrNotFound {
+		http.Error(w, "document not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("reading %s: %v", hash, err)
+		http.Error(w, "unable to read document", http.StatusInternalServerError)
+		return
+	}
+	defer body.Close()
+
+	if mediaType != "" {
+		w.Header().Set("Content-Type", mediaType)
+	}
+	w.Header().Set("ETag", `"`+hash+`"`)
+
+	io.Copy(w, body)
+}
+
+func main() {
+
+	listen := flag.String("listen", ":7080", "address to serve on")
+	backend := flag.String("backend", "fs", "storage backend: fs, or s3 for an S3 compatible object store")
+	root := flag.String("root", "documents", "directory for the fs backend, or for the local stand-in of the s3 backend")
+	bucket := flag.String("bucket", "documents", "bucket for the s3 backend")
+	peer := flag.String("peer", "http://localhost:7050", "REST address of a peer")
+	chaincode := flag.String("chaincode", "", "chaincode name to query for access checks")
+	flag.Parse()
+
+	if *chaincode == "" {
+		fmt.Fprintln(os.Stderr, "docstore: -chaincode is required")
+		os.Exit(2)
+	}
+
+	var store Store
+	switch *backend {
+	case "fs":
+		store = &FileStore{Root: *root}
+	case "s3":
+		store = &ObjectBackedStore{Objects: &LocalObjectStore{Root: *root}, Bucket: *bucket, TempDir: os.TempDir()}
+	default:
+		fmt.Fprintf(os.Stderr, "docstore: unknown backend %q\n", *backend)
+		os.Exit(2)
+	}
+
+	server := &Server{Store: store, Visibility: NewPeerVisibility(*peer, *chaincode)}
+
+	http.Handle("/documents/", server)
+
+	log.Printf("docstore: serving %s backend on %s", *backend, *listen)
+	log.Fatal(http.ListenAndServe(*listen, nil))
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// visibility answers for a fixed set of records, as the chaincode would for the caller "anchor"
+type visibility map[string]string
+
+func (v visibility) RecordedHash(enrollmentID string, record Record) (string, error) {
+
+	if enrollmentID == "broken" {
+		return "", errors.New("peer unreachable")
+	}
+
+	hash, ok := v[record.Kind+"/"+record.ID]
+	if !ok || enrollmentID != "anchor" {
+		return "", ErrAccessDenied
+	}
+
+	return hash, nil
+}
+
+func TestServer(t *testing.T) {
+
+	root := temp_dir(t)
+	defer os.RemoveAll(root)
+
+	po, invoice := "purchase order", "invoice"
+
+	server := &Server{
+		Store:      &FileStore{Root: root},
+		Visibility: visibility{"program/P1": sum(po), "invoice/P1-MO1": sum(invoice), "invoice/P1-MO2": ""},
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		caller string
+		body   string
+		status int
+	}{
+		{"upload a recorded PO", "PUT", "/documents/" + sum(po) + "?program=P1", "anchor", po, http.StatusCreated},
+		{"fetch it", "GET", "/documents/" + sum(po) + "?program=P1", "anchor", "", http.StatusOK},
+		{"upload not referenced by the record", "PUT", "/documents/" + sum("other") + "?program=P1", "anchor", "other", http.StatusForbidden},
+		{"upload before the record names a document", "PUT", "/documents/" + sum(invoice) + "?invoice=P1-MO2", "anchor", invoice, http.StatusForbidden},
+		{"contents do not match", "PUT", "/documents/" + sum(invoice) + "?invoice=P1-MO1", "anchor", "forged", http.StatusBadRequest},
+		{"recorded but not uploaded", "GET", "/documents/" + sum(invoice) + "?invoice=P1-MO1", "anchor", "", http.StatusNotFound},
+		{"fetch through a record that does not reference it", "GET", "/documents/" + sum(po) + "?invoice=P1-MO1", "anchor", "", http.StatusForbidden},
+		{"caller cannot see the record", "GET", "/documents/" + sum(po) + "?program=P1", "vendor", "", http.StatusForbidden},
+		{"no caller", "GET", "/documents/" + sum(po) + "?program=P1", "", "", http.StatusUnauthorized},
+		{"no record", "GET", "/documents/" + sum(po), "anchor", "", http.StatusBadRequest},
+		{"not a hash", "GET", "/documents/P1?program=P1", "anchor", "", http.StatusNotFound},
+		{"peer unreachable", "GET", "/documents/" + sum(po) + "?program=P1", "broken", "", http.StatusBadGateway},
+		{"other methods", "DELETE", "/documents/" + sum(po) + "?program=P1", "anchor", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", "application/pdf")
+		if tt.caller != "" {
+			r.Header.Set("X-Enrollment-ID", tt.caller)
+		}
+
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, r)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, w.Code, tt.status, w.Body.String())
+		}
+		if tt.method == "GET" && w.Code == http.StatusOK && w.Body.String() != po {
+			t.Errorf("%s: got %q", tt.name, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//==============================================================================================================================
+//	 Storage backends - Documents are stored under the hex SHA-256 of their contents, the same hash the chaincode records
+//						in a DocumentRef, so a stored file can always be checked against the ledger.
+//==============================================================================================================================
+
+var ErrNotFound = errors.New("document not found")
+var ErrHashMismatch = errors.New("document contents do not match the hash")
+
+//==============================================================================================================================
+//	Store - Content addressed document storage.
+//==============================================================================================================================
+type Store interface {
+	Put(hash string, mediaType string, body io.Reader) error
+	Get(hash string) (io.ReadCloser, string, error)
+}
+
+//==============================================================================================================================
+//	 valid_hash - Checks hash is a lower case hex SHA-256
+//==============================================================================================================================
+func valid_hash(hash string) bool {
+
+	decoded, err := hex.DecodeString(hash)
+
+	return err == nil && len(decoded) == sha256.Size && hash == strings.ToLower(hash)
+}
+
+//==============================================================================================================================
+//	 spool - Copies body to a temporary file in dir while hashing it, and returns the file once the hash is confirmed.
+//			 Nothing is kept if the contents are not what the caller said they were.
+//==============================================================================================================================
+func spool(dir string, hash string, body io.Reader) (*os.File, error) {
+
+	tmp, err := ioutil.TempFile(dir, "upload-")
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.New()
+
+	_, err = io.Copy(io.MultiWriter(tmp, digest), body)
+	if err == nil && hex.EncodeToString(digest.Sum(nil)) != hash {
+		err = ErrHashMismatch
+	}
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+
+	return tmp, nil
+}
+
+//==============================================================================================================================
+//	FileStore - Keeps documents on the local filesystem as <root>/<hash[0:2]>/<hash>, with the media type alongside
+//			  in <hash>.type.
+//==============================================================================================================================
+type FileStore struct {
+	Root string
+}
+
+func (s *FileStore) path(hash string) string {
+	return filepath.Join(s.Root, hash[:2], hash)
+}
+
+func (s *FileStore) Put(hash string, mediaType string, body io.Reader) error {
+
+	if !valid_hash(hash) {
+		return fmt.Errorf("invalid document hash %q", hash)
+	}
+
+	dir := filepath.Dir(s.path(hash))
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+
+	tmp, err := spool(dir, hash, body)
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+
+	if _, err := os.Stat(s.path(hash)); err == nil { // Same contents whoever wrote it first, so the first media type stands
+		return os.Remove(tmp.Name())
+	}
+
+	if err := ioutil.WriteFile(s.path(hash)+".type", []byte(mediaType), 0640); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path(hash))
+}
+
+func (s *FileStore) Get(hash string) (io.ReadCloser, string, error) {
+
+	if !valid_hash(hash) {
+		return nil, "", ErrNotFound
+	}
+
+	f, err := os.Open(s.path(hash))
+	if os.IsNotExist(err) {
+		return nil, "", ErrNotFound
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	mediaType, _ := ioutil.ReadFile(s.path(hash) + ".type")
+
+	return f, string(mediaType), nil
+}
+
+//==============================================================================================================================
+//	ObjectStore - The subset of an S3 compatible API the document store needs. A client for S3, MinIO or Ceph RGW can be
+//			  wrapped to satisfy it; LocalObjectStore stands in for one during development and testing.
+//==============================================================================================================================
+type ObjectStore interface {
+	PutObject(bucket, key string, body io.ReadSeeker, size int64, contentType string) error
+	GetObject(bucket, key string) (io.ReadCloser, string, error)
+}
+
+//==============================================================================================================================
+//	ObjectBackedStore - Stores documents in a bucket of an ObjectStore under the key sha256/<hash>.
+//==============================================================================================================================
+type ObjectBackedStore struct {
+	Objects ObjectStore
+	Bucket  string
+	TempDir string
+}
+
+func (s *ObjectBackedStore) Put(hash string, mediaType string, body io.Reader) error {
+
+	if !valid_hash(hash) {
+		return fmt.Errorf("invalid document hash %q", hash)
+	}
+
+	tmp, err := spool(s.TempDir, hash, body) // The object store wants the size up front, and the hash is checked first
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	info, err := tmp.Stat()
+	if err != nil {
+		return err
+	}
+
+	return s.Objects.PutObject(s.Bucket, "sha256/"+hash, tmp, info.Size(), mediaType)
+}
+
+func (s *ObjectBackedStore) Get(hash string) (io.ReadCloser, string, error) {
+
+	if !valid_hash(hash) {
+		return nil, "", ErrNotFound
+	}
+
+	return s.Objects.GetObject(s.Bucket, "sha256/"+hash)
+}
+
+//==============================================================================================================================
+//	LocalObjectStore - An ObjectStore that keeps each bucket as a directory under Root.
+//==============================================================================================================================
+type LocalObjectStore struct {
+	Root string
+}
+
+func (o *LocalObjectStore) object(bucket, key string) string {
+	return filepath.Join(o.Root, bucket, filepath.FromSlash(key))
+}
+
+func (o *LocalObjectStore) PutObject(bucket, key string, body io.ReadSeeker, size int64, contentType string) error {
+
+	path := o.object(bucket, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), "object-")
+	if err != nil {
+		return err
+	}
+
+	written, err := io.Copy(f, body)
+	f.Close()
+	if err == nil && written != size {
+		err = fmt.Errorf("object %s/%s: wrote %d bytes, expected %d", bucket, key, written, size)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(path+".content-type", []byte(contentType), 0640)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+func (o *LocalObjectStore) GetObject(bucket, key string) (io.ReadCloser, string, error) {
+
+	path := o.object(bucket, key)
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, "", ErrNotFound
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	contentType, _ := ioutil.ReadFile(path + ".content-type")
+
+	return f, string(contentType), nil
+}
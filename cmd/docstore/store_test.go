@@ -0,0 +1,108 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sum(contents string) string {
+	digest := sha256.Sum256([]byte(contents))
+	return hex.EncodeToString(digest[:])
+}
+
+func temp_dir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "docstore-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestStores(t *testing.T) {
+
+	root := temp_dir(t)
+	defer os.RemoveAll(root)
+
+	stores := []struct {
+		name  string
+		store Store
+	}{
+		{"fs", &FileStore{Root: filepath.Join(root, "fs")}},
+		{"s3", &ObjectBackedStore{Objects: &LocalObjectStore{Root: filepath.Join(root, "s3")}, Bucket: "documents", TempDir: root}},
+	}
+
+	po := "%PDF-1.4 purchase order"
+
+	tests := []struct {
+		name      string
+		hash      string
+		mediaType string
+		body      string
+		ok        bool
+	}{
+		{"stored under its hash", sum(po), "application/pdf", po, true},
+		{"contents do not match the hash", sum(po), "application/pdf", "something else", false},
+		{"stored again", sum(po), "application/pdf", po, true},
+		{"upper case hash", strings.ToUpper(sum("x")), "text/plain", "x", false},
+	}
+
+	for _, s := range stores {
+		for _, tt := range tests {
+			err := s.store.Put(tt.hash, tt.mediaType, strings.NewReader(tt.body))
+			if (err == nil) != tt.ok {
+				t.Errorf("%s %s: error %v, want ok %v", s.name, tt.name, err, tt.ok)
+			}
+		}
+
+		body, mediaType, err := s.store.Get(sum(po))
+		if err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+		contents, _ := ioutil.ReadAll(body)
+		body.Close()
+
+		if string(contents) != po || mediaType != "application/pdf" {
+			t.Errorf("%s: got %q as %q", s.name, contents, mediaType)
+		}
+
+		if _, _, err := s.store.Get(sum("never stored")); err != ErrNotFound {
+			t.Errorf("%s: missing document gave %v, want ErrNotFound", s.name, err)
+		}
+	}
+
+	temps, _ := filepath.Glob(filepath.Join(root, "upload-*"))
+	temps2, _ := filepath.Glob(filepath.Join(root, "fs", "*", "upload-*"))
+	if len(temps)+len(temps2) != 0 {
+		t.Errorf("uploads left behind: %v %v", temps, temps2)
+	}
+}
+
+func TestFileStoreKeepsFirstMediaType(t *testing.T) {
+
+	root := temp_dir(t)
+	defer os.RemoveAll(root)
+
+	store := &FileStore{Root: root}
+	hash := sum("invoice")
+
+	for _, mediaType := range []string{"application/pdf", "text/html"} {
+		if err := store.Put(hash, mediaType, strings.NewReader("invoice")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	body, mediaType, err := store.Get(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body.Close()
+
+	if mediaType != "application/pdf" {
+		t.Errorf("media type %q, want the first one stored", mediaType)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+//==============================================================================================================================
+//	 Entry / Line - The parts of the chaincode's JournalEntry and JournalLine the export needs
+//==============================================================================================================================
+type Entry struct {
+	EntryID         string `json:"entryID"`
+	Date            string `json:"date"`
+	TxID            string `json:"txID"`
+	Event           string `json:"event"`
+	AnchorProgramID string `json:"anchorProgramID"`
+	MOID            string `json:"moID"`
+	Lines           []Line `json:"lines"`
+	Reverses        string `json:"reverses"`
+}
+
+type Line struct {
+	Account   string  `json:"account"`
+	GLCode    string  `json:"glCode"`
+	Debit     float64 `json:"debit"`
+	Credit    float64 `json:"credit"`
+	Narrative string  `json:"narrative"`
+}
+
+var glHeader = []string{
+	"Posting Date",
+	"Entry ID",
+	"Transaction ID",
+	"Event",
+	"Program",
+	"Invoice",
+	"GL Account",
+	"Ledger Account",
+	"Debit",
+	"Credit",
+	"Narrative",
+	"Reverses",
+}
+
+//==============================================================================================================================
+//	 read_entries - Reads the get_journals result, splitting off entries whose lines do not balance to the paisa
+//==============================================================================================================================
+func read_entries(r io.Reader) ([]Entry, []Entry, error) {
+
+	var all []Entry
+
+	err := json.NewDecoder(r).Decode(&all)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading journals: %v", err)
+	}
+
+	var entries, unbalanced []Entry
+
+	for _, e := range all {
+
+		debits, credits := int64(0), int64(0)
+		for _, line := range e.Lines {
+			debits += paise(line.Debit)
+			credits += paise(line.Credit)
+		}
+
+		if debits != credits {
+			unbalanced = append(unbalanced, e)
+			continue
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, unbalanced, nil
+}
+
+//==============================================================================================================================
+//	 write_gl_csv - Writes one row per journal line and returns the number of rows
+//==============================================================================================================================
+func write_gl_csv(w io.Writer, entries []Entry) (int, error) {
+
+	out := csv.NewWriter(w)
+
+	err := out.Write(glHeader)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := 0
+
+	for _, e := range entries {
+		for _, line := range e.Lines {
+
+			err = out.Write([]string{
+				e.Date,
+				e.EntryID,
+				e.TxID,
+				e.Event,
+				e.AnchorProgramID,
+				e.MOID,
+				line.GLCode,
+				line.Account,
+				amount(line.Debit),
+				amount(line.Credit),
+				line.Narrative,
+				e.Reverses,
+			})
+			if err != nil {
+				return rows, err
+			}
+
+			rows++
+		}
+	}
+
+	out.Flush()
+
+	return rows, out.Error()
+}
+
+func amount(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+func paise(value float64) int64 {
+	return int64(value*100 + 0.5)
+}
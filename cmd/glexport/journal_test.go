@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadEntries(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		input      string
+		ok         bool
+		entries    []string
+		unbalanced []string
+	}{
+		{"no entries", `[]`, true, nil, nil},
+		{
+			"balanced to the paisa",
+			`[{"entryID":"J1","lines":[{"account":"RECEIVABLE","debit":1000.10},{"account":"SETTLEMENT","credit":1000.1}]},
+			  {"entryID":"J2","lines":[{"account":"FEE_RECEIVABLE","debit":118},{"account":"FEE_INCOME","credit":100},{"account":"GST_PAYABLE","credit":18}]}]`,
+			true,
+			[]string{"J1", "J2"},
+			nil,
+		},
+		{
+			"unbalanced by a paisa",
+			`[{"entryID":"J1","lines":[{"account":"RECEIVABLE","debit":1000.01},{"account":"SETTLEMENT","credit":1000}]},
+			  {"entryID":"J2","lines":[{"account":"RECEIVABLE","debit":5},{"account":"SETTLEMENT","credit":5}]}]`,
+			true,
+			[]string{"J2"},
+			[]string{"J1"},
+		},
+		{
+			"floating point sums that round to the same paise",
+			`[{"entryID":"J1","lines":[{"debit":0.1},{"debit":0.2},{"credit":0.3}]}]`,
+			true,
+			[]string{"J1"},
+			nil,
+		},
+		{
+			"reversal swaps debit and credit",
+			`[{"entryID":"J0","lines":[{"account":"RECEIVABLE","debit":50.5},{"account":"DISBURSEMENT","credit":50.5}]},
+			  {"entryID":"J1","reverses":"J0","lines":[{"account":"RECEIVABLE","credit":50.5},{"account":"DISBURSEMENT","debit":50.5}]}]`,
+			true,
+			[]string{"J0", "J1"},
+			nil,
+		},
+		{"not a list of entries", `{"entryID":"J1"}`, false, nil, nil},
+	}
+
+	ids := func(entries []Entry) []string {
+		var result []string
+		for _, e := range entries {
+			result = append(result, e.EntryID)
+		}
+		return result
+	}
+
+	for _, tt := range tests {
+		entries, unbalanced, err := read_entries(strings.NewReader(tt.input))
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: error %v, want ok %v", tt.name, err, tt.ok)
+			continue
+		}
+
+		if got := ids(entries); !reflect.DeepEqual(got, tt.entries) {
+			t.Errorf("%s: entries %v, want %v", tt.name, got, tt.entries)
+		}
+		if got := ids(unbalanced); !reflect.DeepEqual(got, tt.unbalanced) {
+			t.Errorf("%s: unbalanced %v, want %v", tt.name, got, tt.unbalanced)
+		}
+	}
+}
+
+func TestWriteGLCSV(t *testing.T) {
+
+	entries := []Entry{{
+		EntryID:         "J2",
+		Date:            "2017-03-15",
+		TxID:            "tx2",
+		Event:           "SETTLEMENT",
+		AnchorProgramID: "P1",
+		MOID:            "P1-MO1",
+		Reverses:        "J1",
+		Lines: []Line{
+			{Account: "SETTLEMENT", GLCode: "110100", Debit: 1000.1},
+			{Account: "RECEIVABLE", GLCode: "120100", Credit: 1000.1, Narrative: "Invoice P1-MO1, settled"},
+		},
+	}}
+
+	var out bytes.Buffer
+
+	rows, err := write_gl_csv(&out, entries)
+	if err != nil || rows != 2 {
+		t.Fatalf("got %d rows, %v", rows, err)
+	}
+
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		glHeader,
+		{"2017-03-15", "J2", "tx2", "SETTLEMENT", "P1", "P1-MO1", "110100", "SETTLEMENT", "1000.10", "0.00", "", "J1"},
+		{"2017-03-15", "J2", "tx2", "SETTLEMENT", "P1", "P1-MO1", "120100", "RECEIVABLE", "0.00", "1000.10", "Invoice P1-MO1, settled", "J1"},
+	}
+
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("got %q, want %q", records, want)
+	}
+}
@@ -0,0 +1,72 @@
+// Command glexport turns the chaincode's journal entries into a CSV for upload to the general ledger.
+//
+// It reads the result of the get_journals query and writes one row per journal line, with the GL account code
+// configured on the ledger at the time the line was posted:
+//
+//	glexport -in journals.json -out journals.csv
+//
+// Each row carries the entry ID, so an entry that is exported twice can be recognised on upload, and the ID of the
+// entry it reverses, if any. A reversal repeats the original lines with debit and credit swapped, so amounts are never
+// negative. An entry whose lines do not balance is not written; it is reported on standard error and makes the
+// command exit with status 1.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+
+	in := flag.String("in", "-", "get_journals result to read, - for standard input")
+	out := flag.String("out", "-", "file to write, - for standard output")
+	flag.Parse()
+
+	var r io.Reader = os.Stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "glexport: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	entries, unbalanced, err := read_entries(r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "glexport: %v\n", err)
+		os.Exit(1)
+	}
+
+	var w io.WriteCloser = os.Stdout
+	if *out != "-" {
+		w, err = os.Create(*out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "glexport: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	rows, err := write_gl_csv(w, entries)
+	if err == nil {
+		err = w.Close()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "glexport: writing csv: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, e := range unbalanced {
+		fmt.Fprintf(os.Stderr, "glexport: skipped %s: debits and credits do not balance\n", e.EntryID)
+	}
+
+	fmt.Fprintf(os.Stderr, "glexport: %d entries (%d lines) written, %d skipped\n", len(entries), rows, len(unbalanced))
+
+	if len(unbalanced) > 0 {
+		os.Exit(1)
+	}
+}
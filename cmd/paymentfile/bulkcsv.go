@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+//==============================================================================================================================
+//	 Bulk upload CSV - The NEFT/RTGS bulk upload layout taken by the corporate banking portal. Transaction type is N for
+//					   NEFT and R for RTGS; the customer reference carries the invoice MOID.
+//==============================================================================================================================
+
+var bulkHeader = []string{
+	"Transaction Type",
+	"Debit Account No",
+	"Beneficiary Account No",
+	"Beneficiary Name",
+	"Amount",
+	"Value Date",
+	"IFSC Code",
+	"Beneficiary Bank",
+	"Customer Reference No",
+	"Remarks",
+}
+
+//==============================================================================================================================
+//	 write_bulk_csv - Writes one row per instruction. valueDate is DD/MM/YYYY as the portal expects.
+//==============================================================================================================================
+func write_bulk_csv(w io.Writer, instructions []Instruction, valueDate string) error {
+
+	out := csv.NewWriter(w)
+
+	err := out.Write(bulkHeader)
+	if err != nil {
+		return err
+	}
+
+	for _, in := range instructions {
+
+		kind := "N"
+		if in.Channel == "RTGS" {
+			kind = "R"
+		}
+
+		err = out.Write([]string{
+			kind,
+			in.DebtorAccount,
+			in.CreditorAccount,
+			in.CreditorName,
+			amount(in.Amount),
+			valueDate,
+			in.CreditorIFSC,
+			in.CreditorBank,
+			in.MOID,
+			"Invoice " + in.InvoiceID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+
+	return out.Error()
+}
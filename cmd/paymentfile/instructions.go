@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+//==============================================================================================================================
+//	 Status - Must match STATE_INVOICE_PAYMENT_APPROVED in the chaincode. Invoices in this state have been approved by
+//			  the checkers and are waiting for the bank to make the payment.
+//==============================================================================================================================
+const STATE_INVOICE_PAYMENT_APPROVED = 9
+
+var ifsc_format = regexp.MustCompile(`^[A-Z]{4}0[A-Z0-9]{6}$`)
+
+//==============================================================================================================================
+//	Program / Invoice - The fields of the get_anchorprograms query result that a payment instruction is built from.
+//			  The vendor's bank account is only held on the program, so programs are read rather than invoices.
+//==============================================================================================================================
+type Program struct {
+	AnchorProgramID string    `json:"anchorprogramID"`
+	AnchorName      string    `json:"anchorname"`
+	AnchorAccountNo string    `json:"anchoraccountno"`
+	AnchorIFSCCode  string    `json:"anchorifsc"`
+	VendorFName     string    `json:"vendorfname"`
+	VendorLName     string    `json:"vendorlname"`
+	Vendorbank      string    `json:"vendorbank"`
+	Vendoraccountno string    `json:"vendoraccountno"`
+	Vendorifsccode  string    `json:"vendorifsccode"`
+	Items           []Invoice `json:"invoices"`
+	Frozen          *struct{} `json:"frozen"`
+}
+
+type Invoice struct {
+	MOID               string  `json:"moID"`
+	InvoiceID          string  `json:"invoiceid"`
+	MOStatus           int     `json:"moStatus"`
+	MOReceivableAmount float64 `json:"moReceivableAmount"`
+	PaymentChannel     string  `json:"paymentChannel"`
+	MOPaid             bool    `json:"mopaid"`
+	MOSettled          bool    `json:"mosettled"`
+}
+
+//==============================================================================================================================
+//	Instruction - One payment to make. MOID is carried as the end-to-end reference in every output format so the
+//			  payment can be matched back to its invoice when the bank statement arrives.
+//==============================================================================================================================
+type Instruction struct {
+	MOID            string
+	InvoiceID       string
+	ProgramID       string
+	Channel         string
+	Amount          float64
+	DebtorName      string
+	DebtorAccount   string
+	DebtorIFSC      string
+	CreditorName    string
+	CreditorBank    string
+	CreditorAccount string
+	CreditorIFSC    string
+}
+
+//==============================================================================================================================
+//	Rejection - An approved invoice that could not be turned into an instruction, and why
+//==============================================================================================================================
+type Rejection struct {
+	MOID   string
+	Reason string
+}
+
+//==============================================================================================================================
+//	 read_instructions - Reads a get_anchorprograms result and returns an instruction for each approved, unpaid invoice.
+//						 Invoices are listed once even when a revised program repeats them.
+//==============================================================================================================================
+func read_instructions(r io.Reader) ([]Instruction, []Rejection, error) {
+
+	var programs []Program
+
+	err := json.NewDecoder(r).Decode(&programs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading programs: %v", err)
+	}
+
+	var instructions []Instruction
+	var rejections []Rejection
+
+	seen := make(map[string]bool)
+
+	for _, p := range programs {
+		for _, x := range p.Items {
+
+			if x.MOStatus != STATE_INVOICE_PAYMENT_APPROVED || x.MOPaid || x.MOSettled || seen[x.MOID] {
+				continue
+			}
+			seen[x.MOID] = true
+
+			in := Instruction{
+				MOID:            x.MOID,
+				InvoiceID:       x.InvoiceID,
+				ProgramID:       p.AnchorProgramID,
+				Channel:         strings.ToUpper(x.PaymentChannel),
+				Amount:          x.MOReceivableAmount,
+				DebtorName:      p.AnchorName,
+				DebtorAccount:   p.AnchorAccountNo,
+				DebtorIFSC:      strings.ToUpper(p.AnchorIFSCCode),
+				CreditorName:    strings.TrimSpace(p.VendorFName + " " + p.VendorLName),
+				CreditorBank:    p.Vendorbank,
+				CreditorAccount: p.Vendoraccountno,
+				CreditorIFSC:    strings.ToUpper(p.Vendorifsccode),
+			}
+
+			reason := check_instruction(in)
+			if p.Frozen != nil {
+				reason = "program is frozen"
+			}
+
+			if reason != "" {
+				rejections = append(rejections, Rejection{MOID: x.MOID, Reason: reason})
+				continue
+			}
+
+			instructions = append(instructions, in)
+		}
+	}
+
+	return instructions, rejections, nil
+}
+
+//==============================================================================================================================
+//	 check_instruction - Returns why an instruction cannot be sent to the bank, or "" if it can. Masked values mean the
+//						 programs were queried by a caller not entitled to see bank details in full.
+//==============================================================================================================================
+func check_instruction(in Instruction) string {
+
+	if in.Channel != "NEFT" && in.Channel != "RTGS" {
+		return "unsupported payment channel " + in.Channel
+	}
+
+	if in.Amount <= 0 {
+		return "no receivable amount"
+	}
+
+	if in.Channel == "RTGS" && in.Amount < 200000 {
+		return "amount below the RTGS minimum of 200000"
+	}
+
+	for _, value := range []string{in.DebtorAccount, in.DebtorIFSC, in.CreditorAccount, in.CreditorIFSC} {
+		if value == "" || value == "UNDEFINED" || strings.HasPrefix(value, "****") || strings.HasPrefix(value, "enc:") {
+			return "bank details missing or masked, query the programs as the program owner or an admin"
+		}
+	}
+
+	if !ifsc_format.MatchString(in.DebtorIFSC) || !ifsc_format.MatchString(in.CreditorIFSC) {
+		return "invalid IFSC"
+	}
+
+	if in.CreditorName == "" {
+		return "no beneficiary name"
+	}
+
+	return ""
+}
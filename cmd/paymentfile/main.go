@@ -0,0 +1,114 @@
+// Command paymentfile turns invoices approved for payment into files for the core banking system, so they do not have
+// to be keyed in by hand.
+//
+// It reads the result of the get_anchorprograms query, taken by the program owner or an admin so bank details are not
+// masked, and writes every invoice in STATE_INVOICE_PAYMENT_APPROVED that is not yet paid as either an ISO 20022
+// pain.001 message or the NEFT/RTGS bulk upload CSV:
+//
+//	paymentfile -format pain001 -in programs.json -out payments.xml
+//	paymentfile -format csv -in programs.json -out payments.csv
+//
+// Each payment carries the invoice MOID as its reference (EndToEndId in pain.001, Customer Reference No in the CSV) so
+// the bank statement can be reconciled against the ledger. Invoices that cannot be paid as they stand are listed on
+// standard error and make the command exit with status 1. When there is nothing to pay no file is written, as a
+// pain.001 message needs at least one payment.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+func main() {
+
+	in := flag.String("in", "-", "get_anchorprograms result to read, - for standard input")
+	out := flag.String("out", "-", "file to write, - for standard output")
+	format := flag.String("format", "pain001", "output format: pain001 or csv")
+	msgID := flag.String("msgid", "", "pain.001 message ID, by default PAY-<timestamp>")
+	initiator := flag.String("initiator", "", "pain.001 initiating party name")
+	date := flag.String("date", "", "requested execution date as YYYY-MM-DD, by default today")
+	flag.Parse()
+
+	if *format != "pain001" && *format != "csv" {
+		fmt.Fprintf(os.Stderr, "paymentfile: unknown format %q\n", *format)
+		os.Exit(2)
+	}
+
+	now := time.Now()
+
+	execution := now
+	if *date != "" {
+		var err error
+		execution, err = time.Parse("2006-01-02", *date)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "paymentfile: invalid -date %q\n", *date)
+			os.Exit(2)
+		}
+	}
+
+	if *msgID == "" {
+		*msgID = "PAY-" + now.Format("20060102150405")
+	}
+
+	var r io.Reader = os.Stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "paymentfile: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	instructions, rejections, err := read_instructions(r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "paymentfile: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, rejection := range rejections {
+		fmt.Fprintf(os.Stderr, "paymentfile: skipped %s: %s\n", rejection.MOID, rejection.Reason)
+	}
+
+	if len(instructions) == 0 {
+		fmt.Fprintf(os.Stderr, "paymentfile: no payments to write, %d skipped\n", len(rejections))
+		if len(rejections) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	var w io.WriteCloser = os.Stdout
+	if *out != "-" {
+		w, err = os.Create(*out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "paymentfile: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *format == "pain001" {
+		err = write_pain001(w, instructions, *msgID, *initiator, now, execution.Format("2006-01-02"))
+	} else {
+		err = write_bulk_csv(w, instructions, execution.Format("02/01/2006"))
+	}
+
+	if err == nil {
+		err = w.Close()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "paymentfile: writing %s: %v\n", *format, err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "paymentfile: %d payments written, %d skipped\n", len(instructions), len(rejections))
+
+	if len(rejections) > 0 {
+		os.Exit(1)
+	}
+}
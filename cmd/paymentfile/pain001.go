@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+//==============================================================================================================================
+//	 pain.001 - ISO 20022 CustomerCreditTransferInitiationV03. One PmtInf block is written per debit account and channel,
+//				with the channel as the local instrument. Banks are identified by IFSC under the INFSC clearing system.
+//==============================================================================================================================
+
+const pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+
+type painDocument struct {
+	XMLName xml.Name       `xml:"Document"`
+	Xmlns   string         `xml:"xmlns,attr"`
+	Initn   painInitiation `xml:"CstmrCdtTrfInitn"`
+}
+
+type painInitiation struct {
+	GrpHdr painGroupHeader   `xml:"GrpHdr"`
+	PmtInf []painPaymentInfo `xml:"PmtInf"`
+}
+
+type painGroupHeader struct {
+	MsgId    string    `xml:"MsgId"`
+	CreDtTm  string    `xml:"CreDtTm"`
+	NbOfTxs  int       `xml:"NbOfTxs"`
+	CtrlSum  string    `xml:"CtrlSum"`
+	InitgPty painParty `xml:"InitgPty"`
+}
+
+type painPaymentInfo struct {
+	PmtInfId    string            `xml:"PmtInfId"`
+	PmtMtd      string            `xml:"PmtMtd"`
+	NbOfTxs     int               `xml:"NbOfTxs"`
+	CtrlSum     string            `xml:"CtrlSum"`
+	PmtTpInf    painPaymentType   `xml:"PmtTpInf"`
+	ReqdExctnDt string            `xml:"ReqdExctnDt"`
+	Dbtr        painParty         `xml:"Dbtr"`
+	DbtrAcct    painAccount       `xml:"DbtrAcct"`
+	DbtrAgt     painAgent         `xml:"DbtrAgt"`
+	CdtTrfTxInf []painTransaction `xml:"CdtTrfTxInf"`
+}
+
+type painPaymentType struct {
+	LclInstrm struct {
+		Prtry string `xml:"Prtry"`
+	} `xml:"LclInstrm"`
+}
+
+type painParty struct {
+	Nm string `xml:"Nm"`
+}
+
+type painAccount struct {
+	Id struct {
+		Othr struct {
+			Id string `xml:"Id"`
+		} `xml:"Othr"`
+	} `xml:"Id"`
+}
+
+type painAgent struct {
+	FinInstnId struct {
+		ClrSysMmbId struct {
+			ClrSysId struct {
+				Cd string `xml:"Cd"`
+			} `xml:"ClrSysId"`
+			MmbId string `xml:"MmbId"`
+		} `xml:"ClrSysMmbId"`
+	} `xml:"FinInstnId"`
+}
+
+type painTransaction struct {
+	PmtId struct {
+		InstrId    string `xml:"InstrId"`
+		EndToEndId string `xml:"EndToEndId"`
+	} `xml:"PmtId"`
+	Amt struct {
+		InstdAmt painAmount `xml:"InstdAmt"`
+	} `xml:"Amt"`
+	CdtrAgt  painAgent   `xml:"CdtrAgt"`
+	Cdtr     painParty   `xml:"Cdtr"`
+	CdtrAcct painAccount `xml:"CdtrAcct"`
+	RmtInf   struct {
+		Ustrd string `xml:"Ustrd"`
+	} `xml:"RmtInf"`
+}
+
+type painAmount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+func amount(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+func account(id string) painAccount {
+	var a painAccount
+	a.Id.Othr.Id = id
+	return a
+}
+
+func agent(ifsc string) painAgent {
+	var a painAgent
+	a.FinInstnId.ClrSysMmbId.ClrSysId.Cd = "INFSC"
+	a.FinInstnId.ClrSysMmbId.MmbId = ifsc
+	return a
+}
+
+//==============================================================================================================================
+//	 write_pain001 - Writes the instructions as a pain.001 message. Instruction and end-to-end IDs are the invoice MOID.
+//					 The schema needs at least one PmtInf block, so there must be at least one instruction.
+//==============================================================================================================================
+func write_pain001(w io.Writer, instructions []Instruction, msgID, initiator string, created time.Time, executionDate string) error {
+
+	if len(instructions) == 0 {
+		return errors.New("a pain.001 message needs at least one payment")
+	}
+
+	doc := painDocument{Xmlns: pain001Namespace}
+
+	doc.Initn.GrpHdr.MsgId = msgID
+	doc.Initn.GrpHdr.CreDtTm = created.Format("2006-01-02T15:04:05")
+	doc.Initn.GrpHdr.InitgPty.Nm = initiator
+
+	blocks := make(map[string]int)
+	var total float64
+
+	for _, in := range instructions {
+
+		key := in.DebtorAccount + "|" + in.Channel
+
+		i, ok := blocks[key]
+		if !ok {
+			var block painPaymentInfo
+			block.PmtInfId = msgID + "-" + strconv.Itoa(len(doc.Initn.PmtInf)+1)
+			block.PmtMtd = "TRF"
+			block.PmtTpInf.LclInstrm.Prtry = in.Channel
+			block.ReqdExctnDt = executionDate
+			block.Dbtr.Nm = in.DebtorName
+			block.DbtrAcct = account(in.DebtorAccount)
+			block.DbtrAgt = agent(in.DebtorIFSC)
+
+			i = len(doc.Initn.PmtInf)
+			blocks[key] = i
+			doc.Initn.PmtInf = append(doc.Initn.PmtInf, block)
+		}
+
+		var tx painTransaction
+		tx.PmtId.InstrId = in.MOID
+		tx.PmtId.EndToEndId = in.MOID
+		tx.Amt.InstdAmt = painAmount{Ccy: "INR", Value: amount(in.Amount)}
+		tx.CdtrAgt = agent(in.CreditorIFSC)
+		tx.Cdtr.Nm = in.CreditorName
+		tx.CdtrAcct = account(in.CreditorAccount)
+		tx.RmtInf.Ustrd = "Invoice " + in.InvoiceID + " MOID " + in.MOID
+
+		block := &doc.Initn.PmtInf[i]
+		block.CdtTrfTxInf = append(block.CdtTrfTxInf, tx)
+		block.NbOfTxs++
+
+		sum, _ := strconv.ParseFloat(block.CtrlSum, 64)
+		block.CtrlSum = amount(sum + in.Amount)
+
+		total += in.Amount
+	}
+
+	doc.Initn.GrpHdr.NbOfTxs = len(instructions)
+	doc.Initn.GrpHdr.CtrlSum = amount(total)
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	err = enc.Encode(doc)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func instruction(moid, debtor, channel string, amount float64) Instruction {
+	return Instruction{
+		MOID:            moid,
+		InvoiceID:       "INV-" + moid,
+		Channel:         channel,
+		Amount:          amount,
+		DebtorName:      "Anchor Ltd",
+		DebtorAccount:   debtor,
+		DebtorIFSC:      "YESB0000001",
+		CreditorName:    "Vendor Pvt",
+		CreditorAccount: "50100200300",
+		CreditorIFSC:    "HDFC0000123",
+	}
+}
+
+func TestWritePain001(t *testing.T) {
+
+	created := time.Date(2017, 3, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		instructions []Instruction
+		ok           bool
+		blocks       []int // transactions in each PmtInf block
+		ctrlSum      string
+	}{
+		{"no instructions", nil, false, nil, ""},
+		{"one payment", []Instruction{instruction("MO1", "001", "NEFT", 1500)}, true, []int{1}, "1500.00"},
+		{
+			"grouped by debit account and channel",
+			[]Instruction{
+				instruction("MO1", "001", "NEFT", 1000.5),
+				instruction("MO2", "001", "RTGS", 250000),
+				instruction("MO3", "001", "NEFT", 99.5),
+				instruction("MO4", "002", "NEFT", 10),
+			},
+			true,
+			[]int{2, 1, 1},
+			"251110.00",
+		},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+
+		err := write_pain001(&out, tt.instructions, "PAY-1", "YBL", created, "2017-03-16")
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: error %v, want ok %v", tt.name, err, tt.ok)
+			continue
+		}
+		if !tt.ok {
+			if out.Len() != 0 {
+				t.Errorf("%s: wrote %d bytes, want none", tt.name, out.Len())
+			}
+			continue
+		}
+
+		var doc painDocument
+		if err := xml.Unmarshal(out.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if doc.Xmlns != pain001Namespace {
+			t.Errorf("%s: namespace %q", tt.name, doc.Xmlns)
+		}
+		if doc.Initn.GrpHdr.NbOfTxs != len(tt.instructions) || doc.Initn.GrpHdr.CtrlSum != tt.ctrlSum {
+			t.Errorf("%s: header has %d payments for %s, want %d for %s", tt.name,
+				doc.Initn.GrpHdr.NbOfTxs, doc.Initn.GrpHdr.CtrlSum, len(tt.instructions), tt.ctrlSum)
+		}
+		if doc.Initn.GrpHdr.CreDtTm != "2017-03-15T10:30:00" {
+			t.Errorf("%s: created %q", tt.name, doc.Initn.GrpHdr.CreDtTm)
+		}
+
+		if len(doc.Initn.PmtInf) != len(tt.blocks) {
+			t.Fatalf("%s: %d PmtInf blocks, want %d", tt.name, len(doc.Initn.PmtInf), len(tt.blocks))
+		}
+
+		seen := make(map[string]bool)
+		for i, block := range doc.Initn.PmtInf {
+			if block.NbOfTxs != tt.blocks[i] || len(block.CdtTrfTxInf) != tt.blocks[i] {
+				t.Errorf("%s: block %d has %d payments, want %d", tt.name, i, len(block.CdtTrfTxInf), tt.blocks[i])
+			}
+			if block.ReqdExctnDt != "2017-03-16" || block.DbtrAgt.FinInstnId.ClrSysMmbId.ClrSysId.Cd != "INFSC" {
+				t.Errorf("%s: block %d execution date %q, clearing system %q", tt.name, i,
+					block.ReqdExctnDt, block.DbtrAgt.FinInstnId.ClrSysMmbId.ClrSysId.Cd)
+			}
+			for _, tx := range block.CdtTrfTxInf {
+				if tx.PmtId.EndToEndId != tx.PmtId.InstrId || tx.Amt.InstdAmt.Ccy != "INR" {
+					t.Errorf("%s: payment %s has end-to-end ID %q in %s", tt.name,
+						tx.PmtId.InstrId, tx.PmtId.EndToEndId, tx.Amt.InstdAmt.Ccy)
+				}
+				seen[tx.PmtId.EndToEndId] = true
+			}
+		}
+
+		for _, in := range tt.instructions {
+			if !seen[in.MOID] {
+				t.Errorf("%s: no payment for %s", tt.name, in.MOID)
+			}
+		}
+	}
+}
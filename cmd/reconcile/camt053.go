@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+//==============================================================================================================================
+//	 camt.053 - ISO 20022 BankToCustomerStatement. Elements are matched by local name so any camt.053 version is read.
+//				An entry with transaction details gives one line per transaction, otherwise one line for the entry.
+//==============================================================================================================================
+
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	ID      string      `xml:"Id"`
+	Account camtAccount `xml:"Acct"`
+	Entries []camtEntry `xml:"Ntry"`
+}
+
+type camtAccount struct {
+	IBAN  string `xml:"Id>IBAN"`
+	Other string `xml:"Id>Othr>Id"`
+}
+
+func (a camtAccount) number() string {
+	if a.IBAN != "" {
+		return a.IBAN
+	}
+	return a.Other
+}
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtEntry struct {
+	Amount          camtAmount      `xml:"Amt"`
+	CreditDebit     string          `xml:"CdtDbtInd"`
+	Reversal        bool            `xml:"RvslInd"`
+	BookingDate     string          `xml:"BookgDt>Dt"`
+	BookingDateTime string          `xml:"BookgDt>DtTm"`
+	ServicerRef     string          `xml:"AcctSvcrRef"`
+	Transactions    []camtTxDetails `xml:"NtryDtls>TxDtls"`
+	Info            string          `xml:"AddtlNtryInf"`
+}
+
+type camtTxDetails struct {
+	Amount       camtAmount  `xml:"Amt"`
+	EndToEndID   string      `xml:"Refs>EndToEndId"`
+	InstrID      string      `xml:"Refs>InstrId"`
+	TxID         string      `xml:"Refs>TxId"`
+	ClearingRef  string      `xml:"Refs>ClrSysRef"`
+	ServicerRef  string      `xml:"Refs>AcctSvcrRef"`
+	DebtorAcct   camtAccount `xml:"RltdPties>DbtrAcct"`
+	CreditorAcct camtAccount `xml:"RltdPties>CdtrAcct"`
+	Remittance   []string    `xml:"RmtInf>Ustrd"`
+	Info         string      `xml:"AddtlTxInf"`
+}
+
+//==============================================================================================================================
+//	 read_camt053 - Returns the lines of every statement in a camt.053 document
+//==============================================================================================================================
+func read_camt053(name string, r io.Reader) ([]Line, error) {
+
+	var doc camtDocument
+
+	err := xml.NewDecoder(r).Decode(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	var lines []Line
+
+	for _, stmt := range doc.Statements {
+		for n, entry := range stmt.Entries {
+
+			base := Line{
+				Account:  stmt.Account.number(),
+				Credit:   entry.CreditDebit == "CRDT",
+				Reversal: entry.Reversal,
+				Date:     entry.BookingDate,
+			}
+			if base.Date == "" && len(entry.BookingDateTime) >= 10 {
+				base.Date = entry.BookingDateTime[:10]
+			}
+
+			details := entry.Transactions
+			if len(details) == 0 {
+				details = []camtTxDetails{{Amount: entry.Amount}}
+			}
+
+			for i, tx := range details {
+
+				line := base
+				line.Source = fmt.Sprintf("%s statement %s entry %d.%d", name, stmt.ID, n+1, i+1)
+
+				value := tx.Amount.Value
+				if value == "" {
+					value = entry.Amount.Value
+				}
+				line.Amount, err = strconv.ParseFloat(strings.TrimSpace(value), 64)
+				if err != nil {
+					return nil, fmt.Errorf("%s: invalid amount %q", line.Source, value)
+				}
+
+				line.UTR = first_utr(tx.ClearingRef, tx.ServicerRef, entry.ServicerRef)
+				line.References = nonempty(tx.EndToEndID, tx.InstrID, tx.TxID)
+				line.Narrative = strings.Join(append(tx.Remittance, tx.Info, entry.Info), " ")
+
+				if line.UTR == "" {
+					line.UTR = find_utr(line.Narrative)
+				}
+
+				if line.Credit {
+					line.Counterparty = tx.DebtorAcct.number()
+				} else {
+					line.Counterparty = tx.CreditorAcct.number()
+				}
+
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	return lines, nil
+}
+
+func nonempty(values ...string) []string {
+	var result []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" && value != "NOTPROVIDED" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
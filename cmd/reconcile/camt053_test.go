@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func camt(entries string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Id>S1</Id>
+      <Acct><Id><Othr><Id>001234567890</Id></Othr></Id></Acct>
+      ` + entries + `
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+}
+
+func TestReadCamt053(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		input string
+		ok    bool
+		want  []summary
+	}{
+		{
+			"debit with transaction details",
+			camt(`<Ntry>
+				<Amt Ccy="INR">1500.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><RvslInd>false</RvslInd>
+				<BookgDt><Dt>2017-03-15</Dt></BookgDt><AcctSvcrRef>BANKREF1</AcctSvcrRef>
+				<NtryDtls><TxDtls>
+					<Refs><EndToEndId>MO1</EndToEndId><InstrId>MO1</InstrId><ClrSysRef>YESBN12345678901</ClrSysRef></Refs>
+					<RltdPties><CdtrAcct><Id><Othr><Id>50100200300</Id></Othr></Id></CdtrAcct></RltdPties>
+				</TxDtls></NtryDtls>
+			</Ntry>`),
+			true,
+			[]summary{{"001234567890", "2017-03-15", 1500, false, false, "YESBN12345678901", []string{"MO1", "MO1"}, "50100200300"}},
+		},
+		{
+			"credit without details, UTR and date time from the entry",
+			camt(`<Ntry>
+				<Amt Ccy="INR">2000.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+				<BookgDt><DtTm>2017-03-16T11:00:00</DtTm></BookgDt><AcctSvcrRef>NOT-A-UTR</AcctSvcrRef>
+				<AddtlNtryInf>RTGS YESBR12345678901234567 SETTLEMENT</AddtlNtryInf>
+			</Ntry>`),
+			true,
+			[]summary{{"001234567890", "2017-03-16", 2000, true, false, "YESBR12345678901234567", nil, ""}},
+		},
+		{
+			"reversal with one line per transaction",
+			camt(`<Ntry>
+				<Amt Ccy="INR">30.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><RvslInd>true</RvslInd>
+				<BookgDt><Dt>2017-03-17</Dt></BookgDt>
+				<NtryDtls>
+					<TxDtls><Amt Ccy="INR">10.00</Amt><Refs><EndToEndId>NOTPROVIDED</EndToEndId><TxId>MO3</TxId></Refs>
+						<RltdPties><DbtrAcct><Id><IBAN>IN00YESB0001</IBAN></Id></DbtrAcct></RltdPties></TxDtls>
+					<TxDtls><Amt Ccy="INR">20.00</Amt><Refs><AcctSvcrRef>123456789012</AcctSvcrRef></Refs></TxDtls>
+				</NtryDtls>
+			</Ntry>`),
+			true,
+			[]summary{
+				{"001234567890", "2017-03-17", 10, true, true, "", []string{"MO3"}, "IN00YESB0001"},
+				{"001234567890", "2017-03-17", 20, true, true, "123456789012", nil, ""},
+			},
+		},
+		{"invalid amount", camt(`<Ntry><Amt Ccy="INR">1,500</Amt><CdtDbtInd>DBIT</CdtDbtInd></Ntry>`), false, nil},
+		{"not XML", "MT940 is not XML", false, nil},
+	}
+
+	for _, tt := range tests {
+		lines, err := read_camt053("test.xml", strings.NewReader(tt.input))
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: error %v, want ok %v", tt.name, err, tt.ok)
+			continue
+		}
+
+		if got := summarise(lines); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
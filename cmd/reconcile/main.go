@@ -0,0 +1,163 @@
+// Command reconcile matches bank statements against the invoices on the ledger, offline, and writes the chaincode
+// calls that record what the bank did.
+//
+// It reads the result of the get_anchorprograms query, taken by the program owner or an admin, and any number of
+// camt.053 (.xml) and MT940 statement files:
+//
+//	reconcile -ledger programs.json -out calls.json -exceptions exceptions.csv statements/*.xml statements/*.sta
+//
+// Lines are matched to invoices by UTR, or by the MOID that paymentfile sends as the payment reference, and then
+// checked by amount and account. A matched payment gives an update_checker_invoice_payment call, its reversal the
+// same call with FAILED or, once the invoice is paid, an update_rev_checker_invoice_payment call, and a matched
+// repayment an update_checker_invoice_settlement call. The calls are written one
+// JSON object per line, each giving the function, its args and the statement line it came from, for a payment checker
+// to submit. Lines that match nothing are written to the exception report for manual review.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func main() {
+
+	ledgerFile := flag.String("ledger", "", "get_anchorprograms result to match against")
+	out := flag.String("out", "-", "file to write the chaincode calls to, - for standard output")
+	exceptions := flag.String("exceptions", "exceptions.csv", "file to write unmatched statement lines to")
+	flag.Parse()
+
+	if *ledgerFile == "" || flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: reconcile -ledger programs.json [-out calls.json] [-exceptions exceptions.csv] statement...")
+		os.Exit(2)
+	}
+
+	f, err := os.Open(*ledgerFile)
+	if err != nil {
+		fail(err)
+	}
+	ledger, err := read_ledger(f)
+	f.Close()
+	if err != nil {
+		fail(err)
+	}
+
+	var lines []Line
+	for _, name := range flag.Args() {
+		read, err := read_statement(name)
+		if err != nil {
+			fail(err)
+		}
+		lines = append(lines, read...)
+	}
+
+	var calls []*Call
+	var unmatched []Exception
+
+	for _, line := range lines {
+		call, reason := ledger.match(line)
+		if call == nil {
+			unmatched = append(unmatched, Exception{Line: line, Reason: reason})
+			continue
+		}
+		calls = append(calls, call)
+	}
+
+	err = write_file(*out, func(w io.Writer) error { return write_calls(w, calls) })
+	if err != nil {
+		fail(err)
+	}
+
+	err = write_file(*exceptions, func(w io.Writer) error { return write_exceptions(w, unmatched) })
+	if err != nil {
+		fail(err)
+	}
+
+	fmt.Fprintf(os.Stderr, "reconcile: %d statement lines, %d matched, %d exceptions\n", len(lines), len(calls), len(unmatched))
+}
+
+//==============================================================================================================================
+//	 read_statement - Reads a statement file as camt.053 if it is XML, otherwise as MT940
+//==============================================================================================================================
+func read_statement(name string) ([]Line, error) {
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(name), ".xml") {
+		return read_camt053(filepath.Base(name), f)
+	}
+
+	return read_mt940(filepath.Base(name), f)
+}
+
+func write_calls(w io.Writer, calls []*Call) error {
+
+	enc := json.NewEncoder(w)
+
+	for _, call := range calls {
+		if err := enc.Encode(call); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func write_exceptions(w io.Writer, exceptions []Exception) error {
+
+	out := csv.NewWriter(w)
+
+	out.Write([]string{"Source", "Account", "Date", "Direction", "Amount", "UTR", "References", "Narrative", "Reason"})
+
+	for _, e := range exceptions {
+		out.Write([]string{
+			e.Line.Source,
+			e.Line.Account,
+			e.Line.Date,
+			direction(e.Line),
+			strconv.FormatFloat(e.Line.Amount, 'f', 2, 64),
+			e.Line.UTR,
+			strings.Join(e.Line.References, " "),
+			strings.TrimSpace(e.Line.Narrative),
+			e.Reason,
+		})
+	}
+
+	out.Flush()
+
+	return out.Error()
+}
+
+func write_file(name string, write func(io.Writer) error) error {
+
+	if name == "-" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	err = write(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "reconcile: %v\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//==============================================================================================================================
+//	 Statuses - Must match the chaincode. Payments are looked for against approved invoices and settlements against
+//				paid ones.
+//==============================================================================================================================
+const STATE_INVOICE_PAYMENT_APPROVED = 9
+const STATE_INVOICE_PAID = 10
+
+//==============================================================================================================================
+//	Program / Invoice - The fields of the get_anchorprograms query result used for matching. The query must be taken
+//			  by the program owner or an admin so the account numbers are not masked.
+//==============================================================================================================================
+type Program struct {
+	AnchorProgramID string    `json:"anchorprogramID"`
+	AnchorAccountNo string    `json:"anchoraccountno"`
+	Vendoraccountno string    `json:"vendoraccountno"`
+	Items           []Invoice `json:"invoices"`
+}
+
+type Invoice struct {
+	MOID               string  `json:"moID"`
+	MOStatus           int     `json:"moStatus"`
+	MOReceivableAmount float64 `json:"moReceivableAmount"`
+	UTRNumber          string  `json:"utrnumber"`
+
+	ProgramID     string `json:"-"`
+	AnchorAccount string `json:"-"`
+	VendorAccount string `json:"-"`
+}
+
+//==============================================================================================================================
+//	Call - An Invoke to make on the chaincode, in the shape of the peer's ctorMsg
+//==============================================================================================================================
+type Call struct {
+	Function string   `json:"function"`
+	Args     []string `json:"args"`
+	Source   string   `json:"source"`
+}
+
+//==============================================================================================================================
+//	Exception - A statement line that could not be matched to an invoice, and why
+//==============================================================================================================================
+type Exception struct {
+	Line   Line
+	Reason string
+}
+
+//==============================================================================================================================
+//	Ledger - The invoices that may still be paid or settled, indexed for matching
+//==============================================================================================================================
+type Ledger struct {
+	invoices []*Invoice
+	matched  map[string]string // MOID to the statement line it was matched to
+}
+
+func read_ledger(r io.Reader) (*Ledger, error) {
+
+	var programs []Program
+
+	err := json.NewDecoder(r).Decode(&programs)
+	if err != nil {
+		return nil, fmt.Errorf("reading programs: %v", err)
+	}
+
+	ledger := &Ledger{matched: make(map[string]string)}
+	seen := make(map[string]bool)
+
+	for _, p := range programs {
+		for _, x := range p.Items {
+
+			if (x.MOStatus != STATE_INVOICE_PAYMENT_APPROVED && x.MOStatus != STATE_INVOICE_PAID) || seen[x.MOID] {
+				continue
+			}
+			seen[x.MOID] = true
+
+			invoice := x
+			invoice.ProgramID = p.AnchorProgramID
+			invoice.AnchorAccount = p.AnchorAccountNo
+			invoice.VendorAccount = p.Vendoraccountno
+
+			ledger.invoices = append(ledger.invoices, &invoice)
+		}
+	}
+
+	return ledger, nil
+}
+
+//==============================================================================================================================
+//	 find - Returns the invoice a line refers to: one whose recorded UTR is the line's, or failing that the one whose MOID
+//			is given as a reference or, longest first, appears in the narrative
+//==============================================================================================================================
+func (l *Ledger) find(line Line) *Invoice {
+
+	if line.UTR != "" {
+		for _, x := range l.invoices {
+			if x.UTRNumber != "" && strings.EqualFold(x.UTRNumber, line.UTR) {
+				return x
+			}
+		}
+	}
+
+	for _, ref := range line.References {
+		for _, x := range l.invoices {
+			if x.MOID == ref {
+				return x
+			}
+		}
+	}
+
+	var found *Invoice
+	for _, x := range l.invoices {
+		if strings.Contains(line.Narrative, x.MOID) && (found == nil || len(x.MOID) > len(found.MOID)) {
+			found = x
+		}
+	}
+
+	return found
+}
+
+//==============================================================================================================================
+//	 match - Matches one statement line. A debit from the anchor's account to the vendor's pays an approved invoice;
+//			 its reversal records the payment as failed, or reverses it once the invoice is paid. A credit from the
+//			 anchor's account of at least the receivable settles a paid invoice, for the amount received. Payment
+//			 amounts must agree exactly, and accounts wherever the statement gives them.
+//==============================================================================================================================
+func (l *Ledger) match(line Line) (*Call, string) {
+
+	x := l.find(line)
+	if x == nil {
+		return nil, "no invoice with this UTR or reference"
+	}
+
+	if previous, ok := l.matched[x.MOID]; ok {
+		return nil, "invoice " + x.MOID + " already matched to " + previous
+	}
+
+	amount := strconv.FormatFloat(line.Amount, 'f', 2, 64)
+
+	settlement := x.MOStatus == STATE_INVOICE_PAID && line.Credit && !line.Reversal
+
+	if settlement && line.Amount < x.MOReceivableAmount-0.005 {
+		return nil, fmt.Sprintf("amount %s is less than %.2f receivable on invoice %s", amount, x.MOReceivableAmount, x.MOID)
+	}
+
+	if !settlement && math.Abs(line.Amount-x.MOReceivableAmount) > 0.005 {
+		return nil, fmt.Sprintf("amount %s does not match %.2f receivable on invoice %s", amount, x.MOReceivableAmount, x.MOID)
+	}
+
+	var call *Call
+
+	switch {
+	case x.MOStatus == STATE_INVOICE_PAYMENT_APPROVED && (!line.Credit || line.Reversal):
+		if !same_account(line.Account, x.AnchorAccount) || !same_account(line.Counterparty, x.VendorAccount) {
+			return nil, "accounts do not match the anchor and vendor accounts of invoice " + x.MOID
+		}
+		if line.UTR == "" {
+			return nil, "no UTR on the payment for invoice " + x.MOID
+		}
+
+		status := "SUCCESS"
+		if line.Reversal || line.Credit {
+			status = "FAILED"
+		}
+		call = &Call{Function: "update_checker_invoice_payment", Args: []string{x.ProgramID, x.MOID, status, line.UTR}}
+
+	case x.MOStatus == STATE_INVOICE_PAID && line.Reversal:
+		if !same_account(line.Account, x.AnchorAccount) || !same_account(line.Counterparty, x.VendorAccount) {
+			return nil, "accounts do not match the anchor and vendor accounts of invoice " + x.MOID
+		}
+
+		call = &Call{Function: "update_rev_checker_invoice_payment", Args: []string{x.ProgramID, x.MOID, "Payment reversed by the bank, " + line.Source}}
+
+	case settlement:
+		if !same_account(line.Counterparty, x.AnchorAccount) {
+			return nil, "remitter does not match the anchor account of invoice " + x.MOID
+		}
+
+		call = &Call{Function: "update_checker_invoice_settlement", Args: []string{x.ProgramID, x.MOID, amount}}
+
+	default:
+		return nil, fmt.Sprintf("a %s is not expected for invoice %s in state %d", direction(line), x.MOID, x.MOStatus)
+	}
+
+	call.Source = line.Source
+	l.matched[x.MOID] = line.Source
+
+	return call, ""
+}
+
+// same_account compares account numbers ignoring spacing and leading zeros. A statement that does not give the
+// account cannot contradict the ledger.
+func same_account(statement, ledger string) bool {
+
+	normalise := func(account string) string {
+		return strings.TrimLeft(strings.Replace(strings.ToUpper(account), " ", "", -1), "0")
+	}
+
+	return statement == "" || normalise(statement) == normalise(ledger)
+}
+
+func direction(line Line) string {
+	switch {
+	case line.Reversal:
+		return "reversal"
+	case line.Credit:
+		return "credit"
+	}
+	return "debit"
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	anchorAccount = "001234567890"
+	vendorAccount = "50100200300"
+	paidUTR       = "YESBR12345678901234567"
+)
+
+func ledger() *Ledger {
+	return &Ledger{
+		invoices: []*Invoice{
+			{MOID: "P1-MO1", MOStatus: STATE_INVOICE_PAYMENT_APPROVED, MOReceivableAmount: 1500, ProgramID: "P1", AnchorAccount: anchorAccount, VendorAccount: vendorAccount},
+			{MOID: "P1-MO2", MOStatus: STATE_INVOICE_PAID, MOReceivableAmount: 2000, UTRNumber: paidUTR, ProgramID: "P1", AnchorAccount: anchorAccount, VendorAccount: vendorAccount},
+			{MOID: "P1-MO22", MOStatus: STATE_INVOICE_PAYMENT_APPROVED, MOReceivableAmount: 75, ProgramID: "P1", AnchorAccount: anchorAccount, VendorAccount: vendorAccount},
+		},
+		matched: make(map[string]string),
+	}
+}
+
+func TestLedgerMatch(t *testing.T) {
+
+	payment := Line{Source: "s", Account: anchorAccount, Amount: 1500, UTR: "YESBN12345678901", References: []string{"P1-MO1"}, Counterparty: vendorAccount}
+
+	with := func(change func(*Line)) Line {
+		line := payment
+		change(&line)
+		return line
+	}
+
+	tests := []struct {
+		name   string
+		line   Line
+		call   *Call
+		reason string // start of the exception reason when no call is made
+	}{
+		{"payment", payment, &Call{"update_checker_invoice_payment", []string{"P1", "P1-MO1", "SUCCESS", "YESBN12345678901"}, "s"}, ""},
+		{"account with leading zeros dropped", with(func(l *Line) { l.Account = "1234567890" }), &Call{"update_checker_invoice_payment", []string{"P1", "P1-MO1", "SUCCESS", "YESBN12345678901"}, "s"}, ""},
+		{"payment reversed before it was recorded", with(func(l *Line) { l.Reversal = true }), &Call{"update_checker_invoice_payment", []string{"P1", "P1-MO1", "FAILED", "YESBN12345678901"}, "s"}, ""},
+		{"MOID in the narrative, longest first", with(func(l *Line) { l.References = nil; l.Amount = 75; l.Narrative = "NEFT P1-MO22" }), &Call{"update_checker_invoice_payment", []string{"P1", "P1-MO22", "SUCCESS", "YESBN12345678901"}, "s"}, ""},
+		{"payment amount differs", with(func(l *Line) { l.Amount = 1499 }), nil, "amount 1499.00 does not match"},
+		{"payment to another account", with(func(l *Line) { l.Counterparty = "999" }), nil, "accounts do not match"},
+		{"payment without UTR", with(func(l *Line) { l.UTR = "" }), nil, "no UTR"},
+		{"unknown invoice", with(func(l *Line) { l.UTR = ""; l.References = []string{"P9-MO1"} }), nil, "no invoice"},
+		{
+			"paid invoice reversed",
+			Line{Source: "s", Account: anchorAccount, Amount: 2000, UTR: paidUTR, Reversal: true, Counterparty: vendorAccount},
+			&Call{"update_rev_checker_invoice_payment", []string{"P1", "P1-MO2", "Payment reversed by the bank, s"}, "s"},
+			"",
+		},
+		{
+			"settlement for more than the receivable",
+			Line{Source: "s", Account: "BANK", Amount: 2010.25, Credit: true, UTR: paidUTR, Counterparty: anchorAccount},
+			&Call{"update_checker_invoice_settlement", []string{"P1", "P1-MO2", "2010.25"}, "s"},
+			"",
+		},
+		{
+			"settlement short of the receivable",
+			Line{Source: "s", Account: "BANK", Amount: 1999, Credit: true, UTR: paidUTR, Counterparty: anchorAccount},
+			nil,
+			"amount 1999.00 is less than",
+		},
+		{
+			"settlement from another remitter",
+			Line{Source: "s", Account: "BANK", Amount: 2000, Credit: true, UTR: paidUTR, Counterparty: "999"},
+			nil,
+			"remitter does not match",
+		},
+		{
+			"second payment of a paid invoice",
+			Line{Source: "s", Account: anchorAccount, Amount: 2000, UTR: paidUTR, Counterparty: vendorAccount},
+			nil,
+			"a debit is not expected",
+		},
+	}
+
+	for _, tt := range tests {
+		call, reason := ledger().match(tt.line)
+
+		if !reflect.DeepEqual(call, tt.call) {
+			t.Errorf("%s: got call %+v, want %+v", tt.name, call, tt.call)
+		}
+		if tt.call == nil && !strings.HasPrefix(reason, tt.reason) {
+			t.Errorf("%s: got reason %q, want %q...", tt.name, reason, tt.reason)
+		}
+	}
+}
+
+func TestLedgerMatchOnce(t *testing.T) {
+
+	l := ledger()
+	line := Line{Source: "first", Account: anchorAccount, Amount: 1500, UTR: "YESBN12345678901", References: []string{"P1-MO1"}, Counterparty: vendorAccount}
+
+	if call, reason := l.match(line); call == nil {
+		t.Fatalf("first match: %s", reason)
+	}
+
+	line.Source = "second"
+	if call, reason := l.match(line); call != nil || reason != "invoice P1-MO1 already matched to first" {
+		t.Errorf("second match: got %+v, %q", call, reason)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//==============================================================================================================================
+//	 MT940 - SWIFT customer statement. Each :61: statement line becomes a line, with the :86: that follows it as its
+//			 narrative. The customer reference of the :61: carries our reference, the bank reference after // the UTR
+//			 if it has a UTR format.
+//==============================================================================================================================
+
+// :61: value date, optional entry date, R for a reversal, C or D, optional funds code, amount, type, references
+var mt940_line = regexp.MustCompile(`^(\d{6})(\d{4})?(R?)([CD])([A-Z]?)(\d+,\d*)([A-Z0-9]{4})([^/]*)(?://(.*))?$`)
+
+//==============================================================================================================================
+//	 read_mt940 - Returns the statement lines of every statement in an MT940 file
+//==============================================================================================================================
+func read_mt940(name string, r io.Reader) ([]Line, error) {
+
+	var lines []Line
+	var account, tag string
+	var current *Line
+
+	scanner := bufio.NewScanner(r)
+	number := 0
+
+	for scanner.Scan() {
+		number++
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		if strings.HasPrefix(text, ":") && strings.Count(text, ":") >= 2 {
+			end := strings.Index(text[1:], ":") + 1
+			tag = text[1:end]
+			text = text[end+1:]
+		} else if text == "-" || text == "-}" {
+			tag = ""
+			continue
+		} else if tag == "86" && current != nil { // Narrative continues over several lines
+			current.Narrative += " " + text
+			continue
+		} else {
+			continue
+		}
+
+		switch tag {
+		case "25":
+			account = strings.TrimSpace(text)
+			if i := strings.LastIndex(account, "/"); i >= 0 { // BIC/account form
+				account = account[i+1:]
+			}
+
+		case "61":
+			m := mt940_line.FindStringSubmatch(strings.TrimSpace(text))
+			if m == nil {
+				return nil, fmt.Errorf("%s line %d: cannot read statement line %q", name, number, text)
+			}
+
+			amount, err := strconv.ParseFloat(strings.Replace(m[6], ",", ".", 1), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s line %d: invalid amount %q", name, number, m[6])
+			}
+
+			lines = append(lines, Line{
+				Source:     fmt.Sprintf("%s line %d", name, number),
+				Account:    account,
+				Date:       "20" + m[1][0:2] + "-" + m[1][2:4] + "-" + m[1][4:6],
+				Amount:     amount,
+				Credit:     m[4] == "C",
+				Reversal:   m[3] == "R",
+				UTR:        first_utr(m[9]),
+				References: nonempty(m[8]),
+			})
+			current = &lines[len(lines)-1]
+
+		case "86":
+			if current != nil {
+				current.Narrative = text
+			}
+
+		default:
+			current = nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	for i := range lines {
+		if lines[i].UTR == "" {
+			lines[i].UTR = find_utr(lines[i].Narrative)
+		}
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// summary keeps the fields of a line that the readers are responsible for, leaving out Source and Narrative
+type summary struct {
+	Account      string
+	Date         string
+	Amount       float64
+	Credit       bool
+	Reversal     bool
+	UTR          string
+	References   []string
+	Counterparty string
+}
+
+func summarise(lines []Line) []summary {
+	var result []summary
+	for _, line := range lines {
+		result = append(result, summary{line.Account, line.Date, line.Amount, line.Credit, line.Reversal, line.UTR, line.References, line.Counterparty})
+	}
+	return result
+}
+
+func TestReadMT940(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		input string
+		ok    bool
+		want  []summary
+	}{
+		{
+			"UTR from the bank reference",
+			":20:STMT1\n:25:YESBINBB/001234567890\n:28C:1/1\n:60F:C170315INR1000,00\n" +
+				":61:1703150315D1500,00NTRFMO1//YESBN12345678901\n:86:NEFT TO VENDOR\n PVT\n-\n",
+			true,
+			[]summary{{"001234567890", "2017-03-15", 1500, false, false, "YESBN12345678901", []string{"MO1"}, ""}},
+		},
+		{
+			"UTR from the narrative when the bank reference is not one",
+			":25:001234567890\r\n:61:170316C2000,NTRFNONREF//BANKREF1\r\n:86:RTGS YESBR12345678901234567 MO2\r\n-\r\n",
+			true,
+			[]summary{{"001234567890", "2017-03-16", 2000, true, false, "YESBR12345678901234567", []string{"NONREF"}, ""}},
+		},
+		{
+			"reversal without narrative",
+			":25:001234567890\n:61:170317RD1500,00NTRFMO1\n:62F:C170317INR0,00\n",
+			true,
+			[]summary{{"001234567890", "2017-03-17", 1500, false, true, "", []string{"MO1"}, ""}},
+		},
+		{
+			"statement lines of several statements",
+			":25:111\n:61:170315C10,00NTRFA\n-\n:25:222\n:61:170315D20,00NTRFB\n-\n",
+			true,
+			[]summary{
+				{"111", "2017-03-15", 10, true, false, "", []string{"A"}, ""},
+				{"222", "2017-03-15", 20, false, false, "", []string{"B"}, ""},
+			},
+		},
+		{"unreadable statement line", ":25:111\n:61:NOT A STATEMENT LINE\n", false, nil},
+	}
+
+	for _, tt := range tests {
+		lines, err := read_mt940("test.sta", strings.NewReader(tt.input))
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: error %v, want ok %v", tt.name, err, tt.ok)
+			continue
+		}
+
+		if got := summarise(lines); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadMT940Narrative(t *testing.T) {
+
+	lines, err := read_mt940("test.sta", strings.NewReader(":25:111\n:61:170315C10,00NTRFA\n:86:FIRST\nSECOND\n-\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lines) != 1 || lines[0].Narrative != "FIRST SECOND" || lines[0].Source != "test.sta line 2" {
+		t.Errorf("got %+v", lines)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+//==============================================================================================================================
+//	Line - One credit or debit from a bank statement, whichever format it came from
+//==============================================================================================================================
+type Line struct {
+	Source       string // file and position, for the exception report
+	Account      string // account the statement is for
+	Date         string // booking date, YYYY-MM-DD
+	Amount       float64
+	Credit       bool
+	Reversal     bool
+	UTR          string
+	References   []string // end-to-end, instruction and customer references given for the line
+	Counterparty string   // the other party's account, where the statement gives it
+	Narrative    string
+}
+
+//==============================================================================================================================
+//	 UTR formats - NEFT and RTGS UTRs start with the sending bank's IFSC prefix and the channel letter; IMPS uses a
+//				   12 digit retrieval reference. A reference field is only taken as the UTR if it has one of these
+//				   formats; otherwise the UTR is looked for in the narrative text.
+//==============================================================================================================================
+var utr_formats = []*regexp.Regexp{
+	regexp.MustCompile(`\b[A-Z]{4}R[0-9A-Z]{17}\b`),
+	regexp.MustCompile(`\b[A-Z]{4}N[0-9A-Z]{11}\b`),
+	regexp.MustCompile(`\b[0-9]{12}\b`),
+}
+
+func valid_utr(value string) bool {
+
+	value = strings.ToUpper(strings.TrimSpace(value))
+
+	for _, format := range utr_formats {
+		if format.FindString(value) == value && value != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// first_utr returns the first of values that is a valid UTR, or ""
+func first_utr(values ...string) string {
+
+	for _, value := range values {
+		if valid_utr(value) {
+			return strings.ToUpper(strings.TrimSpace(value))
+		}
+	}
+
+	return ""
+}
+
+func find_utr(text string) string {
+
+	text = strings.ToUpper(text)
+
+	for _, format := range utr_formats {
+		if utr := format.FindString(text); utr != "" {
+			return utr
+		}
+	}
+
+	return ""
+}
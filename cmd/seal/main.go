@@ -0,0 +1,87 @@
+// Command seal encrypts personal, bank and commercial details on the client, before they are sent to the chaincode,
+// so they never appear in the clear in a transaction or a block.
+//
+// It reads the key from the same file the validating peers hold in their key directory and prints the sealed value
+// for one field:
+//
+//	seal -key pii.key -field vendorpanno ABCDE1234F
+//	seal -key pii.key -field kyc_PAN < pan.json
+//	seal -key terms.key -collection terms_AP1 -field anchorlimit 5000000
+//
+// The field name is the one the chaincode opens the value under: the JSON name of a program field, or kyc_<itemType>
+// for a KYC item. Commercial terms are sealed with the key of the program's collection and bound to the collection
+// as well. With no value argument the value is read from standard input.
+//
+// The chaincode never opens personal details when they are written, so the values it would otherwise work out from
+// the PII key are made here too: the check value register_pii_key records, and the PAN digest update_vendor_details
+// takes with a sealed PAN:
+//
+//	seal -key pii.key -check
+//	seal -key pii.key -pandigest ABCDE1234F
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+func main() {
+
+	keyFile := flag.String("key", "", "file holding the key, 32 bytes base64 encoded")
+	field := flag.String("field", "", "field the value is sealed for")
+	collection := flag.String("collection", "", "collection of the program, for commercial terms")
+	check := flag.Bool("check", false, "print the key's check value for register_pii_key")
+	digest := flag.Bool("pandigest", false, "print the digest of a PAN for update_vendor_details")
+	flag.Parse()
+
+	if *keyFile == "" || (*field == "" && !*check && !*digest) || flag.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "usage: seal -key file [-collection name] -field name [value]")
+		fmt.Fprintln(os.Stderr, "       seal -key file -check")
+		fmt.Fprintln(os.Stderr, "       seal -key file -pandigest [pan]")
+		os.Exit(2)
+	}
+
+	key, err := read_key(*keyFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seal: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *check {
+		fmt.Println(key_check(key))
+		return
+	}
+
+	var value string
+	if flag.NArg() == 1 {
+		value = flag.Arg(0)
+	} else {
+		bytes, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "seal: %v\n", err)
+			os.Exit(1)
+		}
+		value = strings.TrimSuffix(string(bytes), "\n")
+	}
+
+	if *digest {
+		fmt.Println(pan_digest(key, value))
+		return
+	}
+
+	name := *field
+	if *collection != "" {
+		name = *collection + "|" + name
+	}
+
+	sealed, err := seal(key, name, value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seal: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(sealed)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"strings"
+)
+
+//==============================================================================================================================
+//	 PII_SEALED - Prefix of a value sealed on the client, as the chaincode expects it
+//==============================================================================================================================
+const PII_SEALED = "enc:v2:"
+
+//==============================================================================================================================
+//	 read_key - Reads a key file, 32 bytes base64 encoded
+//==============================================================================================================================
+func read_key(path string) ([]byte, error) {
+
+	encoded, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("invalid key " + path + ", expected 32 bytes base64 encoded")
+	}
+
+	return key, nil
+}
+
+//==============================================================================================================================
+//	 seal - Encrypts value with AES-256-GCM under a random nonce, with the field name as additional data, and returns
+//			PII_SEALED + base64(nonce | ciphertext)
+//==============================================================================================================================
+func seal(key []byte, field, value string) (string, error) {
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(value), []byte(field))
+
+	return PII_SEALED + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+//==============================================================================================================================
+//	 key_check - The value register_pii_key records to recognise the key without storing it, as pii_key_check does in
+//				 the chaincode
+//==============================================================================================================================
+func key_check(key []byte) string {
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("pii-key-check"))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+//==============================================================================================================================
+//	 pan_digest - Keyed hash of a PAN, which the chaincode compares to find invoices from the same vendor
+//==============================================================================================================================
+func pan_digest(key []byte, pan string) string {
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("pan|" + strings.ToUpper(strings.TrimSpace(pan))))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
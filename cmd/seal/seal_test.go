@@ -0,0 +1,92 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func open(t *testing.T, key []byte, field, value string) (string, error) {
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, PII_SEALED))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := aes.NewCipher(key)
+	gcm, _ := cipher.NewGCM(block)
+
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(field))
+	return string(plain), err
+}
+
+func TestSeal(t *testing.T) {
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	tests := []struct {
+		name   string
+		field  string
+		value  string
+		openAs string
+		ok     bool
+	}{
+		{"opens under the same field", "vendorpanno", "ABCDE1234F", "vendorpanno", true},
+		{"empty value", "vendorphone", "", "vendorphone", true},
+		{"bound to the field name", "vendorpanno", "ABCDE1234F", "vendoraccountno", false},
+	}
+
+	for _, tt := range tests {
+		sealed, err := seal(key, tt.field, tt.value)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !strings.HasPrefix(sealed, PII_SEALED) {
+			t.Errorf("%s: got %q, want prefix %q", tt.name, sealed, PII_SEALED)
+		}
+
+		plain, err := open(t, key, tt.openAs, sealed)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: open error %v, want ok %v", tt.name, err, tt.ok)
+		}
+		if tt.ok && plain != tt.value {
+			t.Errorf("%s: opened %q, want %q", tt.name, plain, tt.value)
+		}
+	}
+
+	a, _ := seal(key, "vendorpanno", "ABCDE1234F")
+	b, _ := seal(key, "vendorpanno", "ABCDE1234F")
+	if a == b {
+		t.Errorf("sealing twice gave the same value, the nonce must be random")
+	}
+}
+
+func TestPanDigest(t *testing.T) {
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	other := []byte("fedcba9876543210fedcba9876543210")
+
+	tests := []struct {
+		name string
+		a, b string
+		key  []byte
+		same bool
+	}{
+		{"same PAN", "ABCDE1234F", "ABCDE1234F", key, true},
+		{"case and spacing ignored", "ABCDE1234F", " abcde1234f\n", key, true},
+		{"different PAN", "ABCDE1234F", "ABCDE1234G", key, false},
+		{"different key", "ABCDE1234F", "ABCDE1234F", other, false},
+	}
+
+	for _, tt := range tests {
+		if same := pan_digest(key, tt.a) == pan_digest(tt.key, tt.b); same != tt.same {
+			t.Errorf("%s: same digest %v, want %v", tt.name, same, tt.same)
+		}
+	}
+
+	if len(pan_digest(key, "ABCDE1234F")) != 64 || len(key_check(key)) != 64 || key_check(key) == key_check(other) {
+		t.Errorf("digests must be hex SHA-256 and depend on the key")
+	}
+}
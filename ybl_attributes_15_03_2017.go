@@ -1,3409 +1,10279 @@
-package main
-
-import (
-	"bytes"
-	"encoding/base64"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"strconv"
-	"strings"
-	//"time"
-
-	"github.com/hyperledger/fabric/core/chaincode/shim"
-	"github.com/hyperledger/fabric/core/chaincode/shim/crypto/attr"
-)
-
-//==============================================================================================================================
-//	 Participant types - Each participant type is mapped to an integer which we use to compare to the value stored in a
-//						 user's eCert
-//==============================================================================================================================
-const ROLE_ADMIN = "assigner"
-const ROLE_ANCHOR = "Anchor"
-const ROLE_VENDOR = "Vendor"
-const ROLE_PAYMENT_MAKER = "PaymentMaker"
-const ROLE_PAYMENT_CHECKER = "PaymentChecker"
-
-//==============================================================================================================================
-//	 Status types - Anchor Program lifecycle is broken down into 8 statuses, this is part of the business logic to determine what can
-//					be done to the Credential at points in it's lifecycle
-//==============================================================================================================================
-const STATE_TEMPLATE = 0
-const STATE_PROGRAM_INITIATED = 1
-const STATE_PURCHASE_ORDER_PLACED = 2
-const STATE_INVOICE_RAISED = 3
-const STATE_VENDOR_INVOICE_APPROVED = 4
-const STATE_ANCHOR_AUTHORISED_INVOICE_PAYMENT = 5
-const STATE_INVOICE_PAYMENT_REQUESTED = 6
-const STATE_INVOICE_PAYMENT_INITIATED = 7
-const STATE_INVOICE_PAYMENT_PENDING_APPROVAL = 8
-const STATE_INVOICE_PAYMENT_APPROVED = 9
-const STATE_INVOICE_PAID = 10
-const STATE_INVOICE_SETTLED = 11
-const STATE_ANCHOR_PROGRAM_CLOSED = 12
-const STATE_INVOICE_RETIRED = 20
-
-//==============================================================================================================================
-//	 Structure Definitions
-//==============================================================================================================================
-//	Chaincode - A blank struct for use with Shim (A HyperLedger included go file used for get/put state
-//				and other HyperLedger functions)
-//==============================================================================================================================
-type AssetManagementChaincode struct {
-}
-
-//==============================================================================================================================
-//	AnchorProgram - Defines the structure for an AnchorProgram object. JSON on right tells it what JSON fields to map to
-//			  that element when reading a JSON object into the struct e.g. JSON name -> Struct Name.
-//==============================================================================================================================
-type AnchorProgram struct {
-	Owner                     string      `json:"owner"`
-	POraisedBy                string      `json:"poraisedby"`
-	PORaisedAgainst           string      `json:"poraisedAgainst"`
-	AnchorName                string      `json:"anchorname"`
-	AnchorID                  string      `json:"anchorid"`
-	AnchorAccountNo           string      `json:"anchoraccountno"`
-	AnchorPOAmount            float64     `json:"anchorpoamount"`
-	AnchorIFSCCode            string      `json:"anchorifsc"`
-	AnchorAgreement           string      `json:"anchorAgreement"`
-	VendorAgreement           string      `json:"vendorAgreement"`
-	AnchorLimit               float64     `json:"anchorlimit"`
-	AnchorExpiryDate          string      `json:"anchorexpdate"`
-	AnchorInterest            string      `json:"anchorinterest"`
-	AnchorGarceInterest       string      `json:"anchorgraceinterest"`
-	AnchorGarceInterestperiod string      `json:"anchorgraceinterestperiod"`
-	AnchorPenalInterest       string      `json:"anchorpenalinterest"`
-	AnchorLiquidation         string      `json:"anchorliquidation"`
-	AnchorPoImage             string      `json:"anchorpoimage"`
-	AnchorPoID                string      `json:"anchorpoid"`
-	VendorID                  string      `json:"vendorid"`
-	VendorFName               string      `json:"vendorfname"`
-	VendorLName               string      `json:"vendorlname"`
-	Vendoremail               string      `json:"vendoremail"`
-	Vendorphone               string      `json:"vendorphone"`
-	Vendorpanno               string      `json:"vendorpanno"`
-	Vendoraddress             string      `json:"vendoraddress"`
-	Vendorbank                string      `json:"vendorbank"`
-	Vendorbaddress            string      `json:"vendorbaddress"`
-	Vendoraccountno           string      `json:"vendoraccountno"`
-	Vendorifsccode            string      `json:"vendorifsccode"`
-	Vendorlimit               float64     `json:"vendorlimit"`
-	VendorExpirydate          string      `json:"vendorexpirydate"`
-	//POTimestamp               time.Time   `json:"poRaisedTime"`
-	POAcknowledged            bool        `json:"poacknowledged"`
-	Items                     []MyBoxItem `json:"invoices"`
-	Status                    int         `json:"status"`
-	AnchorProgramID           string      `json:"anchorprogramID"`
-	PoForks                   []string    `json:"poForks"`
-	POParent                  string      `json:"poParent"`
-	PORemarks                 string      `json:"poRemarks"`
-	Settled                   bool        `json:"settled"`
-}
-
-//==============================================================================================================================
-//	MyBoxItem - Defines the structure for an invoice object. JSON on right tells it what JSON fields to map to
-//			  that element when reading a JSON object into the struct e.g. JSON name -> Struct Name.
-//==============================================================================================================================
-type MyBoxItem struct {
-	POID                   string    `json:"poIDr"`
-	MOID                   string    `json:"moID"`
-	MOOwner                string    `json:"moOwner"`
-	AnchorName             string    `json:"anchorname"`
-	AnchorAccountNo        string    `json:"anchoraccountno"`
-	AnchorPOAmount         float64   `json:"anchorpoamount"`
-	AnchorIFSCCode         string    `json:"anchorifsc"`
-	AnchorInterest         string    `json:"anchorinterest"`
-	InvoiceRaisedBy        string    `json:"invraisedby"`
-	InvoiceRaisedAgainst   string    `json:"invraisedAgainst"`
-	MOAmount               float64   `json:"moAmount"`
-	InvoiceID              string    `json:"invoiceid"`
-	InvoiceImage           string    `json:"invoiceimage"`
-	Vendorfname            string    `json:"vendorFname"`
-	Vendorbank             string    `json:"vendorBank"`
-	Vendorifsccode         string    `json:"venDorbank"`
-	AnchorPoID             string    `json:"anchOrPOID"`
-	ApprovedInvoiceAmount  float64   `json:"approvedinvoiceAmount"`
-	MOStatus               int       `json:"moStatus"`
-	SettlementAmount       string    `json:"settlementAmount"`
-	CheckerApprovedPayment bool      `json:"checkerApprovedPayment"`
-	//MOTimestamp            time.Time `json:"invoiceRaisedTime"`
-	MOReceivableAmount     float64   `json:"moReceivableAmount"`
-	PaymentChannel         string    `json:"paymentChannel"`
-	MOPaid                 bool      `json:"mopaid"`
-	TxnStatus              string    `json:"txnStatus"`
-	UTRNumber              string    `json:"utrnumber"`
-	MOForks                []string  `json:"moForks"`
-	MOParent               string    `json:"moParent"`
-	MoOriginal             float64   `json:"moOriginalAmount"`
-	MORemarks              string    `json:"moRemarks"`
-	MOSettled              bool      `json:"mosettled"`
-}
-
-//==============================================================================================================================
-//	Anchor Program Holder - Defines the structure that holds all the anchorIDs for Anchor Program records that have been created.
-//				Used as an index when querying all anchor records.
-//==============================================================================================================================
-
-type Anchor_Program_Holder struct {
-	ANCHOR_PROGRAMs []string `json:"anchorprograms"`
-}
-
-//==============================================================================================================================
-//	ProgramIDs - Defines the structure that holds all the IDs for Anchor Program records that have been created.
-//				Used as an index when querying all anchor records.
-//==============================================================================================================================
-
-type ProgramIDs struct {
-	POraisedBy                string    `json:"poraisedby"`
-	AnchorName                string    `json:"anchorname"`
-	AnchorID                  string    `json:"anchorid"`
-	AnchorAccountNo           string    `json:"anchoraccountno"`
-	AnchorPOAmount            float64   `json:"anchorpoamount"`
-	AnchorIFSCCode            string    `json:"anchorifsc"`
-	AnchorLimit               float64   `json:"anchorlimit"`
-	AnchorExpiryDate          string    `json:"anchorexpdate"`
-	AnchorInterest            string    `json:"anchorinterest"`
-	AnchorGarceInterest       string    `json:"anchorgraceinterest"`
-	AnchorGarceInterestperiod string    `json:"anchorgraceinterestperiod"`
-	AnchorPenalInterest       string    `json:"anchorpenalinterest"`
-	AnchorLiquidation         string    `json:"anchorliquidation"`
-	AnchorPoID                string    `json:"anchorpoid"`
-	VendorID                  string    `json:"vendorid"`
-	VendorFName               string    `json:"vendorfname"`
-	VendorLName               string    `json:"vendorlname"`
-	Vendoremail               string    `json:"vendoremail"`
-	Vendorphone               string    `json:"vendorphone"`
-	Vendorpanno               string    `json:"vendorpanno"`
-	Vendoraddress             string    `json:"vendoraddress"`
-	Vendorbank                string    `json:"vendorbank"`
-	Vendorbaddress            string    `json:"vendorbaddress"`
-	Vendoraccountno           string    `json:"vendoraccountno"`
-	Vendorifsccode            string    `json:"vendorifsccode"`
-	Vendorlimit               float64   `json:"vendorlimit"`
-	VendorExpirydate          string    `json:"vendorexpirydate"`
-	//POTimestamp               time.Time `json:"poRaisedTime"`
-	POAcknowledged            bool      `json:"poacknowledged"`
-	Invoices                  []InvoiceIDs
-	Status                    int    `json:"status"`
-	AnchorProgramID           string `json:"anchorprogramID"`
-	Settled                   bool   `json:"settled"`
-}
-
-//==============================================================================================================================
-//	Invoice Holder - Defines the structure that holds all the invoiceIDs for Invoice records that have been created.
-//				Used as an index when querying all invoice records.
-//==============================================================================================================================
-
-type Invoice_Holder struct {
-	INVOICEs []string `json:"invoices"`
-}
-
-//==============================================================================================================================
-//	InvoiceIDs - Defines the structure that holds all the IDs for invoice records that have been created.
-//				Used as an index when querying all invoice records.
-//==============================================================================================================================
-
-type InvoiceIDs struct {
-	POID                   string    `json:"poIDr"`
-	MOID                   string    `json:"moID"`
-	MOOwner                string    `json:"moOwner"`
-	AnchorName             string    `json:"anchorname"`
-	AnchorAccountNo        string    `json:"anchoraccountno"`
-	AnchorPOAmount         float64   `json:"anchorpoamount"`
-	AnchorIFSCCode         string    `json:"anchorifsc"`
-	AnchorInterest         string    `json:"anchorinterest"`
-	InvoiceRaisedBy        string    `json:"invraisedby"`
-	InvoiceRaisedAgainst   string    `json:"invraisedAgainst"`
-	MOAmount               float64   `json:"moAmount"`
-	InvoiceID              string    `json:"invoiceid"`
-	Vendorfname            string    `json:"vendorFname"`
-	Vendorbank             string    `json:"vendorBank"`
-	Vendorifsccode         string    `json:"venDorbank"`
-	AnchorPoID             string    `json:"anchOrPOID"`
-	ApprovedInvoiceAmount  float64   `json:"approvedinvoiceAmount"`
-	MOStatus               int       `json:"moStatus"`
-	CheckerApprovedPayment bool      `json:"checkerApprovedPayment"`
-	//MOTimestamp            time.Time `json:"invoiceRaisedTime"`
-	MOReceivableAmount     float64   `json:"moReceivableAmount"`
-	PaymentChannel         string    `json:"paymentChannel"`
-	MOPaid                 bool      `json:"mopaid"`
-	UTRNumber              string    `json:"utrnumber"`
-	MOSettled              bool      `json:"mosettled"`
-}
-
-//==============================================================================================================================
-//	Init Function - Called when the user deploys the chaincode
-//==============================================================================================================================
-func (t *AssetManagementChaincode) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
-	//	myLogger.Info("[AssetManagementChaincode] Init")
-
-	var anchorProgramIDs Anchor_Program_Holder
-	var invoiceIDs Invoice_Holder
-
-	bytes, err := json.Marshal(anchorProgramIDs)
-	if err != nil {
-		return nil, errors.New("Error creating Anchor_Program_Holder record")
-	}
-
-	err = stub.PutState("anchorProgramIDs", bytes)
-
-	bites, err := json.Marshal(invoiceIDs)
-	if err != nil {
-		return nil, errors.New("Error creating Invoice_Holder record")
-	}
-
-	err = stub.PutState("invoiceIDs", bites)
-
-	if len(args) != 0 {
-		return nil, errors.New("Incorrect number of arguments. Expecting 0")
-	}
-
-	// Set the role of the users that are allowed to assign assets
-	// The metadata will contain the role of the users that are allowed to assign assets
-	assignerRole, err := stub.GetCallerMetadata()
-	fmt.Printf("Assiger role is %v\n", string(assignerRole))
-
-	if err != nil {
-		return nil, fmt.Errorf("Failed getting metadata, [%v]", err)
-	}
-
-	if len(assignerRole) == 0 {
-		return nil, errors.New("Invalid assigner role. Empty.")
-	}
-
-	stub.PutState("assignerRole", assignerRole)
-
-	return nil, nil
-}
-
-//==============================================================================================================================
-//	 retrieve_invoice - Gets the state of the data at MOID in the ledger then converts it from the stored
-//					JSON into the MyBoxItem struct for use in the contract. Returns the MyBoxItem struct.
-//					Returns empty v if it errors.
-//==============================================================================================================================
-func (t *AssetManagementChaincode) retrieve_invoice(stub shim.ChaincodeStubInterface, moid string) (MyBoxItem, error) {
-
-	var x MyBoxItem
-
-	bytes, err := stub.GetState(moid)
-	if err != nil {
-		fmt.Printf("RETRIEVE_INVOICE: Failed to invoke order_code: %s", err)
-		return x, errors.New("RETRIEVE_INVOICE: Error retrieving order with InvoiceID = " + moid)
-	}
-
-	err = json.Unmarshal(bytes, &x)
-	if err != nil {
-		fmt.Printf("RETRIEVE_INVOICE: Corrupt invoice record "+string(bytes)+": %s", err)
-		return x, errors.New("RETRIEVE_INVOICE: Corrupt invoice record" + string(bytes))
-	}
-
-	return x, nil
-}
-
-//==============================================================================================================================
-//	 retrieve_anchorprogram - Gets the state of the data at poID in the ledger then converts it from the stored
-//					JSON into the AnchorProgram struct for use in the contract. Returns the AnchorProgram struct.
-//					Returns empty v if it errors.
-//==============================================================================================================================
-func (t *AssetManagementChaincode) retrieve_anchorprogram(stub shim.ChaincodeStubInterface, anchorProgramID string) (AnchorProgram, error) {
-
-	var v AnchorProgram
-
-	bytes, err := stub.GetState(anchorProgramID)
-	if err != nil {
-		fmt.Printf("RETRIEVE_ANCHORPROGRAM: Failed to invoke order_code: %s", err)
-		return v, errors.New("RETRIEVE_ANCHORPROGRAM: Error retrieving order with AnchorProgramID = " + anchorProgramID)
-	}
-
-	err = json.Unmarshal(bytes, &v)
-	if err != nil {
-		fmt.Printf("RETRIEVE_ANCHORPROGRAM: Corrupt anchorprogram record "+string(bytes)+": %s", err)
-		return v, errors.New("RETRIEVE_ANCHORPROGRAM: Corrupt anchorprogram record" + string(bytes))
-	}
-
-	return v, nil
-}
-
-//==============================================================================================================================
-// copy_invoice - Copies the MyBoxItem struct as revision. MyBox struct passed in a JSON format. Uses the shim file's
-//				  method 'PutState'.
-//==============================================================================================================================
-func (t *AssetManagementChaincode) copy_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem) (MyBoxItem, error) {
-
-	var box MyBoxItem
-	box = x
-	box.MOID = x.MOID + "-R1"
-	//userb.count = userb.count + 1
-	return box, nil
-
-}
-
-//==============================================================================================================================
-// copy_anchorprogram - Copies the Anchorprogram struct as revision. struct passed in a JSON format. Uses the shim file's
-//				  method 'PutState'.
-//==============================================================================================================================
-func (t *AssetManagementChaincode) copy_anchorprogram(stub shim.ChaincodeStubInterface, v AnchorProgram) (AnchorProgram, error) {
-
-	var pobox AnchorProgram
-	pobox = v
-	pobox.AnchorProgramID = v.AnchorProgramID + "-R1"
-	//userb.count = userb.count + 1
-	return pobox, nil
-
-}
-
-//==============================================================================================================================
-// save_invoice - Writes to the ledger the MyBox struct passed in a JSON format. Uses the shim file's
-//				  method 'PutState'.
-//==============================================================================================================================
-func (t *AssetManagementChaincode) save_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem) (bool, error) {
-
-	bytes, err := json.Marshal(x)
-	if err != nil {
-		fmt.Printf("SAVE_CHANGES: Error converting Invoice record: %s", err)
-		return false, errors.New("Error converting Invoice record")
-	}
-
-	err = stub.PutState(x.MOID, bytes)
-	if err != nil {
-		fmt.Printf("SAVE_CHANGES: Error storing Invoice record: %s", err)
-		return false, errors.New("Error storing Invoice record")
-	}
-
-	return true, nil
-}
-
-//==============================================================================================================================
-// save_changes - Writes to the ledger the Anchor Program struct passed in a JSON format. Uses the shim file's
-//				  method 'PutState'.
-//==============================================================================================================================
-func (t *AssetManagementChaincode) save_changes(stub shim.ChaincodeStubInterface, v AnchorProgram) (bool, error) {
-
-	bytes, err := json.Marshal(v)
-	if err != nil {
-		fmt.Printf("SAVE_CHANGES: Error converting Anchor Progam record: %s", err)
-		return false, errors.New("Error converting Anchor Progam record")
-	}
-
-	err = stub.PutState(v.AnchorProgramID, bytes)
-	if err != nil {
-		fmt.Printf("SAVE_CHANGES: Error storing Anchor Progam record: %s", err)
-		return false, errors.New("Error storing Anchor Progam record")
-	}
-
-	return true, nil
-}
-
-//==============================================================================================================================
-//	 Router Functions
-//==============================================================================================================================
-//	Invoke - Called on chaincode invoke. Takes a function name passed and calls that function. Converts some
-//		  initial arguments passed to other things for use in the called function e.g. name -> ecert
-//==============================================================================================================================
-func (t *AssetManagementChaincode) Invoke(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
-	callerRole, err := stub.ReadCertAttribute("role")
-	if err != nil {
-		fmt.Printf("Error reading attribute 'role' [%v] \n", err)
-		return nil, fmt.Errorf("Failed fetching caller role. Error was [%v]", err)
-	}
-	caller_affiliation := string(callerRole[:])
-
-	callerAccount, err := stub.ReadCertAttribute("account")
-	if err != nil {
-		return nil, fmt.Errorf("Failed fetching caller account. Error was [%v]", err)
-	}
-	if function == "create_anchorprogram" {
-		return t.create_anchorprogram(stub, callerAccount, caller_affiliation, args[0])
-	} else { // If the function is not a create then there must be a order so we need to retrieve the order.
-
-		argPos := 0
-
-		v, err := t.retrieve_anchorprogram(stub, args[argPos])
-		if err != nil {
-			fmt.Printf("INVOKE: Error retrieving Anchor Program: %s", err)
-			return nil, errors.New("Error retrieving Anchor Program")
-		}
-
-		if strings.Contains(function, "update") == false && function != "settlement_anchorprogram" { // If the function is not an update or a scrappage it must be a transfer so we need to get the ecert of the recipient.
-			receiverCert, err := base64.StdEncoding.DecodeString(args[1])
-			if err != nil {
-				fmt.Printf("Error decoding [%v] \n", err)
-				return nil, errors.New("Failed decodinf owner")
-			}
-
-			receiverAcc, err := attr.GetValueFrom("account", receiverCert)
-			if err != nil {
-				fmt.Printf("Error reading account [%v] \n", err)
-				return nil, fmt.Errorf("Failed fetching recipient account. Error was [%v]", err)
-			}
-
-			receiverAccount := string(receiverAcc[:])
-
-			recRole, err := attr.GetValueFrom("role", receiverCert)
-			if err != nil {
-				fmt.Printf("Error reading account [%v] \n", err)
-				return nil, fmt.Errorf("Failed fetching recipient role. Error was [%v]", err)
-			}
-
-			rec_affiliation := string(recRole[:])
-
-			if function == "admin_to_anchor" {
-				return t.admin_to_anchor(stub, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
-			} else if function == "anchor_to_admin_rev" {
-				return t.anchor_to_admin_rev(stub, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation, args[2])
-			} else if function == "anchor_to_vendor" {
-				return t.anchor_to_vendor(stub, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
-			} else if function == "vendor_to_anchor_rev" {
-				return t.vendor_to_anchor_rev(stub, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation, args[2])
-			} else if function == "transfer_vendor_to_anchor_invoice" {
-				x, err := t.retrieve_invoice(stub, args[2])
-				if err != nil {
-					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-					return nil, errors.New("Error retrieving INVOICE")
-				}
-				return t.transfer_vendor_to_anchor_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
-			} else if function == "transfer_rev_anchor_to_vendor_invoice" {
-				x, err := t.retrieve_invoice(stub, args[2])
-				if err != nil {
-					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-					return nil, errors.New("Error retrieving INVOICE")
-				}
-				return t.transfer_rev_anchor_to_vendor_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation, args[3])
-			} else if function == "transfer_anchor_to_vendor_invoice" {
-				x, err := t.retrieve_invoice(stub, args[2])
-				if err != nil {
-					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-					return nil, errors.New("Error retrieving INVOICE")
-				}
-				return t.transfer_anchor_to_vendor_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
-			} else if function == "transfer_rev_vendor_to_anchor_invoice" {
-				x, err := t.retrieve_invoice(stub, args[2])
-				if err != nil {
-					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-					return nil, errors.New("Error retrieving INVOICE")
-				}
-				return t.transfer_rev_vendor_to_anchor_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation, args[3])
-			} else if function == "transfer_vendor_to_admin_invoice" {
-				x, err := t.retrieve_invoice(stub, args[2])
-				if err != nil {
-					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-					return nil, errors.New("Error retrieving INVOICE")
-				}
-				return t.transfer_vendor_to_admin_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
-			} else if function == "transfer_rev_admin_to_vendor_invoice" {
-				x, err := t.retrieve_invoice(stub, args[2])
-				if err != nil {
-					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-					return nil, errors.New("Error retrieving INVOICE")
-				}
-				return t.transfer_rev_admin_to_vendor_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation, args[3])
-			} else if function == "transfer_admin_to_payment_invoice" {
-				x, err := t.retrieve_invoice(stub, args[2])
-				if err != nil {
-					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-					return nil, errors.New("Error retrieving INVOICE")
-				}
-				return t.transfer_admin_to_payment_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
-			} else if function == "transfer_rev_payment_to_admin_invoice" {
-				x, err := t.retrieve_invoice(stub, args[2])
-				if err != nil {
-					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-					return nil, errors.New("Error retrieving INVOICE")
-				}
-				return t.transfer_rev_payment_to_admin_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation, args[3])
-			} else if function == "transfer_payment_maker_to_payment_checker_invoice" {
-				x, err := t.retrieve_invoice(stub, args[2])
-				if err != nil {
-					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-					return nil, errors.New("Error retrieving INVOICE")
-				}
-				return t.transfer_payment_maker_to_payment_checker_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
-			} else if function == "transfer_rev_payment_checker_to_payment_maker_invoice" {
-				x, err := t.retrieve_invoice(stub, args[2])
-				if err != nil {
-					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-					return nil, errors.New("Error retrieving INVOICE")
-				}
-				return t.transfer_rev_payment_checker_to_payment_maker_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation, args[3])
-			} else if function == "transfer_payment_checker_to_payment_maker_invoice" {
-				x, err := t.retrieve_invoice(stub, args[2])
-				if err != nil {
-					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-					return nil, errors.New("Error retrieving INVOICE")
-				}
-				return t.transfer_payment_checker_to_payment_maker_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
-			} /*else if function == "transfer_payment_checker_to_anchor_invoice" {
-				x, err := t.retrieve_invoice(stub, args[2])
-				if err != nil {
-					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-					return nil, errors.New("Error retrieving INVOICE")
-				}
-				return t.transfer_payment_checker_to_anchor_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
-			} else if function == "transfer_anchor_to_payment_checker_invoice" {
-				x, err := t.retrieve_invoice(stub, args[2])
-				if err != nil {
-					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-					return nil, errors.New("Error retrieving INVOICE")
-				}
-				return t.transfer_anchor_to_payment_checker_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
-			}
-			*/
-
-			//transfer payment checker to anchor invoice
-			//transfer anchor to payment checker invoice
-
-		} else if function == "update_anchor_details" {
-			return t.update_anchor_details(stub, v, callerAccount, caller_affiliation, args[1], args[2], args[3], args[4], args[5], args[6], args[7], args[8], args[9], args[10], args[11], args[12])
-		} else if function == "update_vendor_details" {
-			return t.update_vendor_details(stub, v, callerAccount, caller_affiliation, args[1], args[2], args[3], args[4], args[5], args[6], args[7], args[8], args[9], args[10], args[11], args[12], args[13], args[14])
-		} else if function == "update_anchor_purchase_order" {
-			return t.update_anchor_purchase_order(stub, v, callerAccount, caller_affiliation, args[1], args[2], args[3])
-		} else if function == "settlement_anchorprogram" {
-			return t.settlement_anchorprogram(stub, v, callerAccount, caller_affiliation)
-		} else if function == "update_vendor_po_acknowledgement" {
-			return t.update_vendor_po_acknowledgement(stub, v, callerAccount, caller_affiliation)
-		} else if function == "update_vendor_create_invoice" {
-			return t.update_vendor_create_invoice(stub, v, callerAccount, caller_affiliation, args[1])
-		} else if function == "update_vendor_invoice_details" {
-			x, err := t.retrieve_invoice(stub, args[1])
-			if err != nil {
-				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-				return nil, errors.New("Error retrieving INVOICE")
-			}
-			return t.update_vendor_invoice_details(stub, x, v, callerAccount, caller_affiliation, args[2], args[3], args[4])
-		} else if function == "update_anchor_invoice_authorized_amount" {
-			x, err := t.retrieve_invoice(stub, args[1])
-			if err != nil {
-				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-				return nil, errors.New("Error retrieving INVOICE")
-			}
-			return t.update_anchor_invoice_authorized_amount(stub, x, v, callerAccount, caller_affiliation, args[2])
-		} else if function == "update_maker_invoice_payment" {
-			x, err := t.retrieve_invoice(stub, args[1])
-			if err != nil {
-				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-				return nil, errors.New("Error retrieving INVOICE")
-			}
-			return t.update_maker_invoice_payment(stub, x, v, callerAccount, caller_affiliation, args[2], args[3])
-		} else if function == "update_checker_invoice_approval" {
-			x, err := t.retrieve_invoice(stub, args[1])
-			if err != nil {
-				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-				return nil, errors.New("Error retrieving INVOICE")
-			}
-			return t.update_checker_invoice_approval(stub, x, v, callerAccount, caller_affiliation)
-		} else if function == "update_rev_checker_invoice_approval" {
-			x, err := t.retrieve_invoice(stub, args[1])
-			if err != nil {
-				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-				return nil, errors.New("Error retrieving INVOICE")
-			}
-			return t.update_rev_checker_invoice_approval(stub, x, v, callerAccount, caller_affiliation, args[2])
-		} else if function == "update_checker_invoice_payment" {
-			x, err := t.retrieve_invoice(stub, args[1])
-			if err != nil {
-				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-				return nil, errors.New("Error retrieving INVOICE")
-			}
-			return t.update_checker_invoice_payment(stub, x, v, callerAccount, caller_affiliation, args[2], args[3])
-		} else if function == "update_rev_checker_invoice_payment" {
-			x, err := t.retrieve_invoice(stub, args[1])
-			if err != nil {
-				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-				return nil, errors.New("Error retrieving INVOICE")
-			}
-			return t.update_rev_checker_invoice_payment(stub, x, v, callerAccount, caller_affiliation, args[2])
-		} else if function == "update_checker_invoice_settlement" {
-			x, err := t.retrieve_invoice(stub, args[1])
-			if err != nil {
-				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-				return nil, errors.New("Error retrieving INVOICE")
-			}
-			return t.update_checker_invoice_settlement(stub, x, v, callerAccount, caller_affiliation, args[2])
-		} else if function == "update_rev_checker_invoice_settlement" {
-			x, err := t.retrieve_invoice(stub, args[1])
-			if err != nil {
-				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
-				return nil, errors.New("Error retrieving INVOICE")
-			}
-			return t.update_rev_checker_invoice_settlement(stub, x, v, callerAccount, caller_affiliation, args[2])
-		}
-
-		return nil, errors.New("Function of that name doesn't exist.")
-	}
-
-}
-
-//=================================================================================================================================
-//	 Create Function
-//=================================================================================================================================
-//	 Create AnchorProgram - Creates the initial JSON for the order and then saves it to the ledger.
-//=================================================================================================================================
-func (t *AssetManagementChaincode) create_anchorprogram(stub shim.ChaincodeStubInterface, callerAccount []byte, caller_affiliation string, anchorprogramID string) ([]byte, error) {
-	var v AnchorProgram
-
-	v.AnchorProgramID = anchorprogramID
-	v.Owner = string(callerAccount)
-
-	/*matched, err := regexp.Match("^[A-z][A-z][0-9]{7}", []byte(anchorprogramID)) // matched = true if the poID passed fits format of two letters followed by seven digits
-	if err != nil {
-		fmt.Printf("CREATE_ANCHORPROGRAM: Invalid anchorprogramID: %s", err)
-		return nil, errors.New("Invalid anchorprogramID")
-	}*/
-
-	if v.AnchorProgramID == "" { /*||
-		matched == false */
-		fmt.Printf("CREATE_ANCHORPROGRAM: Invalid anchorprogramID provided")
-		return nil, errors.New("Invalid poID provided")
-	}
-
-	record, err := stub.GetState(v.AnchorProgramID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
-	if record != nil {
-		return nil, errors.New("AnchorProgram already exists")
-	}
-
-	// Recover the role that is allowed to create  assets
-	assignerRole, err := stub.GetState("assignerRole")
-	if err != nil {
-		fmt.Printf("Error getting role [%v] \n", err)
-		return nil, errors.New("Failed fetching assigner role")
-	}
-
-	assigner := string(assignerRole[:])
-
-	if caller_affiliation != assigner {
-		fmt.Printf("Caller is not assigner - caller %v assigner %v\n", caller_affiliation, assigner)
-		return nil, fmt.Errorf("The caller does not have the rights to invoke assign. Expected role [%v], caller role [%v]", assigner, caller_affiliation)
-	}
-
-	_, err = t.save_changes(stub, v)
-	if err != nil {
-		fmt.Printf("CREATE_ANCHORPROGRAM: Error saving changes: %s", err)
-		return nil, errors.New("Error saving changes")
-	}
-
-	bytes, err := stub.GetState("anchorProgramIDs")
-	if err != nil {
-		return nil, errors.New("Unable to get anchorProgramIDs")
-	}
-
-	var anchorProgramIDs Anchor_Program_Holder
-
-	err = json.Unmarshal(bytes, &anchorProgramIDs)
-	if err != nil {
-		return nil, errors.New("Corrupt Anchor_Program_Holder record")
-	}
-
-	anchorProgramIDs.ANCHOR_PROGRAMs = append(anchorProgramIDs.ANCHOR_PROGRAMs, anchorprogramID)
-
-	bytes, err = json.Marshal(anchorProgramIDs)
-	if err != nil {
-		fmt.Print("Error creating Anchor_Program_Holder record")
-	}
-
-	err = stub.PutState("anchorProgramIDs", bytes)
-	if err != nil {
-		return nil, errors.New("Unable to put the state")
-	}
-
-	//	myLogger.Debugf("New owner of [%s] is [% x]", v, v.Owner)
-
-	return nil, nil
-
-}
-
-//=================================================================================================================================
-//	 Create Invoice - Creates the initial JSON for the invoice and then saves it to the ledger.
-//=================================================================================================================================
-func (t *AssetManagementChaincode) update_vendor_create_invoice(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, id string) ([]byte, error) {
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		v.Owner == string(callerAccount) &&
-		caller_affiliation == ROLE_VENDOR &&
-		//v.POAcknowledged == true &&
-		v.Settled == false { // If the roles and users are ok
-
-		var item MyBoxItem
-
-		//	id := randStr(9, "alphanum")
-		item.POID = v.AnchorProgramID
-		item.MOID = id
-		item.AnchorName = v.AnchorName
-		item.AnchorAccountNo = v.AnchorAccountNo
-		item.AnchorPOAmount = v.AnchorPOAmount
-		item.AnchorIFSCCode = v.AnchorIFSCCode
-		item.AnchorInterest = v.AnchorInterest
-		item.Vendorfname = v.VendorFName
-		item.Vendorbank = v.Vendorbank
-		item.Vendorifsccode = v.Vendorifsccode
-		item.AnchorPoID = v.AnchorPoID
-
-		item.MOOwner = string(callerAccount)
-		item.InvoiceRaisedBy = string(callerAccount)
-
-		/*matched, err := regexp.Match("^[A-z][A-z][0-9]{7}", []byte(id)) // matched = true if the poID passed fits format of two letters followed by seven digits
-		if err != nil {
-			fmt.Printf("CREATE_INVOICE: Invalid InvoiceID: %s", err)
-			return nil, errors.New("Invalid InvoiceID")
-		}*/
-
-		if item.MOID == "" {
-			fmt.Printf("CREATE_INVOICE: Invalid InvoiceID provided")
-			return nil, errors.New("Invalid moID provided")
-		}
-
-		record, err := stub.GetState(item.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
-		if record != nil {
-			return nil, errors.New("Invoice already exists")
-		}
-
-		if caller_affiliation != ROLE_VENDOR { // Only the vendor can create a new Invoice template
-			return nil, errors.New("Permission Denied")
-		}
-
-		_, err = t.save_invoice(stub, item)
-		if err != nil {
-			fmt.Printf("CREATE_INVOICE: Error saving changes: %s", err)
-			return nil, errors.New("Error saving changes")
-		}
-
-		bytes, err := stub.GetState("invoiceIDs")
-		if err != nil {
-			return nil, errors.New("Unable to get invoiceIDs")
-		}
-
-		var invoiceIDs Invoice_Holder
-
-		err = json.Unmarshal(bytes, &invoiceIDs)
-		if err != nil {
-			return nil, errors.New("Corrupt Invoice_Holder record")
-		}
-
-		invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, id)
-
-		bytes, err = json.Marshal(invoiceIDs)
-		if err != nil {
-			fmt.Print("Error creating Invoice_Holder record")
-		}
-
-		err = stub.PutState("invoiceIDs", bytes)
-		if err != nil {
-			return nil, errors.New("Unable to put the state")
-		}
-
-		v.Items = append(v.Items, (item))
-
-	} else { // Otherwise if there is an error
-
-		fmt.Printf("CREATE_INVOICE: Permission Denied")
-		return nil, errors.New("Permission Denied")
-
-	}
-	_, err := t.save_changes(stub, v)
-	if err != nil {
-		fmt.Printf("CREATE_INVOICE: Error saving changes: %s", err)
-		return nil, errors.New("Error saving changes to Anchor Program")
-	}
-
-	return nil, nil
-
-}
-
-//=================================================================================================================================
-//	 Transfer Functions
-//=================================================================================================================================
-//	 admin_to_anchor
-//=================================================================================================================================
-func (t *AssetManagementChaincode) admin_to_anchor(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
-	if v.VendorFName == "UNDEFINED" ||
-		v.VendorLName == "UNDEFINED" ||
-		v.Vendorphone == "UNDEFINED" ||
-		v.Vendoraddress == "UNDEFINED" ||
-		v.Vendoremail == "UNDEFINED" ||
-		v.Vendorlimit == 0 ||
-		v.AnchorName == "UNDEFINED" ||
-		v.AnchorID == "UNDEFINED" ||
-		v.AnchorAccountNo == "UNDEFINED" ||
-		v.AnchorIFSCCode == "UNDEFINED" ||
-		v.AnchorLimit == 0 ||
-		v.AnchorExpiryDate == "UNDEFINED" ||
-		v.AnchorInterest == "UNDEFINED" ||
-		v.AnchorGarceInterest == "UNDEFINED" ||
-		v.AnchorGarceInterestperiod == "UNDEFINED" ||
-		v.AnchorPenalInterest == "UNDEFINED" ||
-		v.AnchorLiquidation == "UNDEFINED" { //If any part of the order is undefined it has not been fully manufacturered so cannot be sent
-
-		fmt.Printf("ADMIN_TO_ANCHOR: AnchorProgram not fully defined")
-
-		return nil, errors.New("AnchorProgram not fully defined")
-	}
-
-	// Verify the identity of the caller
-	// Only the owner can transfer one of his assets
-
-	prvOwner := []byte(v.Owner)
-
-	// Verify ownership
-
-	if bytes.Compare(prvOwner, callerAccount) != 0 {
-		return nil, fmt.Errorf("Failed verifying caller ownership.")
-	}
-
-	if v.Status == STATE_TEMPLATE &&
-		caller_affiliation == ROLE_ADMIN &&
-		recipient_affiliation == ROLE_ANCHOR &&
-		v.Settled == false { // If the roles and users are ok
-
-		v.Owner = receiverAccount          // then make the owner the new owner
-		v.Status = STATE_PROGRAM_INITIATED // and mark it in the state of creating purchase order
-		v.POraisedBy = receiverAccount
-
-	} else { // Otherwise if there is an error
-
-		fmt.Printf("ADMIN_TO_ANCHOR: Permission Denied")
-		return nil, errors.New("Permission Denied")
-
-	}
-
-	_, err := t.save_changes(stub, v) // Write new state
-
-	if err != nil {
-		fmt.Printf("ADMIN_TO_ANCHOR: Error saving changes: %s", err)
-		return nil, errors.New("Error saving changes")
-	}
-
-	return nil, nil // We are Done
-
-}
-
-//=================================================================================================================================
-//	 anchor_to_admin_rev
-//=================================================================================================================================
-func (t *AssetManagementChaincode) anchor_to_admin_rev(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string, new_value string) ([]byte, error) {
-
-	// Verify the identity of the caller
-	// Only the owner can transfer one of his assets
-
-	prvOwner := []byte(v.Owner)
-
-	// Verify ownership
-
-	if bytes.Compare(prvOwner, callerAccount) != 0 {
-		return nil, fmt.Errorf("Failed verifying caller ownership.")
-	}
-
-	var pobox AnchorProgram
-	pobox = v
-
-	if v.Status == STATE_PROGRAM_INITIATED &&
-		caller_affiliation == ROLE_ANCHOR &&
-		recipient_affiliation == ROLE_ADMIN &&
-		v.Settled == false { // If the roles and users are ok
-
-		pobox.Owner = receiverAccount // then make the owner the new owner
-		pobox.Status = STATE_TEMPLATE // and mark it in the state of creating purchase order
-		pobox.AnchorProgramID = v.AnchorProgramID + "-R1"
-		pobox.AnchorPOAmount = 0 // Update to the new value
-		pobox.AnchorPoImage = "UNDEFINED"
-		pobox.AnchorPoID = "UNDEFINED"
-		pobox.POParent = v.AnchorProgramID
-		pobox.PORemarks = new_value
-		v.PoForks = append(v.PoForks, pobox.AnchorProgramID)
-
-		record, _ := stub.GetState(pobox.AnchorProgramID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
-		if record != nil {
-			return nil, errors.New("AnchorProgramID already exists")
-		}
-
-	} else { // Otherwise if there is an error
-
-		fmt.Printf("ANCHOR_TO_ADMIN_REV: Permission Denied")
-		return nil, errors.New("Permission Denied")
-
-	}
-
-	_, err := t.save_changes(stub, pobox) // Write new state
-
-	if err != nil {
-		fmt.Printf("ANCHOR_TO_ADMIN_REV: Error saving changes: %s", err)
-		return nil, errors.New("Error saving changes")
-	}
-
-	_, errs := t.save_changes(stub, v) // Write new state
-
-	if errs != nil {
-		fmt.Printf("ANCHOR_TO_ADMIN_REV: Error saving changes: %s", errs)
-		return nil, errors.New("Error saving changes")
-	}
-
-	bytes, _ := stub.GetState("anchorProgramIDs")
-
-	var anchorProgramIDs Anchor_Program_Holder
-
-	err = json.Unmarshal(bytes, &anchorProgramIDs)
-	if err != nil {
-		return nil, errors.New("Corrupt Anchor_Program_Holder record")
-	}
-
-	anchorProgramIDs.ANCHOR_PROGRAMs = append(anchorProgramIDs.ANCHOR_PROGRAMs, pobox.AnchorProgramID)
-
-	bytes, err = json.Marshal(anchorProgramIDs)
-	if err != nil {
-		fmt.Print("Error creating Anchor_Program_Holder record")
-	}
-
-	err = stub.PutState("anchorProgramIDs", bytes)
-	if err != nil {
-		return nil, errors.New("Unable to put the state")
-	}
-
-	return nil, nil // We are Done
-
-}
-
-//=================================================================================================================================
-//	 anchor_to_vendor
-//=================================================================================================================================
-func (t *AssetManagementChaincode) anchor_to_vendor(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
-
-	if v.AnchorPOAmount == 0 ||
-		v.AnchorPoImage == "UNDEFINED" ||
-		v.AnchorPoID == "UNDEFINED" ||
-		v.VendorID == "UNDEFINED" ||
-		v.VendorFName == "UNDEFINED" ||
-		v.VendorLName == "UNDEFINED" ||
-		v.Vendorphone == "UNDEFINED" ||
-		v.Vendoraddress == "UNDEFINED" ||
-		v.Vendoremail == "UNDEFINED" ||
-		v.Vendorlimit == 0 { //If any part of the order is undefined it has not been fully manufacturered so cannot be sent
-
-		fmt.Printf("ANCHOR_TO_VENDOR: AnchorProgram not fully defined")
-		return nil, errors.New("AnchorProgram not fully defined")
-	}
-
-	if v.Status == STATE_PROGRAM_INITIATED &&
-		v.Owner == string(callerAccount) &&
-		caller_affiliation == ROLE_ANCHOR &&
-		recipient_affiliation == ROLE_VENDOR &&
-		v.Settled == false { // If the roles and users are ok
-
-		v.Owner = receiverAccount              // then make the owner the new owner
-		v.Status = STATE_PURCHASE_ORDER_PLACED // and mark it in the state of creating purchase order
-		v.PORaisedAgainst = receiverAccount
-		//v.POTimestamp = time.Now()
-	} else { // Otherwise if there is an error
-
-		fmt.Printf("ANCHOR_TO_VENDOR: Permission Denied")
-		return nil, errors.New("Permission Denied")
-
-	}
-
-	_, err := t.save_changes(stub, v) // Write new state
-
-	if err != nil {
-		fmt.Printf("ANCHOR_TO_VENDOR: Error saving changes: %s", err)
-		return nil, errors.New("Error saving changes")
-	}
-
-	return nil, nil // We are Done
-
-}
-
-//=================================================================================================================================
-//	 vendor_to_anchor_rev
-//=================================================================================================================================
-func (t *AssetManagementChaincode) vendor_to_anchor_rev(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string, new_value string) ([]byte, error) {
-
-	var pobox AnchorProgram
-	pobox = v
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		v.Owner == string(callerAccount) &&
-		caller_affiliation == ROLE_VENDOR &&
-		recipient_affiliation == ROLE_ANCHOR &&
-		v.Settled == false { // If the roles and users are ok
-
-		pobox.Owner = receiverAccount          // then make the owner the new owner
-		pobox.Status = STATE_PROGRAM_INITIATED // and mark it in the state of creating purchase order
-		pobox.AnchorProgramID = v.AnchorProgramID + "-R2"
-		pobox.AnchorPOAmount = 0 // Update to the new value
-		pobox.AnchorPoImage = "UNDEFINED"
-		pobox.AnchorPoID = "UNDEFINED"
-		pobox.POParent = v.AnchorProgramID
-		pobox.PORemarks = new_value
-		v.PoForks = append(v.PoForks, pobox.AnchorProgramID)
-
-		record, _ := stub.GetState(pobox.AnchorProgramID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
-		if record != nil {
-			return nil, errors.New("AnchorProgramID already exists")
-		}
-
-	} else { // Otherwise if there is an error
-
-		fmt.Printf("VENDOR_TO_ANCHOR_REV: Permission Denied")
-		return nil, errors.New("Permission Denied")
-
-	}
-
-	_, err := t.save_changes(stub, pobox) // Write new state
-
-	if err != nil {
-		fmt.Printf("VENDOR_TO_ANCHOR_REV: Error saving changes: %s", err)
-		return nil, errors.New("Error saving changes")
-	}
-
-	_, errs := t.save_changes(stub, v) // Write new state
-
-	if errs != nil {
-		fmt.Printf("VENDOR_TO_ANCHOR_REV: Error saving changes: %s", errs)
-		return nil, errors.New("Error saving changes")
-	}
-
-	bytes, _ := stub.GetState("anchorProgramIDs")
-
-	var anchorProgramIDs Anchor_Program_Holder
-
-	err = json.Unmarshal(bytes, &anchorProgramIDs)
-	if err != nil {
-		return nil, errors.New("Corrupt Anchor_Program_Holder record")
-	}
-
-	anchorProgramIDs.ANCHOR_PROGRAMs = append(anchorProgramIDs.ANCHOR_PROGRAMs, pobox.AnchorProgramID)
-
-	bytes, err = json.Marshal(anchorProgramIDs)
-	if err != nil {
-		fmt.Print("Error creating Anchor_Program_Holder record")
-	}
-
-	err = stub.PutState("anchorProgramIDs", bytes)
-	if err != nil {
-		return nil, errors.New("Unable to put the state")
-	}
-
-	return nil, nil // We are Done
-
-}
-
-//==========================================================================================================
-//transfer_vendor_to_anchor_invoice
-//==========================================================================================================
-func (t *AssetManagementChaincode) transfer_vendor_to_anchor_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
-
-	if x.InvoiceID == "UNDEFINED" ||
-		x.InvoiceImage == "UNDEFINED" ||
-		x.MOAmount == 0 { //If any part of the order is undefined it has not been fully manufacturered so cannot be sent
-
-		fmt.Printf("VENDOR_TO_ANCHOR_INVOICE: Invoice not fully defined")
-		return nil, errors.New("Invoice not fully defined")
-	}
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		v.Owner == string(callerAccount) &&
-		//v.Settled == false &&
-		x.MOStatus == STATE_TEMPLATE &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_VENDOR &&
-		x.MOPaid == false &&
-		x.MOSettled == false &&
-		recipient_affiliation == ROLE_ANCHOR &&
-		v.Settled == false {
-
-		for i := range v.Items {
-			if x.MOID == v.Items[i].MOID {
-
-				x.MOOwner = receiverAccount // then make the owner the new owner
-				v.Items[i].MOOwner = receiverAccount
-
-				x.MOStatus = STATE_INVOICE_RAISED // and mark it in the state of creating purchase order
-				v.Items[i].MOStatus = STATE_INVOICE_RAISED
-
-				x.InvoiceRaisedAgainst = receiverAccount
-				v.Items[i].InvoiceRaisedAgainst = receiverAccount
-
-				//x.MOTimestamp = time.Now()
-				//v.Items[i].MOTimestamp = time.Now()
-
-				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
-					fmt.Println("QUERY: Error retrieving Parent")
-				} else {
-					f, err := t.retrieve_invoice(stub, x.MOParent)
-					if err != nil {
-						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
-						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
-					}
-
-					for j := range v.Items {
-						if f.MOID == v.Items[j].MOID {
-
-							f.MoOriginal = f.MOAmount
-							v.Items[j].MoOriginal = f.MOAmount
-
-							f.MOAmount = 0
-							v.Items[j].MOAmount = 0
-
-							f.MOStatus = STATE_INVOICE_RETIRED
-							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
-
-							break
-						}
-
-					}
-
-					_, errs := t.save_invoice(stub, f)
-
-					if errs != nil {
-						fmt.Printf("VENDOR_TO_ANCHOR_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
-						return nil, errors.New(" %@((@& Error saving changes to invoice")
-					}
-
-				}
-
-				break
-			}
-		}
-	}
-
-	_, err := t.save_invoice(stub, x)
-
-	if err != nil {
-		fmt.Printf("VENDOR_TO_ANCHOR_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("VENDOR_TO_ANCHOR_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//==========================================================================================================
-//transfer_rev_anchor_to_vendor_invoice
-//==========================================================================================================
-func (t *AssetManagementChaincode) transfer_rev_anchor_to_vendor_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string, new_value string) ([]byte, error) {
-
-	var mobox MyBoxItem
-	mobox = x
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		//v.Owner == string(callerAccount) &&
-		//v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_RAISED &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_ANCHOR &&
-		x.MOPaid == false &&
-		x.MOSettled == false &&
-		recipient_affiliation == ROLE_VENDOR &&
-		v.Settled == false {
-
-		mobox.MOID = x.MOID + "-RIN1"
-		mobox.MOOwner = receiverAccount // then make the owner the new owner
-		mobox.MOStatus = STATE_TEMPLATE // and mark it in the state of creating purchase order
-		mobox.InvoiceID = "UNDEFINED"
-		mobox.InvoiceImage = "UNDEFINED"
-		mobox.MOAmount = 0
-		mobox.MOParent = x.MOID
-		mobox.MORemarks = new_value
-		x.MOForks = append(x.MOForks, mobox.MOID)
-
-		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
-		if record != nil {
-			return nil, errors.New("Invoice already exists")
-		}
-
-	}
-
-	_, err := t.save_invoice(stub, mobox)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_REV_ANCHOR_TO_VENDOR_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	bytes, err := stub.GetState("invoiceIDs")
-	if err != nil {
-		return nil, errors.New("Unable to get invoiceIDs")
-	}
-
-	var invoiceIDs Invoice_Holder
-
-	err = json.Unmarshal(bytes, &invoiceIDs)
-	if err != nil {
-		return nil, errors.New("Corrupt Invoice_Holder record")
-	}
-
-	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
-
-	bytes, err = json.Marshal(invoiceIDs)
-	if err != nil {
-		fmt.Print("Error creating Invoice_Holder record")
-	}
-
-	err = stub.PutState("invoiceIDs", bytes)
-	if err != nil {
-		return nil, errors.New("Unable to put the state")
-	}
-
-	v.Items = append(v.Items, (mobox))
-
-	_, errs := t.save_invoice(stub, x)
-
-	if errs != nil {
-		fmt.Printf("TRANSFER_REV_ANCHOR_TO_VENDOR_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_REV_ANCHOR_TO_VENDOR_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//==========================================================================================================
-//transfer_anchor_to_vendor_invoice
-//==========================================================================================================
-func (t *AssetManagementChaincode) transfer_anchor_to_vendor_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
-
-	if x.InvoiceID == "UNDEFINED" ||
-		x.InvoiceImage == "UNDEFINED" { //If any part of the order is undefined it has not been fully manufacturered so cannot be sent
-
-		fmt.Printf("ANCHOR_TO_VENDOR_INVOICE: Invoice not fully defined")
-		return nil, errors.New("Invoice not fully defined")
-	}
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		//v.Owner 							 == caller &&
-		//v.Settled == false &&
-		x.MOStatus == STATE_VENDOR_INVOICE_APPROVED &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_ANCHOR &&
-		x.MOPaid == false &&
-		x.MOSettled == false &&
-		recipient_affiliation == ROLE_VENDOR &&
-		v.Settled == false {
-
-		for i := range v.Items {
-			if x.MOID == v.Items[i].MOID {
-
-				x.MOOwner = receiverAccount // then make the owner the new owner
-				v.Items[i].MOOwner = receiverAccount
-
-				x.MOStatus = STATE_ANCHOR_AUTHORISED_INVOICE_PAYMENT // and mark it in the state of requesting invoice payment
-				v.Items[i].MOStatus = STATE_ANCHOR_AUTHORISED_INVOICE_PAYMENT
-
-				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
-					fmt.Println("QUERY: Error retrieving Parent")
-				} else {
-					f, err := t.retrieve_invoice(stub, x.MOParent)
-					if err != nil {
-						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
-						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
-					}
-
-					for j := range v.Items {
-						if f.MOID == v.Items[j].MOID {
-
-							f.MOStatus = STATE_INVOICE_RETIRED
-							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
-
-							break
-						}
-
-					}
-
-					_, errs := t.save_invoice(stub, f)
-
-					if errs != nil {
-						fmt.Printf("ANCHOR_TO_VENDOR_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
-						return nil, errors.New(" %@((@& Error saving changes to invoice")
-					}
-
-				}
-
-				break
-			}
-
-		}
-	}
-
-	_, err := t.save_invoice(stub, x)
-
-	if err != nil {
-		fmt.Printf("ANCHOR_TO_VENDOR_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("ANCHOR_TO_VENDOR_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//==========================================================================================================
-//transfer_rev_vendor_to_anchor_invoice
-//==========================================================================================================
-func (t *AssetManagementChaincode) transfer_rev_vendor_to_anchor_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string, new_value string) ([]byte, error) {
-
-	var mobox MyBoxItem
-	mobox = x
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		//v.Owner 							 == caller &&
-		//v.Settled == false &&
-		x.MOStatus == STATE_ANCHOR_AUTHORISED_INVOICE_PAYMENT &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_VENDOR &&
-		x.MOPaid == false &&
-		x.MOSettled == false &&
-		recipient_affiliation == ROLE_ANCHOR &&
-		v.Settled == false {
-
-		mobox.MOID = x.MOID + "-RIN2"
-		mobox.MOOwner = receiverAccount                // then make the owner the new owner
-		mobox.MOStatus = STATE_VENDOR_INVOICE_APPROVED // and mark it in the state of creating purchase order
-
-		mobox.ApprovedInvoiceAmount = 0
-		mobox.MOParent = x.MOID
-		mobox.MORemarks = new_value
-		x.MOForks = append(x.MOForks, mobox.MOID)
-
-		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
-		if record != nil {
-			return nil, errors.New("Invoice already exists")
-		}
-
-	}
-
-	_, err := t.save_invoice(stub, mobox)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_REV_VENDOR_TO_ANCHOR_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	bytes, err := stub.GetState("invoiceIDs")
-	if err != nil {
-		return nil, errors.New("Unable to get invoiceIDs")
-	}
-
-	var invoiceIDs Invoice_Holder
-
-	err = json.Unmarshal(bytes, &invoiceIDs)
-	if err != nil {
-		return nil, errors.New("Corrupt Invoice_Holder record")
-	}
-
-	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
-
-	bytes, err = json.Marshal(invoiceIDs)
-	if err != nil {
-		fmt.Print("Error creating Invoice_Holder record")
-	}
-
-	err = stub.PutState("invoiceIDs", bytes)
-	if err != nil {
-		return nil, errors.New("Unable to put the state")
-	}
-
-	v.Items = append(v.Items, (mobox))
-
-	_, errs := t.save_invoice(stub, x)
-
-	if errs != nil {
-		fmt.Printf("TRANSFER_REV_VENDOR_TO_ANCHOR_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_REV_VENDOR_TO_ANCHOR_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//==========================================================================================================
-//transfer_vendor_to_admin_invoice
-//==========================================================================================================
-func (t *AssetManagementChaincode) transfer_vendor_to_admin_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
-
-	if x.InvoiceID == "UNDEFINED" ||
-		x.InvoiceImage == "UNDEFINED" { //If any part of the order is undefined it has not been fully manufacturered so cannot be sent
-
-		fmt.Printf("VENDOR_TO_ADMIN_INVOICE: Invoice not fully defined")
-		return nil, errors.New("Invoice not fully defined")
-	}
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		//v.Owner 							 == caller &&
-		//v.Settled == false &&
-		x.MOStatus == STATE_ANCHOR_AUTHORISED_INVOICE_PAYMENT &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_VENDOR &&
-		x.MOPaid == false &&
-		x.MOSettled == false &&
-		recipient_affiliation == ROLE_ADMIN &&
-		v.Settled == false {
-
-		for i := range v.Items {
-			if x.MOID == v.Items[i].MOID {
-
-				x.MOOwner = receiverAccount // then make the owner the new owner
-				v.Items[i].MOOwner = receiverAccount
-
-				x.MOStatus = STATE_INVOICE_PAYMENT_REQUESTED // and mark it in the state of requesting invoice payment
-				v.Items[i].MOStatus = STATE_INVOICE_PAYMENT_REQUESTED
-
-				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
-					fmt.Println("QUERY: Error retrieving Parent")
-				} else {
-					f, err := t.retrieve_invoice(stub, x.MOParent)
-					if err != nil {
-						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
-						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
-					}
-
-					for j := range v.Items {
-						if f.MOID == v.Items[j].MOID {
-
-							f.MOStatus = STATE_INVOICE_RETIRED
-							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
-
-							break
-						}
-
-					}
-
-					_, errs := t.save_invoice(stub, f)
-
-					if errs != nil {
-						fmt.Printf("VENDOR_TO_ADMIN_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
-						return nil, errors.New(" %@((@& Error saving changes to invoice")
-					}
-
-				}
-
-				break
-			}
-
-		}
-	}
-
-	_, err := t.save_invoice(stub, x)
-
-	if err != nil {
-		fmt.Printf("VENDOR_TO_ADMIN_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("VENDOR_TO_ADMIN_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//==========================================================================================================
-//transfer_rev_admin_to_vendor_invoice
-//==========================================================================================================
-func (t *AssetManagementChaincode) transfer_rev_admin_to_vendor_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string, new_value string) ([]byte, error) {
-
-	var mobox MyBoxItem
-	mobox = x
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		//v.Owner 							 == caller &&
-		//v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_PAYMENT_REQUESTED &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_ADMIN &&
-		x.MOPaid == false &&
-		x.MOSettled == false &&
-		recipient_affiliation == ROLE_VENDOR &&
-		v.Settled == false {
-
-		mobox.MOID = x.MOID + "-RIN3"
-		mobox.MOOwner = receiverAccount                          // then make the owner the new owner
-		mobox.MOStatus = STATE_ANCHOR_AUTHORISED_INVOICE_PAYMENT // and mark it in the state of creating purchase order
-		mobox.MOParent = x.MOID
-		mobox.MORemarks = new_value
-		x.MOForks = append(x.MOForks, mobox.MOID)
-
-		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
-		if record != nil {
-			return nil, errors.New("Invoice already exists")
-		}
-
-	}
-
-	_, err := t.save_invoice(stub, mobox)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_REV_ADMIN_TO_VENDOR_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	bytes, err := stub.GetState("invoiceIDs")
-	if err != nil {
-		return nil, errors.New("Unable to get invoiceIDs")
-	}
-
-	var invoiceIDs Invoice_Holder
-
-	err = json.Unmarshal(bytes, &invoiceIDs)
-	if err != nil {
-		return nil, errors.New("Corrupt Invoice_Holder record")
-	}
-
-	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
-
-	bytes, err = json.Marshal(invoiceIDs)
-	if err != nil {
-		fmt.Print("Error creating Invoice_Holder record")
-	}
-
-	err = stub.PutState("invoiceIDs", bytes)
-	if err != nil {
-		return nil, errors.New("Unable to put the state")
-	}
-
-	v.Items = append(v.Items, (mobox))
-
-	_, errs := t.save_invoice(stub, x)
-
-	if errs != nil {
-		fmt.Printf("TRANSFER_REV_ADMIN_TO_VENDOR_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_REV_ADMIN_TO_VENDOR_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//==========================================================================================================
-//transfer_admin_to_payment_invoice
-//==========================================================================================================
-func (t *AssetManagementChaincode) transfer_admin_to_payment_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
-
-	if x.InvoiceID == "UNDEFINED" ||
-		x.InvoiceImage == "UNDEFINED" { //If any part of the order is undefined it has not been fully manufacturered so cannot be sent
-
-		fmt.Printf("ANCHOR_TO_ADMIN_INVOICE: Invoice not fully defined")
-		return nil, errors.New("Invoice not fully defined")
-	}
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		//v.Owner 							 == caller &&
-		//v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_PAYMENT_REQUESTED &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_ADMIN &&
-		x.MOPaid == false &&
-		x.MOSettled == false &&
-		recipient_affiliation == ROLE_PAYMENT_MAKER &&
-		v.Settled == false {
-
-		for i := range v.Items {
-			if x.MOID == v.Items[i].MOID {
-
-				x.MOOwner = receiverAccount // then make the owner the new owner
-				v.Items[i].MOOwner = receiverAccount
-
-				x.MOStatus = STATE_INVOICE_PAYMENT_INITIATED // and mark it in the state of requesting invoice payment
-				v.Items[i].MOStatus = STATE_INVOICE_PAYMENT_INITIATED
-
-				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
-					fmt.Println("QUERY: Error retrieving Parent")
-				} else {
-					f, err := t.retrieve_invoice(stub, x.MOParent)
-					if err != nil {
-						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
-						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
-					}
-
-					for j := range v.Items {
-						if f.MOID == v.Items[j].MOID {
-
-							f.MOStatus = STATE_INVOICE_RETIRED
-							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
-
-							break
-						}
-
-					}
-
-					_, errs := t.save_invoice(stub, f)
-
-					if errs != nil {
-						fmt.Printf("TRANSFER_ADMIN_TO_PAYMENT_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
-						return nil, errors.New(" %@((@& Error saving changes to invoice")
-					}
-
-				}
-
-				break
-
-			}
-
-		}
-	}
-
-	_, err := t.save_invoice(stub, x)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_ADMIN_TO_PAYMENT_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_ADMIN_TO_PAYMENT_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//==========================================================================================================
-//transfer_rev_payment_to_admin_invoice
-//==========================================================================================================
-func (t *AssetManagementChaincode) transfer_rev_payment_to_admin_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string, new_value string) ([]byte, error) {
-
-	var mobox MyBoxItem
-	mobox = x
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		//v.Owner 							 == caller &&
-		//v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_PAYMENT_INITIATED &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_PAYMENT_MAKER &&
-		x.MOPaid == false &&
-		x.MOSettled == false &&
-		recipient_affiliation == ROLE_ADMIN &&
-		v.Settled == false {
-
-		mobox.MOID = x.MOID + "-RIN4"
-		mobox.MOOwner = receiverAccount                  // then make the owner the new owner
-		mobox.MOStatus = STATE_INVOICE_PAYMENT_REQUESTED // and mark it in the state of creating purchase order
-		mobox.MOParent = x.MOID
-		mobox.MORemarks = new_value
-		x.MOForks = append(x.MOForks, mobox.MOID)
-
-		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
-		if record != nil {
-			return nil, errors.New("Invoice already exists")
-		}
-
-	}
-
-	_, err := t.save_invoice(stub, mobox)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_REV_PAYMENT_TO_ADMIN_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	bytes, err := stub.GetState("invoiceIDs")
-	if err != nil {
-		return nil, errors.New("Unable to get invoiceIDs")
-	}
-
-	var invoiceIDs Invoice_Holder
-
-	err = json.Unmarshal(bytes, &invoiceIDs)
-	if err != nil {
-		return nil, errors.New("Corrupt Invoice_Holder record")
-	}
-
-	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
-
-	bytes, err = json.Marshal(invoiceIDs)
-	if err != nil {
-		fmt.Print("Error creating Invoice_Holder record")
-	}
-
-	err = stub.PutState("invoiceIDs", bytes)
-	if err != nil {
-		return nil, errors.New("Unable to put the state")
-	}
-
-	v.Items = append(v.Items, (mobox))
-
-	_, errs := t.save_invoice(stub, x)
-
-	if errs != nil {
-		fmt.Printf("TRANSFER_REV_PAYMENT_TO_ADMIN_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_REV_PAYMENT_TO_ADMIN_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//==========================================================================================================
-//transfer_payment_maker_to_payment_checker_invoice
-//==========================================================================================================
-func (t *AssetManagementChaincode) transfer_payment_maker_to_payment_checker_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
-
-	if x.InvoiceID == "UNDEFINED" ||
-		x.InvoiceImage == "UNDEFINED" { //If any part of the order is undefined it has not been fully manufacturered so cannot be sent
-
-		fmt.Printf("TRANSFER PAYMENT MAKER TO PAYMENT CHECKER_INVOICE: Invoice not fully defined")
-		return nil, errors.New("Invoice not fully defined")
-	}
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		//v.Owner 							 == caller &&
-		//v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_PAYMENT_INITIATED &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_PAYMENT_MAKER &&
-		x.MOPaid == false &&
-		x.MOSettled == false &&
-		recipient_affiliation == ROLE_PAYMENT_CHECKER &&
-		v.Settled == false {
-
-		for i := range v.Items {
-			if x.MOID == v.Items[i].MOID {
-
-				x.MOOwner = receiverAccount // then make the owner the new owner
-				v.Items[i].MOOwner = receiverAccount
-
-				x.MOStatus = STATE_INVOICE_PAYMENT_PENDING_APPROVAL // and mark it in the state of requesting invoice payment
-				v.Items[i].MOStatus = STATE_INVOICE_PAYMENT_PENDING_APPROVAL
-
-				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
-					fmt.Println("QUERY: Error retrieving Parent")
-				} else {
-					f, err := t.retrieve_invoice(stub, x.MOParent)
-					if err != nil {
-						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
-						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
-					}
-
-					for j := range v.Items {
-						if f.MOID == v.Items[j].MOID {
-
-							f.MOStatus = STATE_INVOICE_RETIRED
-							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
-
-							break
-						}
-
-					}
-
-					_, errs := t.save_invoice(stub, f)
-
-					if errs != nil {
-						fmt.Printf("TRANSFER_PAYMENT_MAKER_TO_PAYMENT_CHECKER_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
-						return nil, errors.New(" %@((@& Error saving changes to invoice")
-					}
-
-				}
-
-				break
-
-			}
-
-		}
-	}
-
-	_, err := t.save_invoice(stub, x)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_PAYMENT_MAKER_TO_PAYMENT_CHECKER_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_PAYMENT_MAKER_TO_PAYMENT_CHECKER_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//==========================================================================================================
-//transfer_rev_payment_checker_to_payment_maker_invoice
-//==========================================================================================================
-func (t *AssetManagementChaincode) transfer_rev_payment_checker_to_payment_maker_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string, new_value string) ([]byte, error) {
-
-	var mobox MyBoxItem
-	mobox = x
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		//v.Owner 							 == caller &&
-		//v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_PAYMENT_PENDING_APPROVAL &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_PAYMENT_CHECKER &&
-		x.MOPaid == false &&
-		x.MOSettled == false &&
-		recipient_affiliation == ROLE_PAYMENT_MAKER &&
-		v.Settled == false {
-
-		mobox.MOID = x.MOID + "-RIN5"
-		mobox.MOOwner = receiverAccount                  // then make the owner the new owner
-		mobox.MOStatus = STATE_INVOICE_PAYMENT_INITIATED // and mark it in the state of creating purchase order
-		mobox.MOParent = x.MOID
-		x.MOForks = append(x.MOForks, mobox.MOID)
-		mobox.MORemarks = new_value
-		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
-		if record != nil {
-			return nil, errors.New("Invoice already exists")
-		}
-
-	}
-
-	_, err := t.save_invoice(stub, mobox)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_REV_PAYMENT_CHECKER_TO_PAYMENT_MAKER_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	bytes, err := stub.GetState("invoiceIDs")
-	if err != nil {
-		return nil, errors.New("Unable to get invoiceIDs")
-	}
-
-	var invoiceIDs Invoice_Holder
-
-	err = json.Unmarshal(bytes, &invoiceIDs)
-	if err != nil {
-		return nil, errors.New("Corrupt Invoice_Holder record")
-	}
-
-	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
-
-	bytes, err = json.Marshal(invoiceIDs)
-	if err != nil {
-		fmt.Print("Error creating Invoice_Holder record")
-	}
-
-	err = stub.PutState("invoiceIDs", bytes)
-	if err != nil {
-		return nil, errors.New("Unable to put the state")
-	}
-
-	v.Items = append(v.Items, (mobox))
-
-	_, errs := t.save_invoice(stub, x)
-
-	if errs != nil {
-		fmt.Printf("TRANSFER_REV_PAYMENT_CHECKER_TO_PAYMENT_MAKER_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_REV_PAYMENT_CHECKER_TO_PAYMENT_MAKER_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//==========================================================================================================
-//transfer_payment_checker_to_payment_maker_invoice
-//==========================================================================================================
-func (t *AssetManagementChaincode) transfer_payment_checker_to_payment_maker_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		//v.Owner 							 == caller &&
-		//v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_PAYMENT_PENDING_APPROVAL &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_PAYMENT_CHECKER &&
-		x.MOPaid == false &&
-		x.MOSettled == false &&
-		recipient_affiliation == ROLE_PAYMENT_MAKER &&
-		v.Settled == false {
-
-		for i := range v.Items {
-			if x.MOID == v.Items[i].MOID {
-
-				x.MOOwner = receiverAccount // then make the owner the new owner
-				v.Items[i].MOOwner = receiverAccount
-
-				x.MOStatus = STATE_INVOICE_PAYMENT_INITIATED // and mark it in the state of requesting invoice payment
-				v.Items[i].MOStatus = STATE_INVOICE_PAYMENT_INITIATED
-
-				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
-					fmt.Println("QUERY: Error retrieving Parent")
-				} else {
-					f, err := t.retrieve_invoice(stub, x.MOParent)
-					if err != nil {
-						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
-						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
-					}
-
-					for j := range v.Items {
-						if f.MOID == v.Items[j].MOID {
-
-							f.MOStatus = STATE_INVOICE_RETIRED
-							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
-
-							break
-						}
-
-					}
-
-					_, errs := t.save_invoice(stub, f)
-
-					if errs != nil {
-						fmt.Printf("TRANSFER_PAYMENT_CHECKER_TO_PAYMENT_MAKER_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
-						return nil, errors.New(" %@((@& Error saving changes to invoice")
-					}
-
-				}
-
-				break
-
-			}
-
-		}
-	}
-
-	_, err := t.save_invoice(stub, x)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_PAYMENT_CHECKER_TO_PAYMENT_MAKER_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("TRANSFER_PAYMENT_CHECKER_TO_PAYMENT_MAKER_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-/*//==========================================================================================================
-//transfer_payment_checker_to_anchor_invoice
-//==========================================================================================================
-func (t *AssetManagementChaincode) transfer_payment_checker_to_anchor_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		//v.Owner 							 == caller &&
-		//v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_PAID &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_PAYMENT_CHECKER &&
-		x.MOPaid == true &&
-		x.AnchorPaidInv == false &&
-		x.MOSettled == false &&
-		recipient_affiliation == ROLE_ANCHOR &&
-		v.Settled == false {
-
-		for i := range v.Items {
-			if x.MOID == v.Items[i].MOID {
-
-				x.MOOwner = receiverAccount // then make the owner the new owner
-				v.Items[i].MOOwner = receiverAccount
-
-				break
-
-			}
-
-		}
-	}
-
-	_, err := t.save_invoice(stub, x)
-
-	if err != nil {
-		fmt.Printf("transfer_payment_checker_to_anchor_invoice: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("transfer_payment_checker_to_anchor_invoice: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//==========================================================================================================
-//transfer_anchor_to_payment_checker_invoice
-//==========================================================================================================
-func (t *AssetManagementChaincode) transfer_anchor_to_payment_checker_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		//v.Owner 							 == caller &&
-		//v.Settled == false &&
-		x.MOStatus == STATE_ANCHOR_PAID_INVOICE &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_ANCHOR &&
-		x.MOPaid == true &&
-		x.AnchorPaidInv == true &&
-		x.MOSettled == false &&
-		recipient_affiliation == ROLE_PAYMENT_CHECKER &&
-		v.Settled == false {
-
-		for i := range v.Items {
-			if x.MOID == v.Items[i].MOID {
-
-				x.MOOwner = receiverAccount // then make the owner the new owner
-				v.Items[i].MOOwner = receiverAccount
-
-				break
-
-			}
-
-		}
-	}
-
-	_, err := t.save_invoice(stub, x)
-
-	if err != nil {
-		fmt.Printf("transfer_anchor_to_payment_checker_invoice: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("transfer_anchor_to_payment_checker_invoice: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-*/
-//=================================================================================================================================
-//	 Update Functions
-//---------------------------------------------------------------------------------------------------------------------------------
-//   ADMIN UPDATE ANCHOR FUNCTIONS
-//=================================================================================================================================
-//	 update_anchor_details
-//=================================================================================================================================
-func (t *AssetManagementChaincode) update_anchor_details(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation, name, id, ifsc, agreement, account, limit, expiry, interest, graceInterest, graceinterestPeriod, penalInterest, anchorLiquidation string) ([]byte, error) {
-	new_amount, _ := strconv.ParseFloat(string(limit), 64) // will return an error if the new purchase amount contains non numerical chars
-
-	if v.Status == STATE_TEMPLATE &&
-		v.Owner == string(callerAccount) &&
-		caller_affiliation == ROLE_ADMIN &&
-		v.Settled == false {
-
-		v.AnchorName = name
-		v.AnchorID = id
-		v.AnchorIFSCCode = ifsc
-		v.AnchorAgreement = agreement
-		v.AnchorAccountNo = account
-		v.AnchorLimit = new_amount
-		v.AnchorExpiryDate = expiry
-		v.AnchorInterest = interest
-		v.AnchorGarceInterest = graceInterest
-		v.AnchorGarceInterestperiod = graceinterestPeriod
-		v.AnchorPenalInterest = penalInterest
-		v.AnchorLiquidation = anchorLiquidation
-
-	} else {
-
-		return nil, errors.New("Permission denied")
-
-	}
-
-	_, err := t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("update_anchor_details: Error saving changes: %s", err)
-		return nil, errors.New("Error saving changes")
-	}
-
-	return nil, nil
-
-}
-
-//---------------------------------------------------------------------------------------------------------------------------------
-//   ADMIN UPDATE VENDOR FUNCTIONS
-//=================================================================================================================================
-//	 update_vendor_details
-//=================================================================================================================================
-func (t *AssetManagementChaincode) update_vendor_details(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation, id, limit, firstName, lastName, email, phone, address, pan, agreement, expiry, bank, bankAddress, account, ifsc string) ([]byte, error) {
-	new_amount, _ := strconv.ParseFloat(string(limit), 64) // will return an error if the new purchase amount contains non numerical chars
-
-	if v.Status == STATE_TEMPLATE &&
-		v.Owner == string(callerAccount) &&
-		caller_affiliation == ROLE_ADMIN &&
-		v.Settled == false {
-
-		v.VendorID = id
-		v.Vendorlimit = new_amount
-		v.VendorFName = firstName
-		v.VendorLName = lastName
-		v.Vendoremail = email
-		v.Vendorphone = phone
-		v.Vendoraddress = address
-		v.Vendorpanno = pan
-		v.VendorAgreement = agreement
-		v.VendorExpirydate = expiry
-		v.Vendorbank = bank
-		v.Vendorbaddress = bankAddress
-		v.Vendoraccountno = account
-		v.Vendorifsccode = ifsc
-
-	} else {
-
-		return nil, errors.New("Permission denied")
-
-	}
-
-	_, err := t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("update_vendor_details: Error saving changes: %s", err)
-		return nil, errors.New("Error saving changes")
-	}
-
-	return nil, nil
-
-}
-
-//---------------------------------------------------------------------------------------------------------------------------------
-//   ANCHOR UPDATE PO FUNCTIONS
-//=================================================================================================================================
-//	 update_anchor_purchase_order
-//=================================================================================================================================
-
-func (t *AssetManagementChaincode) update_anchor_purchase_order(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value, poImage, poID string) ([]byte, error) {
-
-	new_amount, _ := strconv.ParseFloat(string(new_value), 64) // will return an error if the new purchase amount contains non numerical chars
-
-	if new_amount > v.Vendorlimit {
-		fmt.Println("Amount exceeds authorized vendor limit")
-		return nil, nil
-	} else if v.Status == STATE_PROGRAM_INITIATED &&
-		v.Owner == string(callerAccount) &&
-		caller_affiliation == ROLE_ANCHOR &&
-		v.AnchorPOAmount == 0 && // Can't change the purchase amount after its initial assignment
-		v.Settled == false {
-
-		v.AnchorPOAmount = new_amount // Update to the new value
-		v.AnchorPoImage = poImage
-		v.AnchorPoID = poID
-
-	} else {
-
-		return nil, errors.New("Permission denied")
-
-	}
-
-	_, err := t.save_changes(stub, v) // Save the changes in the blockchain
-
-	if err != nil {
-		fmt.Printf("update_anchor_purchase_order: Error saving changes: %s", err)
-		return nil, errors.New("Error saving changes")
-	}
-
-	return nil, nil
-
-}
-
-//---------------------------------------------------------------------------------------------------------------------------------
-//   VENDOR UPDATE PO FUNCTIONS
-//=================================================================================================================================
-//	 update_vendor_po_acknowledgement
-//=================================================================================================================================
-func (t *AssetManagementChaincode) update_vendor_po_acknowledgement(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string) ([]byte, error) {
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		v.Owner == string(callerAccount) &&
-		caller_affiliation == ROLE_VENDOR &&
-		v.POAcknowledged == false &&
-		v.Settled == false {
-
-		v.POAcknowledged = true
-
-	} else {
-		return nil, errors.New("Permission denied")
-	}
-
-	_, err := t.save_changes(stub, v)
-	if err != nil {
-		fmt.Printf("UPDATE_VENDOR_PO_ACKNOWLEDGEMENT: Error saving changes: %s", err)
-		return nil, errors.New("UPDATE_VENDOR_PO_ACKNOWLEDGEMENT: Error saving changes")
-	}
-
-	return nil, nil
-
-}
-
-//---------------------------------------------------------------------------------------------------------------------------------
-//   VENDOR UPDATE INVOICE FUNCTIONS
-//=================================================================================================================================
-//	 update_vendor_invoice_details
-//=================================================================================================================================
-func (t *AssetManagementChaincode) update_vendor_invoice_details(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation, new_value, invID, image string) ([]byte, error) {
-	new_amount, _ := strconv.ParseFloat(string(new_value), 64) // will return an error if the new purchase amount contains non numerical chars
-
-	var inv float64
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		v.Owner == string(callerAccount) &&
-		v.Settled == false &&
-		x.MOStatus == STATE_TEMPLATE &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_VENDOR &&
-		new_amount <= v.AnchorPOAmount &&
-		x.MOPaid == false &&
-		x.MOSettled == false {
-
-		for i := range v.Items {
-			if v.Items[i].MOStatus == STATE_VENDOR_INVOICE_APPROVED {
-				inv += v.Items[i].ApprovedInvoiceAmount
-				continue
-			}
-		}
-
-		if inv+new_amount > v.AnchorPOAmount {
-			fmt.Println("Total invoice amount cannot exceed the Purchase Order")
-			return nil, nil
-		} else {
-
-			for i := range v.Items {
-				if x.MOID == v.Items[i].MOID {
-
-					x.MOAmount = new_amount
-					v.Items[i].MOAmount = new_amount
-
-					x.InvoiceID = invID
-					v.Items[i].InvoiceID = invID
-
-					x.InvoiceImage = image
-					v.Items[i].InvoiceImage = image
-
-					break
-
-				}
-
-			}
-		}
-	}
-
-	_, err := t.save_invoice(stub, x)
-
-	if err != nil {
-		fmt.Printf("UPDATE_VENDOR_INVOICE_DETAILS: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("UPDATE_VENDOR_INVOICE_DETAILS: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//---------------------------------------------------------------------------------------------------------------------------------
-//   ANCHOR UPDATE INVOICE FUNCTIONS
-//=================================================================================================================================
-//	 update_anchor_invoice_authorized_amount
-//=================================================================================================================================
-func (t *AssetManagementChaincode) update_anchor_invoice_authorized_amount(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value string) ([]byte, error) {
-	new_amount, _ := strconv.ParseFloat(string(new_value), 64) // will return an error if the new purchase amount contains non numerical chars
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		// v.Owner 						== caller &&
-		v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_RAISED ||
-		x.MOStatus == STATE_VENDOR_INVOICE_APPROVED &&
-			x.MOOwner == string(callerAccount) &&
-			caller_affiliation == ROLE_ANCHOR &&
-			x.MOPaid == false &&
-			x.MOSettled == false {
-
-		for i := range v.Items {
-			if x.MOID == v.Items[i].MOID {
-
-				x.ApprovedInvoiceAmount = new_amount
-				v.Items[i].ApprovedInvoiceAmount = new_amount
-
-				x.MOStatus = STATE_VENDOR_INVOICE_APPROVED
-				v.Items[i].MOStatus = STATE_VENDOR_INVOICE_APPROVED
-
-				break
-			}
-
-		}
-	}
-
-	_, err := t.save_invoice(stub, x)
-
-	if err != nil {
-		fmt.Printf("UPDATE_ANCHOR_AUTHORIZED_INVOICE_AMOUNT: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("UPDATE_ANCHOR_AUTHORIZED_INVOICE_AMOUNT: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-/*//=================================================================================================================================
-//	 update_rev_anchor_invoice_authorized_amount
-//=================================================================================================================================
-func (t *AssetManagementChaincode) update_rev_anchor_invoice_authorized_amount(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value string) ([]byte, error) {
-	new_amount, _ := strconv.ParseFloat(string(new_value), 64) // will return an error if the new purchase amount contains non numerical chars
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		// v.Owner 						== caller &&
-		v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_RAISED ||
-		x.MOStatus == STATE_VENDOR_INVOICE_APPROVED &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_ANCHOR &&
-		x.MOPaid == false &&
-		x.MOSettled == false {
-
-		for i := range v.Items {
-			if x.MOID == v.Items[i].MOID {
-
-				x.ApprovedInvoiceAmount = new_amount
-				v.Items[i].ApprovedInvoiceAmount = new_amount
-
-				x.MOStatus = STATE_VENDOR_INVOICE_APPROVED
-				v.Items[i].MOStatus = STATE_VENDOR_INVOICE_APPROVED
-
-				break
-			}
-
-		}
-	}
-
-	_, err := t.save_invoice(stub, x)
-
-	if err != nil {
-		fmt.Printf("UPDATE_ANCHOR_AUTHORIZED_INVOICE_AMOUNT: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("UPDATE_ANCHOR_AUTHORIZED_INVOICE_AMOUNT: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-*/
-
-//---------------------------------------------------------------------------------------------------------------------------------
-//   PAYMENT MAKER UPDATE INVOICE FUNCTIONS
-//=================================================================================================================================
-//	 update_maker_invoice_payment
-//=================================================================================================================================
-func (t *AssetManagementChaincode) update_maker_invoice_payment(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value, channel string) ([]byte, error) {
-	new_amount, _ := strconv.ParseFloat(string(new_value), 64) // will return an error if the new purchase amount contains non numerical chars
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		// v.Owner 						== caller &&
-		v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_PAYMENT_INITIATED &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_PAYMENT_MAKER &&
-		x.MOPaid == false &&
-		x.MOSettled == false {
-
-		for i := range v.Items {
-			if x.MOID == v.Items[i].MOID {
-
-				x.MOReceivableAmount = new_amount
-				v.Items[i].MOReceivableAmount = new_amount
-
-				x.PaymentChannel = channel
-				v.Items[i].PaymentChannel = channel
-
-				break
-			}
-
-		}
-	}
-
-	_, err := t.save_invoice(stub, x)
-
-	if err != nil {
-		fmt.Printf("UPDATE_MAKER_INVOICE_PAYMENT: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("UPDATE_MAKER_INVOICE_PAYMENT: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//---------------------------------------------------------------------------------------------------------------------------------
-//   PAYMENT CHECKER UPDATE INVOICE FUNCTIONS
-//=================================================================================================================================
-//	 update_checker_invoice_approval
-//=================================================================================================================================
-func (t *AssetManagementChaincode) update_checker_invoice_approval(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string) ([]byte, error) {
-
-	//var pending = 0
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		// v.Owner 						== caller &&
-		v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_PAYMENT_PENDING_APPROVAL &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_PAYMENT_CHECKER &&
-		x.MOPaid == false &&
-		x.MOSettled == false {
-
-		for i := range v.Items {
-			//			pending += v.Items[i].MOAmount
-			if x.MOID == v.Items[i].MOID {
-
-				x.CheckerApprovedPayment = true
-				v.Items[i].CheckerApprovedPayment = true
-
-				x.MOStatus = STATE_INVOICE_PAYMENT_APPROVED
-				v.Items[i].MOStatus = STATE_INVOICE_PAYMENT_APPROVED
-
-				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
-					fmt.Println("QUERY: Error retrieving Parent")
-				} else {
-					f, err := t.retrieve_invoice(stub, x.MOParent)
-					if err != nil {
-						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
-						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
-					}
-
-					for j := range v.Items {
-						if f.MOID == v.Items[j].MOID {
-
-							f.MOStatus = STATE_INVOICE_RETIRED
-							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
-
-							break
-						}
-
-					}
-
-					_, errs := t.save_invoice(stub, f)
-
-					if errs != nil {
-						fmt.Printf("UPDATE_CHECKER_INVOICE_APPROVAL: $!^!* Error saving changes to Invoice: %s", errs)
-						return nil, errors.New(" %@((@& Error saving changes to invoice")
-					}
-
-				}
-
-				break
-
-			}
-
-		}
-
-	}
-
-	_, err := t.save_invoice(stub, x)
-
-	if err != nil {
-		fmt.Printf("UPDATE_CHECKER_INVOICE_APPROVAL: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("UPDATE_CHECKER_INVOICE_APPROVAL: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//=================================================================================================================================
-//	 update_rev_checker_invoice_approval
-//=================================================================================================================================
-func (t *AssetManagementChaincode) update_rev_checker_invoice_approval(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value string) ([]byte, error) {
-
-	var mobox MyBoxItem
-	mobox = x
-
-	//var pending = 0
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		// v.Owner 						== caller &&
-		v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_PAYMENT_APPROVED &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_PAYMENT_CHECKER &&
-		x.MOPaid == false &&
-		x.MOSettled == false {
-
-		mobox.MOID = x.MOID + "-RIU1"
-		mobox.CheckerApprovedPayment = false                    // then make the owner the new owner
-		mobox.MOStatus = STATE_INVOICE_PAYMENT_PENDING_APPROVAL // and mark it in the state of creating purchase order
-		mobox.MOParent = x.MOID
-		mobox.MORemarks = new_value
-		x.MOForks = append(x.MOForks, mobox.MOID)
-
-		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
-		if record != nil {
-			return nil, errors.New("Invoice already exists")
-		}
-
-	}
-
-	_, err := t.save_invoice(stub, mobox)
-
-	if err != nil {
-		fmt.Printf("UPDATE_REV_PAYMENT_CHECKER_INVOICE_APPROVAL: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	bytes, err := stub.GetState("invoiceIDs")
-	if err != nil {
-		return nil, errors.New("Unable to get invoiceIDs")
-	}
-
-	var invoiceIDs Invoice_Holder
-
-	err = json.Unmarshal(bytes, &invoiceIDs)
-	if err != nil {
-		return nil, errors.New("Corrupt Invoice_Holder record")
-	}
-
-	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
-
-	bytes, err = json.Marshal(invoiceIDs)
-	if err != nil {
-		fmt.Print("Error creating Invoice_Holder record")
-	}
-
-	err = stub.PutState("invoiceIDs", bytes)
-	if err != nil {
-		return nil, errors.New("Unable to put the state")
-	}
-
-	v.Items = append(v.Items, (mobox))
-
-	_, errs := t.save_invoice(stub, x)
-
-	if errs != nil {
-		fmt.Printf("UPDATE_REV_PAYMENT_CHECKER_INVOICE_APPROVAL: $!^!* Error saving changes to Invoice: %s", errs)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("UPDATE_REV_PAYMENT_CHECKER_INVOICE_APPROVAL: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//=================================================================================================================================
-//	 update_checker_invoice_payment
-//=================================================================================================================================
-func (t *AssetManagementChaincode) update_checker_invoice_payment(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value0 string, new_value string) ([]byte, error) {
-
-	//var pending = 0
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		// v.Owner 						== caller &&
-		v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_PAYMENT_APPROVED &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_PAYMENT_CHECKER &&
-		x.MOPaid == false &&
-		x.MOSettled == false {
-
-		for i := range v.Items {
-			//			pending += v.Items[i].MOAmount
-			if x.MOID == v.Items[i].MOID {
-				
-				x.TxnStatus = new_value0
-				v.Items[i].TxnStatus = new_value0 
-				
-				if new_value0 == "SUCCESS" {
-					
-					x.UTRNumber = new_value
-					v.Items[i].UTRNumber = new_value
-
-					x.MOPaid = true
-					v.Items[i].MOPaid = true
-
-					x.MOStatus = STATE_INVOICE_PAID
-					v.Items[i].MOStatus = STATE_INVOICE_PAID
-				} else {
-					
-					
-					x.UTRNumber = new_value
-					v.Items[i].UTRNumber = new_value
-
-					x.MOPaid = false
-					v.Items[i].MOPaid = false
-
-					x.MOStatus = STATE_INVOICE_PAYMENT_APPROVED
-					v.Items[i].MOStatus = STATE_INVOICE_PAYMENT_APPROVED
-				}
-
-				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
-					fmt.Println("QUERY: Error retrieving Parent")
-				} else {
-					f, err := t.retrieve_invoice(stub, x.MOParent)
-					if err != nil {
-						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
-						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
-					}
-
-					for j := range v.Items {
-						if f.MOID == v.Items[j].MOID {
-
-							f.MOStatus = STATE_INVOICE_RETIRED
-							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
-
-							break
-						}
-
-					}
-
-					_, errs := t.save_invoice(stub, f)
-
-					if errs != nil {
-						fmt.Printf("UPDATE_CHECKER_INVOICE_PAYMENT: $!^!* Error saving changes to Invoice: %s", errs)
-						return nil, errors.New(" %@((@& Error saving changes to invoice")
-					}
-
-				}
-
-				break
-
-			}
-
-		}
-
-	}
-
-	_, err := t.save_invoice(stub, x)
-
-	if err != nil {
-		fmt.Printf("UPDATE_CHECKER_INVOICE_PAYMENT: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("UPDATE_CHECKER_INVOICE_PAYMENT: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//=================================================================================================================================
-//	 update_rev_checker_invoice_payment
-//=================================================================================================================================
-func (t *AssetManagementChaincode) update_rev_checker_invoice_payment(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value string) ([]byte, error) {
-
-	var mobox MyBoxItem
-	mobox = x
-
-	//var pending = 0
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		// v.Owner 						== caller &&
-		v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_PAID &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_PAYMENT_CHECKER &&
-		x.MOPaid == true &&
-		x.MOSettled == false {
-
-		mobox.MOID = x.MOID + "-RIU2"
-		mobox.MOPaid = false // then make the owner the new owner
-		mobox.UTRNumber = "UNDEFINED"
-		mobox.MOStatus = STATE_INVOICE_PAYMENT_APPROVED // and mark it in the state of creating purchase order
-		mobox.MOParent = x.MOID
-		mobox.MORemarks = new_value
-		x.MOForks = append(x.MOForks, mobox.MOID)
-
-		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
-		if record != nil {
-			return nil, errors.New("Invoice already exists")
-		}
-
-	}
-
-	_, err := t.save_invoice(stub, mobox)
-
-	if err != nil {
-		fmt.Printf("UPDATE_REV_CHECKER_INVOICE_PAYMENT: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	bytes, err := stub.GetState("invoiceIDs")
-	if err != nil {
-		return nil, errors.New("Unable to get invoiceIDs")
-	}
-
-	var invoiceIDs Invoice_Holder
-
-	err = json.Unmarshal(bytes, &invoiceIDs)
-	if err != nil {
-		return nil, errors.New("Corrupt Invoice_Holder record")
-	}
-
-	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
-
-	bytes, err = json.Marshal(invoiceIDs)
-	if err != nil {
-		fmt.Print("Error creating Invoice_Holder record")
-	}
-
-	err = stub.PutState("invoiceIDs", bytes)
-	if err != nil {
-		return nil, errors.New("Unable to put the state")
-	}
-
-	v.Items = append(v.Items, (mobox))
-
-	_, errs := t.save_invoice(stub, x)
-
-	if errs != nil {
-		fmt.Printf("UPDATE_REV_CHECKER_INVOICE_PAYMENT: $!^!* Error saving changes to Invoice: %s", errs)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("UPDATE_REV_CHECKER_INVOICE_PAYMENT: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//=================================================================================================================================
-//	 update_checker_invoice_settlement
-//=================================================================================================================================
-func (t *AssetManagementChaincode) update_checker_invoice_settlement(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value string) ([]byte, error) {
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		// v.Owner 						== caller &&
-		v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_PAID &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_PAYMENT_CHECKER &&
-		x.MOPaid == true &&
-		x.MOSettled == false {
-
-		for i := range v.Items {
-			if x.MOID == v.Items[i].MOID {
-
-				x.MOSettled = true
-				v.Items[i].MOSettled = true
-
-				x.MOStatus = STATE_INVOICE_SETTLED
-				v.Items[i].MOStatus = STATE_INVOICE_SETTLED
-
-				x.SettlementAmount = new_value
-				v.Items[i].SettlementAmount = new_value
-
-				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
-					fmt.Println("QUERY: Error retrieving Parent")
-				} else {
-					f, err := t.retrieve_invoice(stub, x.MOParent)
-					if err != nil {
-						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
-						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
-					}
-
-					for j := range v.Items {
-						if f.MOID == v.Items[j].MOID {
-
-							f.MOStatus = STATE_INVOICE_RETIRED
-							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
-
-							break
-						}
-
-					}
-
-					_, errs := t.save_invoice(stub, f)
-
-					if errs != nil {
-						fmt.Printf("UPDATE_CHECKER_INVOICE_SETTLEMENT: $!^!* Error saving changes to Invoice: %s", errs)
-						return nil, errors.New(" %@((@& Error saving changes to invoice")
-					}
-
-				}
-
-				break
-
-			}
-
-		}
-
-	}
-
-	_, err := t.save_invoice(stub, x)
-
-	if err != nil {
-		fmt.Printf("UPDATE_CHECKER_INVOICE_SETTLEMENT: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("UPDATE_CHECKER_INVOICE_SETTLEMENT: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//=================================================================================================================================
-//	 update_rev_checker_invoice_settlement
-//=================================================================================================================================
-func (t *AssetManagementChaincode) update_rev_checker_invoice_settlement(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value string) ([]byte, error) {
-
-	var mobox MyBoxItem
-	mobox = x
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		// v.Owner 						== caller &&
-		v.Settled == false &&
-		x.MOStatus == STATE_INVOICE_SETTLED &&
-		x.MOOwner == string(callerAccount) &&
-		caller_affiliation == ROLE_PAYMENT_CHECKER &&
-		x.MOPaid == true &&
-		x.MOSettled == true {
-
-		mobox.MOID = x.MOID + "-RIU3"
-		mobox.MOSettled = false // then make the owner the new owner
-		mobox.SettlementAmount = "UNDEFINED"
-		mobox.MOStatus = STATE_INVOICE_PAID // and mark it in the state of creating purchase order
-		mobox.MOParent = x.MOID
-		mobox.MORemarks = new_value
-		x.MOForks = append(x.MOForks, mobox.MOID)
-
-		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
-		if record != nil {
-			return nil, errors.New("Invoice already exists")
-		}
-
-	}
-
-	_, err := t.save_invoice(stub, mobox)
-
-	if err != nil {
-		fmt.Printf("UPDATE_REV_CHECKER_INVOICE_SETTLEMENT: $!^!* Error saving changes to Invoice: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	bytes, err := stub.GetState("invoiceIDs")
-	if err != nil {
-		return nil, errors.New("Unable to get invoiceIDs")
-	}
-
-	var invoiceIDs Invoice_Holder
-
-	err = json.Unmarshal(bytes, &invoiceIDs)
-	if err != nil {
-		return nil, errors.New("Corrupt Invoice_Holder record")
-	}
-
-	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
-
-	bytes, err = json.Marshal(invoiceIDs)
-	if err != nil {
-		fmt.Print("Error creating Invoice_Holder record")
-	}
-
-	err = stub.PutState("invoiceIDs", bytes)
-	if err != nil {
-		return nil, errors.New("Unable to put the state")
-	}
-
-	v.Items = append(v.Items, (mobox))
-
-	_, errs := t.save_invoice(stub, x)
-
-	if errs != nil {
-		fmt.Printf("UPDATE_REV_CHECKER_INVOICE_SETTLEMENT: $!^!* Error saving changes to Invoice: %s", errs)
-		return nil, errors.New(" %@((@& Error saving changes to invoice")
-	}
-
-	_, err = t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("UPDATE_REV_CHECKER_INVOICE_SETTLEMENT: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//=================================================================================================================================
-//	 settlement_anchorprogram
-//=================================================================================================================================
-func (t *AssetManagementChaincode) settlement_anchorprogram(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string) ([]byte, error) {
-
-	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
-		// v.Owner 						== caller                      &&
-		v.Settled == false &&
-		caller_affiliation == ROLE_PAYMENT_CHECKER {
-
-		v.Settled = true
-		v.Status = STATE_ANCHOR_PROGRAM_CLOSED
-
-	}
-
-	_, err := t.save_changes(stub, v)
-
-	if err != nil {
-		fmt.Printf("settlement_anchorprogram: $!^!* Error saving changes to AnchorProgram: %s", err)
-		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
-	}
-
-	return nil, nil
-
-}
-
-//=================================================================================================================================
-//	Query - Called on chaincode query. Takes a function name passed and calls that function. Passes the
-//  		initial arguments passed are passed on to the called function.
-//=================================================================================================================================
-func (t *AssetManagementChaincode) Query(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
-
-	callerRole, err := stub.ReadCertAttribute("role")
-	if err != nil {
-		fmt.Printf("Error reading attribute 'role' [%v] \n", err)
-		return nil, fmt.Errorf("Failed fetching caller role. Error was [%v]", err)
-	}
-	caller_affiliation := string(callerRole[:])
-
-	callerAccount, err := stub.ReadCertAttribute("account")
-	if err != nil {
-		return nil, fmt.Errorf("Failed fetching caller account. Error was [%v]", err)
-	}
-
-	if function == "get_anchorprogram_details" {
-		if len(args) != 1 {
-			fmt.Printf("Incorrect number of arguments passed")
-			return nil, errors.New("QUERY: Incorrect number of arguments passed")
-		}
-
-		v, err := t.retrieve_anchorprogram(stub, args[0])
-		if err != nil {
-			fmt.Printf("QUERY: Error retrieving po: %s", err)
-			return nil, errors.New("QUERY: Error retrieving anchor program " + err.Error())
-		}
-
-		return t.get_anchorprogram_details(stub, v, callerAccount, caller_affiliation)
-
-	} else if function == "get_invoice_details" {
-		if len(args) != 1 {
-			fmt.Printf("Incorrect number of arguments passed")
-			return nil, errors.New("QUERY: Incorrect number of arguments passed")
-		}
-
-		x, errs := t.retrieve_invoice(stub, args[0])
-		if errs != nil {
-			fmt.Printf("QUERY: Error retrieving invoice: %s", errs)
-			return nil, errors.New("QUERY: Error retrieving invoice " + errs.Error())
-		}
-
-		return t.get_invoice_details(stub, x, callerAccount, caller_affiliation)
-
-	} else if function == "get_anchorprograms" {
-		return t.get_anchorprograms(stub, callerAccount, caller_affiliation)
-	} else if function == "get_anchorprogramIDs" {
-		return t.get_anchorprogramIDs(stub, callerAccount, caller_affiliation)
-	} else if function == "get_invoiceIDs" {
-		return t.get_invoiceIDs(stub, callerAccount, caller_affiliation)
-	} else if function == "get_invoices" {
-		return t.get_invoices(stub, callerAccount, caller_affiliation)
-	}
-
-	return nil, errors.New("Received unknown function invocation")
-
-}
-
-//=================================================================================================================================
-//	 Read Functions
-//=================================================================================================================================
-//	 get_invoice_details -----> get transaction details for a particular order invoice
-//=================================================================================================================================
-func (t *AssetManagementChaincode) get_invoice_details(stub shim.ChaincodeStubInterface, v MyBoxItem, callerAccount []byte, caller_affiliation string) ([]byte, error) {
-
-	bytes, err := json.Marshal(v)
-
-	if err != nil {
-		return nil, errors.New("GET_INVOICE_DETAILS: Invalid Invoice object")
-	}
-
-	if v.MOOwner == string(callerAccount) ||
-		v.InvoiceRaisedBy == string(callerAccount) ||
-		caller_affiliation == ROLE_ADMIN {
-
-		return bytes, nil
-	} else {
-		return nil, errors.New("Permission Denied")
-	}
-
-}
-
-//=================================================================================================================================
-//	 get_anchorprogram_details -----> get transaction details for a particular order
-//=================================================================================================================================
-func (t *AssetManagementChaincode) get_anchorprogram_details(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string) ([]byte, error) {
-
-	bytes, err := json.Marshal(v)
-
-	if err != nil {
-		return nil, errors.New("GET_ANCHORPROGRAM_DETAILS: Invalid AnchorProgram object")
-	}
-
-	if v.Owner == string(callerAccount) ||
-		v.POraisedBy == string(callerAccount) ||
-		caller_affiliation == ROLE_ADMIN {
-
-		return bytes, nil
-	} else {
-		return nil, errors.New("Permission Denied")
-	}
-
-}
-
-//=================================================================================================================================
-//	 get_invoices ----> get details of all invoices
-//=================================================================================================================================
-
-func (t *AssetManagementChaincode) get_invoices(stub shim.ChaincodeStubInterface, callerAccount []byte, caller_affiliation string) ([]byte, error) {
-
-	bytes, err := stub.GetState("invoiceIDs")
-	if err != nil {
-		return nil, errors.New("Unable to get invoiceIDs")
-	}
-
-	var invoiceIDs Invoice_Holder
-
-	err = json.Unmarshal(bytes, &invoiceIDs)
-	if err != nil {
-		return nil, errors.New("Corrupt Invoice_Holder")
-	}
-
-	result := "["
-
-	var temp []byte
-	var v MyBoxItem
-
-	for _, po := range invoiceIDs.INVOICEs {
-
-		v, err = t.retrieve_invoice(stub, po)
-		if err != nil {
-			return nil, errors.New("Failed to retrieve Invoice")
-		}
-
-		temp, err = t.get_invoice_details(stub, v, callerAccount, caller_affiliation)
-		if err == nil {
-			result += string(temp) + ","
-		}
-	}
-
-	if len(result) == 1 {
-		result = "[]"
-	} else {
-		result = result[:len(result)-1] + "]"
-	}
-
-	return []byte(result), nil
-}
-
-//=================================================================================================================================
-//	 get_anchorprograms ----> get details of all orders
-//=================================================================================================================================
-
-func (t *AssetManagementChaincode) get_anchorprograms(stub shim.ChaincodeStubInterface, callerAccount []byte, caller_affiliation string) ([]byte, error) {
-
-	bytes, err := stub.GetState("anchorProgramIDs")
-	if err != nil {
-		return nil, errors.New("Unable to get anchorProgramIDs")
-	}
-
-	var anchorProgramIDs Anchor_Program_Holder
-
-	err = json.Unmarshal(bytes, &anchorProgramIDs)
-	if err != nil {
-		return nil, errors.New("Corrupt Anchor_Program_Holder")
-	}
-
-	result := "["
-
-	var temp []byte
-	var v AnchorProgram
-
-	for _, po := range anchorProgramIDs.ANCHOR_PROGRAMs {
-
-		v, err = t.retrieve_anchorprogram(stub, po)
-		if err != nil {
-			return nil, errors.New("Failed to retrieve Anchor Program")
-		}
-
-		temp, err = t.get_anchorprogram_details(stub, v, callerAccount, caller_affiliation)
-		if err == nil {
-			result += string(temp) + ","
-		}
-	}
-
-	if len(result) == 1 {
-		result = "[]"
-	} else {
-		result = result[:len(result)-1] + "]"
-	}
-
-	return []byte(result), nil
-}
-
-//=================================================================================================================================
-//	 get_anchorprogramIDs ----> get ID details of all orders
-//=================================================================================================================================
-
-func (t *AssetManagementChaincode) get_anchorprogramIDs(stub shim.ChaincodeStubInterface, callerAccount []byte, caller_affiliation string) ([]byte, error) {
-
-	bytes, err := stub.GetState("anchorProgramIDs")
-	if err != nil {
-		return nil, errors.New("Unable to get anchorProgramIDs")
-	}
-
-	var anchorProgramIDs Anchor_Program_Holder
-
-	err = json.Unmarshal(bytes, &anchorProgramIDs)
-	if err != nil {
-		return nil, errors.New("Corrupt Anchor_Program_Holder")
-	}
-
-	result := "["
-
-	var v AnchorProgram
-	var list ProgramIDs
-
-	for _, po := range anchorProgramIDs.ANCHOR_PROGRAMs {
-
-		v, err = t.retrieve_anchorprogram(stub, po)
-		if err != nil {
-			return nil, errors.New("Failed to retrieve Anchor Program")
-		}
-
-		if v.Owner == string(callerAccount) ||
-			v.POraisedBy == string(callerAccount) ||
-			v.PORaisedAgainst == string(callerAccount) ||
-			caller_affiliation == ROLE_ADMIN {
-
-			list.AnchorID = v.AnchorID
-			list.POraisedBy = v.POraisedBy
-			list.AnchorName = v.AnchorName
-			list.AnchorAccountNo = v.AnchorAccountNo
-			list.AnchorPOAmount = v.AnchorPOAmount
-			list.AnchorIFSCCode = v.AnchorIFSCCode
-			list.AnchorLimit = v.AnchorLimit
-			list.AnchorExpiryDate = v.AnchorExpiryDate
-			list.AnchorInterest = v.AnchorInterest
-			list.AnchorGarceInterest = v.AnchorGarceInterest
-			list.AnchorGarceInterestperiod = v.AnchorGarceInterestperiod
-			list.AnchorPenalInterest = v.AnchorPenalInterest
-			list.AnchorLiquidation = v.AnchorLiquidation
-			list.AnchorPoID = v.AnchorPoID
-			list.VendorID = v.VendorID
-			list.VendorFName = v.VendorFName
-			list.VendorLName = v.VendorLName
-			list.Vendoremail = v.Vendoremail
-			list.Vendorphone = v.Vendorphone
-			list.Vendorpanno = v.Vendorpanno
-			list.Vendoraddress = v.Vendoraddress
-			list.Vendorbank = v.Vendorbank
-			list.Vendorbaddress = v.Vendorbaddress
-			list.Vendoraccountno = v.Vendoraccountno
-			list.Vendorifsccode = v.Vendorifsccode
-			list.Vendorlimit = v.Vendorlimit
-			list.VendorExpirydate = v.VendorExpirydate
-			list.POAcknowledged = v.POAcknowledged
-			//list.POTimestamp = v.POTimestamp
-			list.Status = v.Status
-			list.AnchorProgramID = v.AnchorProgramID
-			list.Settled = v.Settled
-
-			temp, err := json.Marshal(list)
-
-			if err == nil {
-				result += string(temp) + ","
-			}
-		}
-	}
-
-	if len(result) == 1 {
-		result = "[]"
-	} else {
-		result = result[:len(result)-1] + "]"
-	}
-
-	return []byte(result), nil
-}
-
-//=================================================================================================================================
-//	 get_invoiceIDs ----> get ID details of all orders
-//=================================================================================================================================
-
-func (t *AssetManagementChaincode) get_invoiceIDs(stub shim.ChaincodeStubInterface, callerAccount []byte, caller_affiliation string) ([]byte, error) {
-
-	bytes, err := stub.GetState("invoiceIDs")
-	if err != nil {
-		return nil, errors.New("Unable to get invoiceIDs")
-	}
-
-	var invoiceIDs Invoice_Holder
-
-	err = json.Unmarshal(bytes, &invoiceIDs)
-	if err != nil {
-		return nil, errors.New("Corrupt Invoice_Holder")
-	}
-
-	result := "["
-
-	var v MyBoxItem
-	var list InvoiceIDs
-
-	for _, po := range invoiceIDs.INVOICEs {
-
-		v, err = t.retrieve_invoice(stub, po)
-		if err != nil {
-			return nil, errors.New("Failed to retrieve Invoice")
-		}
-
-		if v.MOOwner == string(callerAccount) ||
-			v.InvoiceRaisedBy == string(callerAccount) ||
-			v.InvoiceRaisedAgainst == string(callerAccount) ||
-			caller_affiliation == ROLE_ADMIN {
-
-			list.POID = v.POID
-			list.MOID = v.MOID
-			list.MOOwner = v.MOOwner
-			list.AnchorName = v.AnchorName
-			list.AnchorAccountNo = v.AnchorAccountNo
-			list.AnchorPOAmount = v.AnchorPOAmount
-			list.AnchorIFSCCode = v.AnchorIFSCCode
-			list.AnchorInterest = v.AnchorInterest
-			list.InvoiceRaisedBy = v.InvoiceRaisedBy
-			list.InvoiceRaisedAgainst = v.InvoiceRaisedAgainst
-			list.MOAmount = v.MOAmount
-			list.InvoiceID = v.InvoiceID
-			list.ApprovedInvoiceAmount = v.ApprovedInvoiceAmount
-			list.MOStatus = v.MOStatus
-			list.CheckerApprovedPayment = v.CheckerApprovedPayment
-			//list.MOTimestamp = v.MOTimestamp
-			list.MOPaid = v.MOPaid
-			list.UTRNumber = v.UTRNumber
-			list.MOSettled = v.MOSettled
-			list.Vendorfname = v.Vendorfname
-			list.Vendorbank = v.Vendorbank
-			list.Vendorifsccode = v.Vendorifsccode
-			list.AnchorPoID = v.AnchorPoID
-
-			temp, err := json.Marshal(list)
-
-			if err == nil {
-				result += string(temp) + ","
-			}
-		}
-	}
-
-	if len(result) == 1 {
-		result = "[]"
-	} else {
-		result = result[:len(result)-1] + "]"
-	}
-
-	return []byte(result), nil
-}
-
-//=================================================================================================================================
-//	 Main - main - Starts up the chaincode
-//=================================================================================================================================
-func main() {
-	err := shim.Start(new(AssetManagementChaincode))
-	if err != nil {
-		fmt.Printf("Error starting AssetManagementChaincode: %s", err)
-	}
-}
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+//==============================================================================================================================
+//	 Participant types - Each participant type is mapped to an integer which we use to compare to the value stored in a
+//						 user's eCert
+//==============================================================================================================================
+const ROLE_ADMIN = "assigner"
+const ROLE_ANCHOR = "Anchor"
+const ROLE_VENDOR = "Vendor"
+const ROLE_PAYMENT_MAKER = "PaymentMaker"
+const ROLE_PAYMENT_CHECKER = "PaymentChecker"
+const ROLE_KYC_OFFICER = "KYCOfficer"
+
+//==============================================================================================================================
+//	 Status types - Anchor Program lifecycle is broken down into 8 statuses, this is part of the business logic to determine what can
+//					be done to the Credential at points in it's lifecycle
+//==============================================================================================================================
+const STATE_TEMPLATE = 0
+const STATE_PROGRAM_INITIATED = 1
+const STATE_PURCHASE_ORDER_PLACED = 2
+const STATE_INVOICE_RAISED = 3
+const STATE_VENDOR_INVOICE_APPROVED = 4
+const STATE_ANCHOR_AUTHORISED_INVOICE_PAYMENT = 5
+const STATE_INVOICE_PAYMENT_REQUESTED = 6
+const STATE_INVOICE_PAYMENT_INITIATED = 7
+const STATE_INVOICE_PAYMENT_PENDING_APPROVAL = 8
+const STATE_INVOICE_PAYMENT_APPROVED = 9
+const STATE_INVOICE_PAID = 10
+const STATE_INVOICE_SETTLED = 11
+const STATE_ANCHOR_PROGRAM_CLOSED = 12
+const STATE_INVOICE_PAYMENT_FAILED = 13
+const STATE_INVOICE_WRITTEN_OFF = 14
+const STATE_INVOICE_RETIRED = 20
+
+//==============================================================================================================================
+//	 Match results - Outcome of the PO / GRN / invoice three-way match run when the anchor approves an invoice amount
+//==============================================================================================================================
+const MATCH_PASSED = "MATCHED"
+const MATCH_OVERRIDDEN = "OVERRIDDEN"
+
+//==============================================================================================================================
+//	 PII encryption - Prefix marking a personal or bank detail field that is stored encrypted, see require_sealed. Values
+//					  in the older PII_PREFIX form also carry a mask in the clear; migrate_pii_fields removes it.
+//==============================================================================================================================
+const PII_SEALED = "enc:v2:"
+const PII_PREFIX = "enc:v1:"
+
+//==============================================================================================================================
+//	 Key directory - Where a validating peer keeps its keys unless YBL_KEY_DIR says otherwise, see local_key
+//==============================================================================================================================
+const KEY_DIR = "/etc/hyperledger/ybl/keys"
+
+//==============================================================================================================================
+//	 Participant status - Whether a registered account may take part in transactions
+//==============================================================================================================================
+const PARTICIPANT_ACTIVE = "ACTIVE"
+const PARTICIPANT_SUSPENDED = "SUSPENDED"
+
+//==============================================================================================================================
+//	 KYC status - The review status of a vendor's KYC items, and of the vendor's KYC as a whole
+//==============================================================================================================================
+const KYC_PENDING = "PENDING"
+const KYC_VERIFIED = "VERIFIED"
+const KYC_REJECTED = "REJECTED"
+
+//==============================================================================================================================
+//	 KYC requirements - The items that must all be verified before a vendor's KYC is, and how long a verification lasts
+//						when the KYC officer does not set an expiry
+//==============================================================================================================================
+var KYC_REQUIRED_ITEMS = []string{"PAN", "ADDRESS", "BANK"}
+
+const KYC_VALIDITY_DAYS = 730
+
+//==============================================================================================================================
+//	 Admin principals - An admin is named either by the role in its eCert or by its account. Changes to the admin set are
+//						proposed and applied once enough admins approve.
+//==============================================================================================================================
+const ADMIN_BY_ROLE = "role"
+const ADMIN_BY_ACCOUNT = "account"
+
+const ADMIN_CHANGE_PENDING = "PENDING"
+const ADMIN_CHANGE_APPLIED = "APPLIED"
+
+//==============================================================================================================================
+//	 Freeze scopes - What a freeze applies to: one program, or every program of a vendor or of an anchor
+//==============================================================================================================================
+const FREEZE_PROGRAM = "program"
+const FREEZE_VENDOR = "vendor"
+const FREEZE_ANCHOR = "anchor"
+
+var FREEZE_REASON_CODES = []string{"SUSPECTED_FRAUD", "SANCTIONS", "COURT_ORDER", "KYC_LAPSE", "DISPUTE", "OTHER"}
+
+//==============================================================================================================================
+//	 Write-off reason codes - Why an invoice is written off, see update_admin_invoice_write_off
+//==============================================================================================================================
+var WRITE_OFF_REASON_CODES = []string{"ANCHOR_INSOLVENT", "DISPUTE_LOST", "FRAUD", "UNRECOVERABLE", "SETTLEMENT_WAIVED", "OTHER"}
+
+//==============================================================================================================================
+//	 Payment failures - Failed payment attempts after which an invoice escalates to the admin, unless the program sets its own
+//==============================================================================================================================
+const DEFAULT_MAX_PAYMENT_FAILURES = 3
+
+//==============================================================================================================================
+//	 Payment batch status - The status of a payment batch, and the result recorded for each invoice in it
+//==============================================================================================================================
+const BATCH_PENDING_APPROVAL = "PENDING_APPROVAL"
+const BATCH_APPROVED = "APPROVED"
+const BATCH_REJECTED = "REJECTED"
+const BATCH_PARTIAL = "PARTIAL"
+const BATCH_AWAITING_APPROVALS = "AWAITING_APPROVALS"
+const BATCH_SKIPPED = "SKIPPED"
+
+//==============================================================================================================================
+//	 Fees - The events a program charges fees at, and how each fee is worked out
+//==============================================================================================================================
+const FEE_ONBOARDING = "ONBOARDING"
+const FEE_DISBURSEMENT = "DISBURSEMENT"
+const FEE_RENEWAL = "RENEWAL"
+
+const DEFAULT_RENEWAL_MONTHS = 12
+
+const FEE_FLAT = "FLAT"
+const FEE_PERCENT = "PERCENT"
+const FEE_SLAB = "SLAB"
+
+//==============================================================================================================================
+//	 Finance base - Whether a program finances an invoice's gross amount or its taxable value, and the form of a GSTIN
+//==============================================================================================================================
+const FINANCE_GROSS = "GROSS"
+const FINANCE_NET_OF_TAX = "NET_OF_TAX"
+
+var GSTIN_FORMAT = regexp.MustCompile(`^[0-9]{2}[A-Z]{5}[0-9]{4}[A-Z][1-9A-Z]Z[0-9A-Z]$`)
+
+//==============================================================================================================================
+//	 Ledger accounts - The accounts journal lines are posted to, each mapped to a GL account code, and the transitions that
+//					   post them
+//==============================================================================================================================
+const GL_RECEIVABLE = "RECEIVABLE"
+const GL_FEE_RECEIVABLE = "FEE_RECEIVABLE"
+const GL_SETTLEMENT = "SETTLEMENT"
+const GL_INTEREST_INCOME = "INTEREST_INCOME"
+const GL_FEE_INCOME = "FEE_INCOME"
+const GL_GST_PAYABLE = "GST_PAYABLE"
+const GL_WRITE_OFF = "WRITE_OFF"
+
+const JOURNAL_DISBURSEMENT = "DISBURSEMENT"
+const JOURNAL_SETTLEMENT = "SETTLEMENT"
+const JOURNAL_PROGRAM_FEES = "PROGRAM_FEES"
+const JOURNAL_WRITE_OFF = "WRITE_OFF"
+
+//==============================================================================================================================
+//	 Structure Definitions
+//==============================================================================================================================
+//	Chaincode - A blank struct for use with Shim (A HyperLedger included go file used for get/put state
+//				and other HyperLedger functions)
+//==============================================================================================================================
+type AssetManagementChaincode struct {
+}
+
+//==============================================================================================================================
+//	AnchorProgram - Defines the structure for an AnchorProgram object. JSON on right tells it what JSON fields to map to
+//			  that element when reading a JSON object into the struct e.g. JSON name -> Struct Name.
+//==============================================================================================================================
+type AnchorProgram struct {
+	Owner                     string      `json:"owner"`
+	POraisedBy                string      `json:"poraisedby"`
+	PORaisedAgainst           string      `json:"poraisedAgainst"`
+	AnchorName                string      `json:"anchorname"`
+	AnchorID                  string      `json:"anchorid"`
+	AnchorAccountNo           string      `json:"anchoraccountno"`
+	AnchorPOAmount            float64     `json:"anchorpoamount"`
+	AnchorPOQuantity          float64     `json:"anchorpoquantity"`
+	AnchorIFSCCode            string      `json:"anchorifsc"`
+	AnchorAgreement           string      `json:"anchorAgreement"`
+	VendorAgreement           string      `json:"vendorAgreement"`
+	AnchorLimit               float64     `json:"anchorlimit"`
+	AnchorExpiryDate          string      `json:"anchorexpdate"`
+	AnchorInterest            string      `json:"anchorinterest"`
+	AnchorGarceInterest       string      `json:"anchorgraceinterest"`
+	AnchorGarceInterestperiod string      `json:"anchorgraceinterestperiod"`
+	AnchorPenalInterest       string      `json:"anchorpenalinterest"`
+	AnchorLiquidation         string      `json:"anchorliquidation"`
+	AnchorPoDocument          DocumentRef `json:"anchorpodocument"`
+	AnchorPoImage             string      `json:"anchorpoimage,omitempty"` // only on programs whose PO was attached before DocumentRef
+	AnchorPoID                string      `json:"anchorpoid"`
+	VendorID                  string      `json:"vendorid"`
+	VendorFName               string      `json:"vendorfname"`
+	VendorLName               string      `json:"vendorlname"`
+	Vendoremail               string      `json:"vendoremail"`
+	Vendorphone               string      `json:"vendorphone"`
+	Vendorpanno               string      `json:"vendorpanno"`
+	Vendorgstin               string      `json:"vendorgstin"`
+	Vendoraddress             string      `json:"vendoraddress"`
+	Vendorbank                string      `json:"vendorbank"`
+	Vendorbaddress            string      `json:"vendorbaddress"`
+	Vendoraccountno           string      `json:"vendoraccountno"`
+	Vendorifsccode            string      `json:"vendorifsccode"`
+	Vendorlimit               float64     `json:"vendorlimit"`
+	VendorExpirydate          string      `json:"vendorexpirydate"`
+	//POTimestamp               time.Time   `json:"poRaisedTime"`
+	POAcknowledged            bool        `json:"poacknowledged"`
+	Items                     []MyBoxItem `json:"invoices"`
+	Status                    int         `json:"status"`
+	AnchorProgramID           string      `json:"anchorprogramID"`
+	PoForks                   []string    `json:"poForks"`
+	POParent                  string      `json:"poParent"`
+	PORemarks                 string      `json:"poRemarks"`
+	Settled                   bool        `json:"settled"`
+
+	GoodsReceipts     []GoodsReceiptNote `json:"goodsReceipts"`
+	QuantityTolerance float64            `json:"quantityTolerance"` // percentage
+	ValueTolerance    float64            `json:"valueTolerance"`    // percentage
+
+	VendorPanDigest string `json:"vendorpandigest"` // keyed hash of the PAN made by the client, compared in place of Vendorpanno
+
+	ApprovalBands []ApprovalBand `json:"approvalBands"`
+
+	TermsCollection string `json:"termsCollection"` // collection holding the commercial terms, see store_terms
+	TermsHash       string `json:"termsHash"`       // SHA-256 of the salted terms held in the collection
+	terms_loaded    bool
+	terms_salt      string
+
+	Frozen *Freeze `json:"frozen,omitempty"` // filled in by queries, never stored
+
+	ProgramType string `json:"programType"` // checked against the programTypes a payment channel allows
+
+	MaxPaymentFailures int `json:"maxPaymentFailures"` // 0 uses DEFAULT_MAX_PAYMENT_FAILURES
+
+	PaymentBatches []string `json:"paymentBatches"`
+
+	FeeSchedule []FeeRule `json:"feeSchedule"`
+	FeeLines    []FeeLine `json:"feeLines"` // onboarding and renewal fees charged on the program
+
+	FinanceBase string `json:"financeBase"` // FINANCE_GROSS or FINANCE_NET_OF_TAX, "" is gross
+
+	JournalEntries []string `json:"journalEntries"` // keys of the journal entries for program fees
+}
+
+//==============================================================================================================================
+//	MyBoxItem - Defines the structure for an invoice object. JSON on right tells it what JSON fields to map to
+//			  that element when reading a JSON object into the struct e.g. JSON name -> Struct Name.
+//==============================================================================================================================
+type MyBoxItem struct {
+	POID                   string    `json:"poIDr"`
+	MOID                   string    `json:"moID"`
+	MOOwner                string    `json:"moOwner"`
+	AnchorName             string    `json:"anchorname"`
+	AnchorAccountNo        string    `json:"anchoraccountno"`
+	AnchorPOAmount         float64   `json:"anchorpoamount"`
+	AnchorIFSCCode         string    `json:"anchorifsc"`
+	AnchorInterest         string    `json:"anchorinterest"`
+	InvoiceRaisedBy        string    `json:"invraisedby"`
+	InvoiceRaisedAgainst   string    `json:"invraisedAgainst"`
+	MOAmount               float64   `json:"moAmount"`
+	InvoiceID              string    `json:"invoiceid"`
+	Vendorfname            string    `json:"vendorFname"`
+	Vendorbank             string    `json:"vendorBank"`
+	Vendorifsccode         string    `json:"venDorbank"`
+	AnchorPoID             string    `json:"anchOrPOID"`
+	ApprovedInvoiceAmount  float64   `json:"approvedinvoiceAmount"`
+	MOStatus               int       `json:"moStatus"`
+	SettlementAmount       string    `json:"settlementAmount"`
+	CheckerApprovedPayment bool      `json:"checkerApprovedPayment"`
+	//MOTimestamp            time.Time `json:"invoiceRaisedTime"`
+	MOReceivableAmount     float64   `json:"moReceivableAmount"`
+	PaymentChannel         string    `json:"paymentChannel"`
+	MOPaid                 bool      `json:"mopaid"`
+	TxnStatus              string    `json:"txnStatus"`
+	UTRNumber              string    `json:"utrnumber"`
+	MOForks                []string  `json:"moForks"`
+	MOParent               string    `json:"moParent"`
+	MoOriginal             float64   `json:"moOriginalAmount"`
+	MORemarks              string    `json:"moRemarks"`
+	MOSettled              bool      `json:"mosettled"`
+
+	InvoiceQuantity     float64         `json:"invoiceQuantity"`
+	MatchResult         string          `json:"matchResult"`
+	MatchVariances      []MatchVariance `json:"matchVariances"`
+	MatchOverrideReason string          `json:"matchOverrideReason"`
+
+	InvoiceDate string `json:"invoiceDate"`
+	Fingerprint string `json:"fingerprint"`
+
+	InvoiceDocument DocumentRef `json:"invoicedocument"`
+	InvoiceImage    string      `json:"invoiceimage,omitempty"` // only on invoices whose file was attached before DocumentRef
+
+	RequiredApprovals int               `json:"requiredApprovals"`
+	Approvals         []PaymentApproval `json:"approvals"`
+
+	PaymentMaker string `json:"paymentMaker"`
+	RevisedBy    string `json:"revisedBy"` // account that raised a revision, or revised the approved amount
+
+	Frozen *Freeze `json:"frozen,omitempty"` // filled in by queries, never stored
+
+	PaymentAttempts    []PaymentAttempt    `json:"paymentAttempts"`
+	PaymentFailures    int                 `json:"paymentFailures"` // failed attempts since the last admin resolution
+	PaymentResolutions []PaymentResolution `json:"paymentResolutions"`
+
+	PaymentBatch string `json:"paymentBatch"` // the batch the invoice awaits approval in, if any
+
+	WriteOffAmount      float64 `json:"writeOffAmount"`
+	WriteOffReasonCode  string  `json:"writeOffReasonCode"`
+	WriteOffReason      string  `json:"writeOffReason"`
+	WriteOffRequestedBy string  `json:"writeOffRequestedBy"` // the first admin; a second admin approves the write-off
+	WriteOffRequestedAt string  `json:"writeOffRequestedAt"`
+	WrittenOffBy        string  `json:"writtenOffBy"`
+	WrittenOffAt        string  `json:"writtenOffAt"`
+
+	FeeLines        []FeeLine `json:"feeLines"` // disbursement fees, worked out when the maker enters the payment
+	NetDisbursement float64   `json:"netDisbursement"`
+
+	Tax               InvoiceTax `json:"tax"`
+	FinanceableAmount float64    `json:"financeableAmount"`
+
+	JournalEntries []string `json:"journalEntries"` // keys of the journal entries the invoice posted
+}
+
+//==============================================================================================================================
+//	DocumentRef - Defines the structure recorded for a PO or invoice document in place of the file itself. The file is kept
+//			  off-chain at URI and identified by the SHA-256 Hash of its contents.
+//==============================================================================================================================
+type DocumentRef struct {
+	Hash       string `json:"hash"`
+	MediaType  string `json:"mediaType"`
+	Size       int64  `json:"size"`
+	URI        string `json:"uri"`
+	RecordedAt string `json:"recordedAt"`
+	RecordedBy string `json:"recordedBy"`
+}
+
+//==============================================================================================================================
+//	DocumentVerification - Defines the structure returned by verify_document. TxID is set when the check is against the
+//			  document the record held after that transaction rather than the current one.
+//==============================================================================================================================
+type DocumentVerification struct {
+	RecordID   string `json:"recordID"`
+	TxID       string `json:"txID,omitempty"`
+	Function   string `json:"function,omitempty"`
+	Matches    bool   `json:"matches"`
+	Hash       string `json:"hash"`
+	MediaType  string `json:"mediaType"`
+	Size       int64  `json:"size"`
+	RecordedAt string `json:"recordedAt"`
+	RecordedBy string `json:"recordedBy"`
+}
+
+//==============================================================================================================================
+//	PIIField - Names one personal or bank detail field of a record for encryption, decryption and masking. Name is
+//			  bound into the ciphertext so a value cannot be moved to another field.
+//==============================================================================================================================
+type PIIField struct {
+	Name  string
+	Value *string
+}
+
+//==============================================================================================================================
+//	PIIKeyMetadata - Defines the caller metadata clients once used to send keys, e.g. {"piiKey":"<base64>"} or
+//			  {"collectionKeys":{"terms_AP1":"<base64>"}}. Metadata is written to every block, so a transaction
+//			  carrying a key is now rejected, see no_keys_in_metadata.
+//==============================================================================================================================
+type PIIKeyMetadata struct {
+	PIIKey         string            `json:"piiKey"`
+	CollectionKeys map[string]string `json:"collectionKeys"`
+}
+
+//==============================================================================================================================
+//	CommercialTerms - Defines the terms of a program kept in its private collection rather than on the program itself
+//==============================================================================================================================
+type CommercialTerms struct {
+	AnchorAgreement           string  `json:"anchorAgreement"`
+	VendorAgreement           string  `json:"vendorAgreement"`
+	AnchorLimit               float64 `json:"anchorlimit"`
+	AnchorInterest            string  `json:"anchorinterest"`
+	AnchorGarceInterest       string  `json:"anchorgraceinterest"`
+	AnchorGarceInterestperiod string  `json:"anchorgraceinterestperiod"`
+	AnchorPenalInterest       string  `json:"anchorpenalinterest"`
+	AnchorLiquidation         string  `json:"anchorliquidation"`
+	Vendorlimit               float64 `json:"vendorlimit"`
+	Salt                      string  `json:"salt"`
+}
+
+//==============================================================================================================================
+//	ApprovalBand - Defines the number of distinct payment checkers that must approve a payment of MinAmount or more.
+//			  Payments below every band need one approval.
+//==============================================================================================================================
+type ApprovalBand struct {
+	MinAmount float64 `json:"minAmount"`
+	Approvals int     `json:"approvals"`
+}
+
+//==============================================================================================================================
+//	PaymentApproval - Defines the structure recording one payment checker's approval of an invoice payment
+//==============================================================================================================================
+type PaymentApproval struct {
+	Account    string `json:"account"`
+	ApprovedAt string `json:"approvedAt"`
+}
+
+//==============================================================================================================================
+//	Participant - Defines the structure of an entry in the participant registry, held at "participant_<account>"
+//==============================================================================================================================
+type Participant struct {
+	AccountID       string   `json:"accountID"`
+	Roles           []string `json:"roles"`
+	Organization    string   `json:"organization"`
+	DisplayName     string   `json:"displayName"`
+	Status          string   `json:"status"`
+	CertFingerprint string   `json:"certFingerprint"`
+	UpdatedAt       string   `json:"updatedAt"`
+	UpdatedBy       string   `json:"updatedBy"`
+}
+
+//==============================================================================================================================
+//	Participant Holder - Defines the structure that holds the accounts of all registered participants.
+//				Used as an index when listing participants.
+//==============================================================================================================================
+type Participant_Holder struct {
+	PARTICIPANTs []string `json:"participants"`
+}
+
+//==============================================================================================================================
+//	KYCItem - Defines one KYC item submitted for a vendor, e.g. its PAN card, proof of address or a cancelled cheque.
+//			  Document references the scanned copy; Data holds the details read from it, encrypted as PII.
+//==============================================================================================================================
+type KYCItem struct {
+	ItemType    string      `json:"itemType"`
+	Document    DocumentRef `json:"document"`
+	Data        string      `json:"data"`
+	Status      string      `json:"status"`
+	SubmittedBy string      `json:"submittedBy"`
+	SubmittedAt string      `json:"submittedAt"`
+	ReviewedBy  string      `json:"reviewedBy"`
+	ReviewedAt  string      `json:"reviewedAt"`
+	Reason      string      `json:"reason"`
+}
+
+//==============================================================================================================================
+//	KYCRecord - Defines the structure of a vendor's KYC, held at "kyc_<vendorID>". Account is the vendor's own account,
+//			  assigned by an admin, see assign_kyc_account.
+//==============================================================================================================================
+type KYCRecord struct {
+	VendorID   string    `json:"vendorID"`
+	Account    string    `json:"account"`
+	Items      []KYCItem `json:"items"`
+	Status     string    `json:"status"`
+	VerifiedAt string    `json:"verifiedAt"`
+	VerifiedBy string    `json:"verifiedBy"`
+	ExpiryDate string    `json:"expiryDate"`
+	UpdatedAt  string    `json:"updatedAt"`
+}
+
+//==============================================================================================================================
+//	KYC Holder - Defines the structure that holds the vendor IDs with a KYC record. Used as an index for get_kyc_due.
+//==============================================================================================================================
+type KYC_Holder struct {
+	VENDORs []string `json:"vendors"`
+}
+
+//==============================================================================================================================
+//	KYCDue - Defines the structure returned by get_kyc_due for each vendor whose KYC must be redone
+//==============================================================================================================================
+type KYCDue struct {
+	VendorID   string `json:"vendorID"`
+	Account    string `json:"account"`
+	Status     string `json:"status"`
+	ExpiryDate string `json:"expiryDate"`
+}
+
+//==============================================================================================================================
+//	AdminSet - Defines the managed set of administrators, held at "adminSet". Quorum is the number of distinct admins
+//			  that must approve a change to the set.
+//==============================================================================================================================
+type AdminSet struct {
+	Principals []AdminPrincipal `json:"principals"`
+	Quorum     int              `json:"quorum"`
+	Version    int              `json:"version"`
+	UpdatedAt  string           `json:"updatedAt"`
+	UpdatedBy  string           `json:"updatedBy"`
+}
+
+type AdminPrincipal struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+//==============================================================================================================================
+//	AdminProposal - Defines a proposed change to the admin set, held at "adminProposal_<id>"
+//==============================================================================================================================
+type AdminProposal struct {
+	ProposalID string          `json:"proposalID"`
+	Action     string          `json:"action"`
+	Kind       string          `json:"kind"`
+	Value      string          `json:"value"`
+	NewKind    string          `json:"newKind"`
+	NewValue   string          `json:"newValue"`
+	Status     string          `json:"status"`
+	ProposedBy string          `json:"proposedBy"`
+	ProposedAt string          `json:"proposedAt"`
+	Approvals  []AdminApproval `json:"approvals"`
+	AppliedAt  string          `json:"appliedAt"`
+}
+
+type AdminApproval struct {
+	Account    string `json:"account"`
+	ApprovedAt string `json:"approvedAt"`
+}
+
+//==============================================================================================================================
+//	AdminChangeEvent - Defines the payload of the admin_change event set by every proposal, approval and applied change
+//==============================================================================================================================
+type AdminChangeEvent struct {
+	Event           string        `json:"event"`
+	Proposal        AdminProposal `json:"proposal"`
+	By              string        `json:"by"`
+	AdminSetVersion int           `json:"adminSetVersion"`
+}
+
+//==============================================================================================================================
+//	Freeze - Defines the structure of a freeze on a program, vendor or anchor, held at "freeze_<scope>_<target>".
+//			  Lifting it takes a request and the approval of a second admin.
+//==============================================================================================================================
+type Freeze struct {
+	Scope               string `json:"scope"`
+	Target              string `json:"target"`
+	ReasonCode          string `json:"reasonCode"`
+	Reason              string `json:"reason"`
+	FrozenBy            string `json:"frozenBy"`
+	FrozenAt            string `json:"frozenAt"`
+	UnfreezeRequestedBy string `json:"unfreezeRequestedBy,omitempty"`
+	UnfreezeRequestedAt string `json:"unfreezeRequestedAt,omitempty"`
+	UnfreezeReason      string `json:"unfreezeReason,omitempty"`
+	UnfrozenBy          string `json:"unfrozenBy,omitempty"`
+	UnfrozenAt          string `json:"unfrozenAt,omitempty"`
+}
+
+//==============================================================================================================================
+//	Freeze Holder - Defines the structure that holds the "<scope>_<target>" of every freeze ever made. Used by get_freezes.
+//==============================================================================================================================
+type Freeze_Holder struct {
+	FREEZEs []string `json:"freezes"`
+}
+
+//==============================================================================================================================
+//	Delegation - Defines the structure of a delegation of authority, letting Delegate act for Principal in Role from From
+//			  to To inclusive. Programs limits it to those anchor programs; empty covers all of the principal's.
+//==============================================================================================================================
+type Delegation struct {
+	DelegationID string   `json:"delegationID"`
+	Principal    string   `json:"principal"`
+	Delegate     string   `json:"delegate"`
+	Role         string   `json:"role"`
+	From         string   `json:"from"`
+	To           string   `json:"to"`
+	Programs     []string `json:"programs"`
+	CreatedBy    string   `json:"createdBy"`
+	CreatedAt    string   `json:"createdAt"`
+	Revoked      bool     `json:"revoked"`
+	RevokedBy    string   `json:"revokedBy"`
+	RevokedAt    string   `json:"revokedAt"`
+}
+
+//==============================================================================================================================
+//	Delegation Holder - Defines the structure that holds the IDs of the delegations granted by a principal. Kept at
+//				"delegations_<principal>".
+//==============================================================================================================================
+type Delegation_Holder struct {
+	DELEGATIONs []string `json:"delegations"`
+}
+
+//==============================================================================================================================
+//	ActionRecord - Defines one entry of the action log kept for each program and invoice at "actions_<id>". Account is
+//			  the account the action was taken as; Delegate is set when someone acted for it under a delegation.
+//			  Document is the PO or invoice document the record held once the action was taken.
+//==============================================================================================================================
+type ActionRecord struct {
+	Function     string      `json:"function"`
+	Account      string      `json:"account"`
+	Role         string      `json:"role"`
+	Delegate     string      `json:"delegate"`
+	DelegationID string      `json:"delegationID"`
+	At           string      `json:"at"`
+	TxID         string      `json:"txID"`
+	Document     DocumentRef `json:"document"`
+}
+
+//==============================================================================================================================
+//	ChannelCatalogue - Defines the payment channels a maker may use, held at "channelCatalogue"
+//==============================================================================================================================
+type ChannelCatalogue struct {
+	Channels  []PaymentChannelRule `json:"channels"`
+	Version   int                  `json:"version"`
+	UpdatedAt string               `json:"updatedAt"`
+	UpdatedBy string               `json:"updatedBy"`
+}
+
+//==============================================================================================================================
+//	PaymentChannelRule - Defines one payment channel. A MaxAmount of 0 is no limit, empty ProgramTypes allows every program
+//			  type and an empty UTRFormat accepts any reference.
+//==============================================================================================================================
+type PaymentChannelRule struct {
+	Channel      string   `json:"channel"`
+	MinAmount    float64  `json:"minAmount"`
+	MaxAmount    float64  `json:"maxAmount"`
+	ProgramTypes []string `json:"programTypes"`
+	UTRFormat    string   `json:"utrFormat"`
+}
+
+//==============================================================================================================================
+//	PaymentAttempt - Defines one outcome the payment checker recorded for an invoice's payment
+//==============================================================================================================================
+type PaymentAttempt struct {
+	Status     string `json:"status"`
+	ReasonCode string `json:"reasonCode"`
+	UTR        string `json:"utr"`
+	At         string `json:"at"`
+	By         string `json:"by"`
+	TxID       string `json:"txID"`
+}
+
+//==============================================================================================================================
+//	PaymentResolution - Defines an admin's decision on an invoice whose payment failed too often
+//==============================================================================================================================
+type PaymentResolution struct {
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+	At     string `json:"at"`
+	By     string `json:"by"`
+}
+
+//==============================================================================================================================
+//	PaymentBatch - Defines a batch of a program's invoices a payment maker sends to one payment checker to approve or
+//			  reject as a unit, held at "batch_<batchID>"
+//==============================================================================================================================
+type PaymentBatch struct {
+	BatchID         string      `json:"batchID"`
+	AnchorProgramID string      `json:"anchorProgramID"`
+	Items           []BatchItem `json:"items"`
+	Total           float64     `json:"total"`
+	Count           int         `json:"count"`
+	Status          string      `json:"status"`
+	Maker           string      `json:"maker"`
+	Checker         string      `json:"checker"`
+	CreatedAt       string      `json:"createdAt"`
+	DecidedAt       string      `json:"decidedAt"`
+	Reason          string      `json:"reason"`
+}
+
+//==============================================================================================================================
+//	BatchItem - Defines one invoice of a payment batch and what the checker's decision did to it
+//==============================================================================================================================
+type BatchItem struct {
+	MOID   string  `json:"moID"`
+	Amount float64 `json:"amount"`
+	Result string  `json:"result"`
+	Detail string  `json:"detail"`
+}
+
+//==============================================================================================================================
+//	ClosingStatement - Defines the totals of a program recorded when it is closed, held at "closing_<anchorProgramID>"
+//==============================================================================================================================
+type ClosingStatement struct {
+	AnchorProgramID string  `json:"anchorProgramID"`
+	POValue         float64 `json:"poValue"`
+	Invoiced        float64 `json:"invoiced"`
+	Approved        float64 `json:"approved"`
+	Disbursed       float64 `json:"disbursed"`
+	Repaid          float64 `json:"repaid"`
+	Interest        float64 `json:"interest"` // repaid less disbursed on settled invoices
+	WrittenOff      float64 `json:"writtenOff"`
+	Invoices        int     `json:"invoices"`
+	Settled         int     `json:"settled"`
+	Retired         int     `json:"retired"`
+	WrittenOffCount int     `json:"writtenOffCount"`
+	ClosedAt        string  `json:"closedAt"`
+	ClosedBy        string  `json:"closedBy"`
+	TxID            string  `json:"txID"`
+}
+
+//==============================================================================================================================
+//	OpenItem - Defines an invoice that stops its program from closing, returned as part of the error from
+//			  settlement_anchorprogram
+//==============================================================================================================================
+type OpenItem struct {
+	MOID     string `json:"moID"`
+	MOStatus int    `json:"moStatus"`
+	MOOwner  string `json:"moOwner"`
+}
+
+//==============================================================================================================================
+//	FeeRule - Defines one fee of a program's fee schedule and the event it is charged at. A FLAT fee is Amount, a PERCENT
+//			  fee is Rate percent of the base amount and a SLAB fee uses the slab with the highest MinAmount the base reaches.
+//			  GSTRate is the percentage of GST charged on the fee. A RENEWAL fee is charged at most once every
+//			  PeriodMonths, by default DEFAULT_RENEWAL_MONTHS.
+//==============================================================================================================================
+type FeeRule struct {
+	Code         string    `json:"code"`
+	Description  string    `json:"description"`
+	Event        string    `json:"event"`
+	Basis        string    `json:"basis"`
+	Amount       float64   `json:"amount"`
+	Rate         float64   `json:"rate"`
+	Slabs        []FeeSlab `json:"slabs"`
+	GSTRate      float64   `json:"gstRate"`
+	PeriodMonths int       `json:"periodMonths"`
+}
+
+//==============================================================================================================================
+//	FeeSlab - Defines one slab of a SLAB fee: a flat Amount plus Rate percent of the base amount
+//==============================================================================================================================
+type FeeSlab struct {
+	MinAmount float64 `json:"minAmount"`
+	Amount    float64 `json:"amount"`
+	Rate      float64 `json:"rate"`
+}
+
+//==============================================================================================================================
+//	FeeLine - Defines a fee charged on a program or invoice
+//==============================================================================================================================
+type FeeLine struct {
+	Code  string  `json:"code"`
+	Event string  `json:"event"`
+	Base  float64 `json:"base"`
+	Fee   float64 `json:"fee"`
+	GST   float64 `json:"gst"`
+	Total float64 `json:"total"`
+	At    string  `json:"at"`
+	TxID  string  `json:"txID"`
+}
+
+//==============================================================================================================================
+//	InvoiceTax - Defines the GST and TDS breakdown of an invoice. The taxable value and GST add up to the invoice amount;
+//			  CGST and SGST apply within a state and IGST between states.
+//==============================================================================================================================
+type InvoiceTax struct {
+	SupplierGSTIN string  `json:"supplierGSTIN"`
+	BuyerGSTIN    string  `json:"buyerGSTIN"`
+	TaxableValue  float64 `json:"taxableValue"`
+	CGST          float64 `json:"cgst"`
+	SGST          float64 `json:"sgst"`
+	IGST          float64 `json:"igst"`
+	TDS           float64 `json:"tds"`
+}
+
+//==============================================================================================================================
+//	JournalEntry - Defines the balanced journal lines one transaction posted, held at "journal_<date>_<txID>"
+//==============================================================================================================================
+type JournalEntry struct {
+	EntryID         string        `json:"entryID"`
+	Date            string        `json:"date"`
+	At              string        `json:"at"`
+	TxID            string        `json:"txID"`
+	Event           string        `json:"event"`
+	AnchorProgramID string        `json:"anchorProgramID"`
+	MOID            string        `json:"moID"`
+	Lines           []JournalLine `json:"lines"`
+	PostedBy        string        `json:"postedBy"`
+	Reverses        string        `json:"reverses"`   // the entry this one reverses, if any
+	ReversedBy      string        `json:"reversedBy"` // the entry that reversed this one, if any
+}
+
+//==============================================================================================================================
+//	JournalLine - Defines one debit or credit of a journal entry against a GL account code
+//==============================================================================================================================
+type JournalLine struct {
+	Account   string  `json:"account"`
+	GLCode    string  `json:"glCode"`
+	Debit     float64 `json:"debit"`
+	Credit    float64 `json:"credit"`
+	Narrative string  `json:"narrative"`
+}
+
+//==============================================================================================================================
+//	GLAccounts - Defines the GL account code posted for each ledger account, held at "glAccounts"
+//==============================================================================================================================
+type GLAccounts struct {
+	Codes     map[string]string `json:"codes"`
+	Version   int               `json:"version"`
+	UpdatedAt string            `json:"updatedAt"`
+	UpdatedBy string            `json:"updatedBy"`
+}
+
+//==============================================================================================================================
+//	SoDRules - Defines the segregation of duties rules checked on every transition, held at "sodRules"
+//==============================================================================================================================
+type SoDRules struct {
+	DistinctMakerChecker    bool   `json:"distinctMakerChecker"`    // maker and checkers of an invoice's payment differ
+	DistinctAnchorVendor    bool   `json:"distinctAnchorVendor"`    // anchor and vendor accounts and organizations differ
+	NoSelfApprovedRevisions bool   `json:"noSelfApprovedRevisions"` // a revision is approved by someone other than its reviser
+	UpdatedAt               string `json:"updatedAt"`
+	UpdatedBy               string `json:"updatedBy"`
+}
+
+//==============================================================================================================================
+//	AccessPolicy - Defines the structure of the access policy document held at "accessPolicy". Every version is also kept
+//			  at "accessPolicy_v<version>". A call is allowed if any rule for its function matches.
+//==============================================================================================================================
+type AccessPolicy struct {
+	Version   int          `json:"version"`
+	UpdatedAt string       `json:"updatedAt"`
+	UpdatedBy string       `json:"updatedBy"`
+	Rules     []PolicyRule `json:"rules"`
+
+	Redactions map[string][]string `json:"redactions"` // role -> JSON fields removed from what that role reads
+
+	AllowUnregistered bool `json:"allowUnregistered"` // let accounts missing from the participant registry call; on by default, see check_caller
+}
+
+//==============================================================================================================================
+//	PolicyRule - Defines one rule of the access policy. Empty lists and zero values place no restriction. States are the
+//			  program status, or the invoice status for functions that act on an invoice.
+//==============================================================================================================================
+type PolicyRule struct {
+	Function       string   `json:"function"`
+	States         []int    `json:"states"`
+	Roles          []string `json:"roles"`
+	RecipientRoles []string `json:"recipientRoles"`
+	Owner          bool     `json:"owner"`     // caller must own the program or invoice
+	SameOrg        bool     `json:"sameOrg"`   // caller and recipient must carry the same "org" attribute
+	AmountMax      float64  `json:"amountMax"` // rule only covers amounts up to this value
+}
+
+//==============================================================================================================================
+//	Viewer - What the caller of a query can see, worked out once per query by viewer()
+//==============================================================================================================================
+type Viewer struct {
+	Account string
+	Role    string
+	All     bool
+	Redact  []string
+	Key     []byte
+}
+
+//==============================================================================================================================
+//	PolicyRequest - The call being checked against the access policy. Built by Invoke from the caller's and recipient's
+//			  certificate attributes and the record the call acts on.
+//==============================================================================================================================
+type PolicyRequest struct {
+	Function      string
+	CallerAccount string
+	CallerRole    string
+	CallerOrg     string
+	RecipientRole string
+	RecipientOrg  string
+	Owner         string
+	State         int
+	Amount        float64
+
+	Admin bool // Caller is in the managed admin set; rules for ROLE_ADMIN match on this rather than the eCert role
+
+	Delegate string // Account actually signing when CallerAccount is a principal it acts for, see active_delegation
+}
+
+//==============================================================================================================================
+//	GoodsReceiptNote - Defines the structure for a goods receipt note recorded by the anchor against the purchase order
+//			  of an Anchor Program. Used as the receipt leg of the three-way match.
+//==============================================================================================================================
+type GoodsReceiptNote struct {
+	GRNID            string  `json:"grnID"`
+	AnchorPoID       string  `json:"anchorpoid"`
+	ReceivedQuantity float64 `json:"receivedQuantity"`
+	ReceivedValue    float64 `json:"receivedValue"`
+	ReceivedDate     string  `json:"receivedDate"`
+	RecordedBy       string  `json:"recordedBy"`
+}
+
+//==============================================================================================================================
+//	MatchVariance - Defines the structure for one check of the three-way match. Variance is Actual less Expected, and
+//			  Tolerance is the percentage of Expected that Actual may exceed it by.
+//==============================================================================================================================
+type MatchVariance struct {
+	Check           string  `json:"check"`
+	Expected        float64 `json:"expected"`
+	Actual          float64 `json:"actual"`
+	Variance        float64 `json:"variance"`
+	Tolerance       float64 `json:"tolerance"`
+	WithinTolerance bool    `json:"withinTolerance"`
+}
+
+//==============================================================================================================================
+//	Anchor Program Holder - Defines the structure that holds all the anchorIDs for Anchor Program records that have been created.
+//				Used as an index when querying all anchor records.
+//==============================================================================================================================
+
+type Anchor_Program_Holder struct {
+	ANCHOR_PROGRAMs []string `json:"anchorprograms"`
+}
+
+//==============================================================================================================================
+//	ProgramIDs - Defines the structure that holds all the IDs for Anchor Program records that have been created.
+//				Used as an index when querying all anchor records.
+//==============================================================================================================================
+
+type ProgramIDs struct {
+	POraisedBy                string    `json:"poraisedby"`
+	AnchorName                string    `json:"anchorname"`
+	AnchorID                  string    `json:"anchorid"`
+	AnchorAccountNo           string    `json:"anchoraccountno"`
+	AnchorPOAmount            float64   `json:"anchorpoamount"`
+	AnchorIFSCCode            string    `json:"anchorifsc"`
+	AnchorLimit               float64   `json:"anchorlimit"`
+	AnchorExpiryDate          string    `json:"anchorexpdate"`
+	AnchorInterest            string    `json:"anchorinterest"`
+	AnchorGarceInterest       string    `json:"anchorgraceinterest"`
+	AnchorGarceInterestperiod string    `json:"anchorgraceinterestperiod"`
+	AnchorPenalInterest       string    `json:"anchorpenalinterest"`
+	AnchorLiquidation         string    `json:"anchorliquidation"`
+	AnchorPoID                string    `json:"anchorpoid"`
+	VendorID                  string    `json:"vendorid"`
+	VendorFName               string    `json:"vendorfname"`
+	VendorLName               string    `json:"vendorlname"`
+	Vendoremail               string    `json:"vendoremail"`
+	Vendorphone               string    `json:"vendorphone"`
+	Vendorpanno               string    `json:"vendorpanno"`
+	Vendorgstin               string    `json:"vendorgstin"`
+	Vendoraddress             string    `json:"vendoraddress"`
+	Vendorbank                string    `json:"vendorbank"`
+	Vendorbaddress            string    `json:"vendorbaddress"`
+	Vendoraccountno           string    `json:"vendoraccountno"`
+	Vendorifsccode            string    `json:"vendorifsccode"`
+	Vendorlimit               float64   `json:"vendorlimit"`
+	VendorExpirydate          string    `json:"vendorexpirydate"`
+	//POTimestamp               time.Time `json:"poRaisedTime"`
+	POAcknowledged            bool      `json:"poacknowledged"`
+	Invoices                  []InvoiceIDs
+	Status                    int    `json:"status"`
+	AnchorProgramID           string `json:"anchorprogramID"`
+	Settled                   bool   `json:"settled"`
+
+	Frozen *Freeze `json:"frozen,omitempty"`
+}
+
+//==============================================================================================================================
+//	Invoice Holder - Defines the structure that holds all the invoiceIDs for Invoice records that have been created.
+//				Used as an index when querying all invoice records.
+//==============================================================================================================================
+
+type Invoice_Holder struct {
+	INVOICEs []string `json:"invoices"`
+}
+
+//==============================================================================================================================
+//	InvoiceIDs - Defines the structure that holds all the IDs for invoice records that have been created.
+//				Used as an index when querying all invoice records.
+//==============================================================================================================================
+
+type InvoiceIDs struct {
+	POID                   string    `json:"poIDr"`
+	MOID                   string    `json:"moID"`
+	MOOwner                string    `json:"moOwner"`
+	AnchorName             string    `json:"anchorname"`
+	AnchorAccountNo        string    `json:"anchoraccountno"`
+	AnchorPOAmount         float64   `json:"anchorpoamount"`
+	AnchorIFSCCode         string    `json:"anchorifsc"`
+	AnchorInterest         string    `json:"anchorinterest"`
+	InvoiceRaisedBy        string    `json:"invraisedby"`
+	InvoiceRaisedAgainst   string    `json:"invraisedAgainst"`
+	MOAmount               float64   `json:"moAmount"`
+	InvoiceID              string    `json:"invoiceid"`
+	Vendorfname            string    `json:"vendorFname"`
+	Vendorbank             string    `json:"vendorBank"`
+	Vendorifsccode         string    `json:"venDorbank"`
+	AnchorPoID             string    `json:"anchOrPOID"`
+	ApprovedInvoiceAmount  float64   `json:"approvedinvoiceAmount"`
+	MOStatus               int       `json:"moStatus"`
+	CheckerApprovedPayment bool      `json:"checkerApprovedPayment"`
+	//MOTimestamp            time.Time `json:"invoiceRaisedTime"`
+	MOReceivableAmount     float64   `json:"moReceivableAmount"`
+	PaymentChannel         string    `json:"paymentChannel"`
+	MOPaid                 bool      `json:"mopaid"`
+	UTRNumber              string    `json:"utrnumber"`
+	MOSettled              bool      `json:"mosettled"`
+	MatchResult            string    `json:"matchResult"`
+	InvoiceDate            string    `json:"invoiceDate"`
+
+	Frozen *Freeze `json:"frozen,omitempty"`
+}
+
+//==============================================================================================================================
+//	SuspectedDuplicate - Defines the structure that groups live invoices from different revision chains that look like the
+//				same commercial invoice. Returned by get_suspected_duplicate_invoices for manual review.
+//==============================================================================================================================
+
+type SuspectedDuplicate struct {
+	Reason   string             `json:"reason"`
+	Key      string             `json:"key"`
+	Invoices []DuplicateInvoice `json:"invoices"`
+}
+
+type DuplicateInvoice struct {
+	POID        string  `json:"poIDr"`
+	MOID        string  `json:"moID"`
+	InvoiceID   string  `json:"invoiceid"`
+	InvoiceDate string  `json:"invoiceDate"`
+	MOAmount    float64 `json:"moAmount"`
+	MOStatus    int     `json:"moStatus"`
+}
+
+//==============================================================================================================================
+//	Init Function - Called when the user deploys the chaincode
+//==============================================================================================================================
+func (t *AssetManagementChaincode) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	//	myLogger.Info("[AssetManagementChaincode] Init")
+
+	var anchorProgramIDs Anchor_Program_Holder
+	var invoiceIDs Invoice_Holder
+
+	existing, err := stub.GetState("anchorProgramIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get anchorProgramIDs")
+	}
+	if len(existing) == 0 { // Re-running Init must not lose the programs already recorded
+		bytes, err := json.Marshal(anchorProgramIDs)
+		if err != nil {
+			return nil, errors.New("Error creating Anchor_Program_Holder record")
+		}
+
+		err = stub.PutState("anchorProgramIDs", bytes)
+		if err != nil {
+			return nil, errors.New("Unable to put the state")
+		}
+	}
+
+	existing, err = stub.GetState("invoiceIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get invoiceIDs")
+	}
+	if len(existing) == 0 {
+		bites, err := json.Marshal(invoiceIDs)
+		if err != nil {
+			return nil, errors.New("Error creating Invoice_Holder record")
+		}
+
+		err = stub.PutState("invoiceIDs", bites)
+		if err != nil {
+			return nil, errors.New("Unable to put the state")
+		}
+	}
+
+	if len(args) != 0 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 0")
+	}
+
+	// Set the role of the users that are allowed to assign assets
+	// The metadata will contain the role of the users that are allowed to assign assets
+	assignerRole, err := stub.GetCallerMetadata()
+	fmt.Printf("Assiger role is %v\n", string(assignerRole))
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting metadata, [%v]", err)
+	}
+
+	if len(assignerRole) == 0 {
+		return nil, errors.New("Invalid assigner role. Empty.")
+	}
+
+	existing, err = stub.GetState("adminSet")
+	if err != nil {
+		return nil, errors.New("Unable to get adminSet")
+	}
+	if len(existing) == 0 { // Once seeded the admin set only changes through approved proposals
+		err = stub.PutState("assignerRole", assignerRole)
+		if err != nil {
+			return nil, errors.New("Unable to put the state")
+		}
+
+		admins := AdminSet{Principals: []AdminPrincipal{{Kind: ADMIN_BY_ROLE, Value: string(assignerRole)}}, Quorum: 1, Version: 1}
+		admins.UpdatedAt, err = tx_timestamp(stub)
+		if err != nil {
+			return nil, err
+		}
+		admins.UpdatedBy = "deploy"
+
+		_, err = t.save_admin_set(stub, admins)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err = stub.GetState("accessPolicy")
+	if err != nil {
+		return nil, errors.New("Unable to get accessPolicy")
+	}
+	if len(existing) == 0 {
+		policy := default_access_policy()
+		policy.Version = 1
+		policy.UpdatedAt, err = tx_timestamp(stub)
+		if err != nil {
+			return nil, err
+		}
+		policy.UpdatedBy = "deploy"
+
+		_, err = t.save_access_policy(stub, policy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+//==============================================================================================================================
+//	 retrieve_invoice - Gets the state of the data at MOID in the ledger then converts it from the stored
+//					JSON into the MyBoxItem struct for use in the contract. Returns the MyBoxItem struct.
+//					Returns empty v if it errors.
+//==============================================================================================================================
+func (t *AssetManagementChaincode) retrieve_invoice(stub shim.ChaincodeStubInterface, moid string) (MyBoxItem, error) {
+
+	var x MyBoxItem
+
+	bytes, err := stub.GetState(moid)
+	if err != nil {
+		fmt.Printf("RETRIEVE_INVOICE: Failed to invoke order_code: %s", err)
+		return x, errors.New("RETRIEVE_INVOICE: Error retrieving order with InvoiceID = " + moid)
+	}
+
+	err = json.Unmarshal(bytes, &x)
+	if err != nil {
+		fmt.Printf("RETRIEVE_INVOICE: Corrupt invoice record "+string(bytes)+": %s", err)
+		return x, errors.New("RETRIEVE_INVOICE: Corrupt invoice record" + string(bytes))
+	}
+
+	return x, nil
+}
+
+//==============================================================================================================================
+//	 retrieve_anchorprogram - Gets the state of the data at poID in the ledger then converts it from the stored
+//					JSON into the AnchorProgram struct for use in the contract. Returns the AnchorProgram struct.
+//					Returns empty v if it errors.
+//==============================================================================================================================
+func (t *AssetManagementChaincode) retrieve_anchorprogram(stub shim.ChaincodeStubInterface, anchorProgramID string) (AnchorProgram, error) {
+
+	var v AnchorProgram
+
+	bytes, err := stub.GetState(anchorProgramID)
+	if err != nil {
+		fmt.Printf("RETRIEVE_ANCHORPROGRAM: Failed to invoke order_code: %s", err)
+		return v, errors.New("RETRIEVE_ANCHORPROGRAM: Error retrieving order with AnchorProgramID = " + anchorProgramID)
+	}
+
+	err = json.Unmarshal(bytes, &v)
+	if err != nil {
+		fmt.Printf("RETRIEVE_ANCHORPROGRAM: Corrupt anchorprogram record "+string(bytes)+": %s", err)
+		return v, errors.New("RETRIEVE_ANCHORPROGRAM: Corrupt anchorprogram record" + string(bytes))
+	}
+
+	return v, nil
+}
+
+//==============================================================================================================================
+// copy_invoice - Copies the MyBoxItem struct as revision. MyBox struct passed in a JSON format. Uses the shim file's
+//				  method 'PutState'.
+//==============================================================================================================================
+func (t *AssetManagementChaincode) copy_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem) (MyBoxItem, error) {
+
+	var box MyBoxItem
+	box = x
+	box.MOID = x.MOID + "-R1"
+	//userb.count = userb.count + 1
+	return box, nil
+
+}
+
+//==============================================================================================================================
+// copy_anchorprogram - Copies the Anchorprogram struct as revision. struct passed in a JSON format. Uses the shim file's
+//				  method 'PutState'.
+//==============================================================================================================================
+func (t *AssetManagementChaincode) copy_anchorprogram(stub shim.ChaincodeStubInterface, v AnchorProgram) (AnchorProgram, error) {
+
+	var pobox AnchorProgram
+	pobox = v
+	pobox.AnchorProgramID = v.AnchorProgramID + "-R1"
+	//userb.count = userb.count + 1
+	return pobox, nil
+
+}
+
+//==============================================================================================================================
+// save_invoice - Writes to the ledger the MyBox struct passed in a JSON format. Uses the shim file's
+//				  method 'PutState'.
+//==============================================================================================================================
+func (t *AssetManagementChaincode) save_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem) (bool, error) {
+
+	bytes, err := json.Marshal(x)
+	if err != nil {
+		fmt.Printf("SAVE_CHANGES: Error converting Invoice record: %s", err)
+		return false, errors.New("Error converting Invoice record")
+	}
+
+	err = stub.PutState(x.MOID, bytes)
+	if err != nil {
+		fmt.Printf("SAVE_CHANGES: Error storing Invoice record: %s", err)
+		return false, errors.New("Error storing Invoice record")
+	}
+
+	return true, nil
+}
+
+//==============================================================================================================================
+// save_changes - Writes to the ledger the Anchor Program struct passed in a JSON format. Uses the shim file's
+//				  method 'PutState'.
+//==============================================================================================================================
+func (t *AssetManagementChaincode) save_changes(stub shim.ChaincodeStubInterface, v AnchorProgram) (bool, error) {
+
+	err := t.store_terms(stub, &v)
+	if err != nil {
+		fmt.Printf("SAVE_CHANGES: Error storing commercial terms: %s", err)
+		return false, err
+	}
+
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		fmt.Printf("SAVE_CHANGES: Error converting Anchor Progam record: %s", err)
+		return false, errors.New("Error converting Anchor Progam record")
+	}
+
+	err = stub.PutState(v.AnchorProgramID, bytes)
+	if err != nil {
+		fmt.Printf("SAVE_CHANGES: Error storing Anchor Progam record: %s", err)
+		return false, errors.New("Error storing Anchor Progam record")
+	}
+
+	return true, nil
+}
+
+//==============================================================================================================================
+// tx_timestamp - Returns the timestamp of the current transaction in RFC 3339 form. Used in place of time.Now() so
+//				  every peer records the same value.
+//==============================================================================================================================
+func tx_timestamp(stub shim.ChaincodeStubInterface) (string, error) {
+
+	ts, err := stub.GetTxTimestamp()
+	if err != nil {
+		fmt.Printf("TX_TIMESTAMP: Error getting transaction timestamp: %s", err)
+		return "", errors.New("Error getting transaction timestamp")
+	}
+
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC().Format(time.RFC3339), nil
+}
+
+//==============================================================================================================================
+// parse_document - Reads a document reference passed as JSON, e.g.
+//				  {"hash":"<hex sha-256>","mediaType":"application/pdf","size":48213,"uri":"docstore://<hash>"}
+//				  and stamps it with the transaction time and the account recording it.
+//==============================================================================================================================
+func (t *AssetManagementChaincode) parse_document(stub shim.ChaincodeStubInterface, raw string, callerAccount []byte) (DocumentRef, error) {
+
+	var document DocumentRef
+
+	err := json.Unmarshal([]byte(raw), &document)
+	if err != nil {
+		return document, errors.New("Invalid document reference, expected JSON with hash, mediaType, size and uri")
+	}
+
+	document.Hash = strings.ToLower(document.Hash)
+
+	decoded, err := hex.DecodeString(document.Hash)
+	if err != nil || len(decoded) != sha256.Size {
+		return document, errors.New("Invalid document hash, expected a hex encoded SHA-256")
+	}
+
+	if document.MediaType == "" || document.Size <= 0 || document.URI == "" {
+		return document, errors.New("Document reference is missing its media type, size or URI")
+	}
+
+	document.RecordedAt, err = tx_timestamp(stub)
+	if err != nil {
+		return document, err
+	}
+	document.RecordedBy = string(callerAccount)
+
+	return document, nil
+}
+
+//==============================================================================================================================
+// optional_arg - Returns args[i] if the caller supplied it, otherwise an empty string. Used for arguments that were
+//				  added to a function after clients were already calling it.
+//==============================================================================================================================
+func optional_arg(args []string, i int) string {
+
+	if len(args) > i {
+		return args[i]
+	}
+
+	return ""
+}
+
+//==============================================================================================================================
+// has_po_document - Whether the program's PO document is attached, either as a reference or, on programs set up before
+//				  references, as the file itself
+//==============================================================================================================================
+func has_po_document(v AnchorProgram) bool {
+
+	return v.AnchorPoDocument.Hash != "" || (v.AnchorPoImage != "" && v.AnchorPoImage != "UNDEFINED")
+}
+
+//==============================================================================================================================
+// has_invoice_document - Whether the invoice's document is attached, either as a reference or as the file itself
+//==============================================================================================================================
+func has_invoice_document(x MyBoxItem) bool {
+
+	return x.InvoiceDocument.Hash != "" || (x.InvoiceImage != "" && x.InvoiceImage != "UNDEFINED")
+}
+
+//==============================================================================================================================
+// Private collections - Fabric 0.6 has no private data collections: every peer on the channel holds all of world
+//				  state. Commercial terms are instead kept in an encrypted side record per collection, keyed by the
+//				  hash of the terms, and the program only records that hash. The collection key is held by the
+//				  validating peers like the PII key, see local_key, and the client seals new terms with it before they
+//				  are sent, see open_terms. The chaincode reads the terms to check limits, so every validating peer can
+//				  read them; blocks, non-validating peers and backups hold only ciphertext. Who may see the terms in a
+//				  query is decided by the access policy, not by who holds the key.
+//==============================================================================================================================
+//	 collection_key - Returns the peer's key for a collection, <collection>.key or else terms.key, or nil if it holds
+//					  neither. The first key used to write to a collection is registered for it.
+//==============================================================================================================================
+func (t *AssetManagementChaincode) collection_key(stub shim.ChaincodeStubInterface, collection string) ([]byte, error) {
+
+	key, err := local_key(collection)
+	if err == nil && key == nil {
+		key, err = local_key("terms")
+	}
+	if err != nil || key == nil {
+		return nil, err
+	}
+
+	check, err := stub.GetState("collectionKeyCheck_" + collection)
+	if err != nil {
+		return nil, errors.New("Unable to get the key check for collection " + collection)
+	}
+
+	if len(check) != 0 && !hmac.Equal(check, []byte(collection_key_check(key, collection))) {
+		return nil, errors.New("Key does not match the key registered for collection " + collection)
+	}
+
+	return key, nil
+}
+
+func collection_key_check(key []byte, collection string) string {
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("collection-key-check|" + collection))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+//==============================================================================================================================
+// terms_collection - The collection holding a program's commercial terms. Revisions keep their original's collection.
+//==============================================================================================================================
+func terms_collection(v AnchorProgram) string {
+
+	if v.TermsCollection != "" {
+		return v.TermsCollection
+	}
+
+	return "terms_" + v.AnchorProgramID
+}
+
+//==============================================================================================================================
+// load_terms - Fills in a program's commercial terms from its collection. Fails if the peer holds no key for the
+//				  collection, so terms are never read as blank. Programs whose terms were never moved to a collection
+//				  already carry them.
+//==============================================================================================================================
+func (t *AssetManagementChaincode) load_terms(stub shim.ChaincodeStubInterface, v *AnchorProgram) error {
+
+	if v.TermsHash == "" {
+		v.terms_loaded = true
+		return nil
+	}
+
+	key, err := t.collection_key(stub, v.TermsCollection)
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return errors.New("This peer holds no key for collection " + v.TermsCollection)
+	}
+
+	bytes, err := stub.GetState("private_" + v.TermsCollection + "_" + v.TermsHash)
+	if err != nil || len(bytes) == 0 {
+		return errors.New("Commercial terms missing from collection " + v.TermsCollection)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes))
+	if err != nil {
+		return errors.New("Corrupt commercial terms in collection " + v.TermsCollection)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return errors.New("Corrupt commercial terms in collection " + v.TermsCollection)
+	}
+
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(v.TermsCollection))
+	if err != nil {
+		return errors.New("Unable to decrypt commercial terms in collection " + v.TermsCollection)
+	}
+
+	hash := sha256.Sum256(plain)
+	if hex.EncodeToString(hash[:]) != v.TermsHash {
+		return errors.New("Commercial terms do not match the hash on program " + v.AnchorProgramID)
+	}
+
+	var terms CommercialTerms
+
+	err = json.Unmarshal(plain, &terms)
+	if err != nil {
+		return errors.New("Corrupt commercial terms in collection " + v.TermsCollection)
+	}
+
+	v.AnchorAgreement = terms.AnchorAgreement
+	v.VendorAgreement = terms.VendorAgreement
+	v.AnchorLimit = terms.AnchorLimit
+	v.AnchorInterest = terms.AnchorInterest
+	v.AnchorGarceInterest = terms.AnchorGarceInterest
+	v.AnchorGarceInterestperiod = terms.AnchorGarceInterestperiod
+	v.AnchorPenalInterest = terms.AnchorPenalInterest
+	v.AnchorLiquidation = terms.AnchorLiquidation
+	v.Vendorlimit = terms.Vendorlimit
+	v.terms_salt = terms.Salt
+	v.terms_loaded = true
+
+	return nil
+}
+
+//==============================================================================================================================
+// store_terms - Moves a program's commercial terms into its collection, leaving only their hash on the program. Called
+//				  by save_changes. Terms that were never loaded are left as they are.
+//==============================================================================================================================
+func (t *AssetManagementChaincode) store_terms(stub shim.ChaincodeStubInterface, v *AnchorProgram) error {
+
+	if !v.terms_loaded {
+		return nil
+	}
+
+	collection := terms_collection(*v)
+
+	key, err := t.collection_key(stub, collection)
+	if err != nil {
+		return err
+	}
+
+	if key == nil {
+		return errors.New("This peer holds no key for collection " + collection)
+	}
+
+	terms := CommercialTerms{
+		AnchorAgreement:           v.AnchorAgreement,
+		VendorAgreement:           v.VendorAgreement,
+		AnchorLimit:               v.AnchorLimit,
+		AnchorInterest:            v.AnchorInterest,
+		AnchorGarceInterest:       v.AnchorGarceInterest,
+		AnchorGarceInterestperiod: v.AnchorGarceInterestperiod,
+		AnchorPenalInterest:       v.AnchorPenalInterest,
+		AnchorLiquidation:         v.AnchorLiquidation,
+		Vendorlimit:               v.Vendorlimit,
+		Salt:                      v.terms_salt,
+	}
+
+	if terms.Salt == "" { // The salt stops the hash being matched against guessed terms
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(stub.GetTxID() + "|salt|" + v.AnchorProgramID))
+		terms.Salt = hex.EncodeToString(mac.Sum(nil)[:16])
+	}
+
+	plain, err := json.Marshal(terms)
+	if err != nil {
+		return errors.New("Error converting commercial terms")
+	}
+
+	hash := sha256.Sum256(plain)
+	termsHash := hex.EncodeToString(hash[:])
+
+	if termsHash != v.TermsHash {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return err
+		}
+
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return err
+		}
+
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(stub.GetTxID() + "|" + collection + "|" + termsHash))
+		nonce := mac.Sum(nil)[:gcm.NonceSize()]
+
+		sealed := gcm.Seal(nonce, nonce, plain, []byte(collection))
+
+		err = stub.PutState("private_"+collection+"_"+termsHash, []byte(base64.StdEncoding.EncodeToString(sealed)))
+		if err != nil {
+			return errors.New("Error storing commercial terms")
+		}
+
+		check, err := stub.GetState("collectionKeyCheck_" + collection)
+		if err == nil && len(check) == 0 {
+			err = stub.PutState("collectionKeyCheck_"+collection, []byte(collection_key_check(key, collection)))
+			if err != nil {
+				return errors.New("Error registering the key for collection " + collection)
+			}
+		}
+	}
+
+	v.TermsCollection = collection
+	v.TermsHash = termsHash
+
+	v.AnchorAgreement = ""
+	v.VendorAgreement = ""
+	v.AnchorLimit = 0
+	v.AnchorInterest = ""
+	v.AnchorGarceInterest = ""
+	v.AnchorGarceInterestperiod = ""
+	v.AnchorPenalInterest = ""
+	v.AnchorLiquidation = ""
+	v.Vendorlimit = 0
+
+	return nil
+}
+
+//==============================================================================================================================
+// require_terms - Returns an error unless the program's commercial terms can be read and written
+//==============================================================================================================================
+func (t *AssetManagementChaincode) require_terms(stub shim.ChaincodeStubInterface, v AnchorProgram) error {
+
+	key, err := t.collection_key(stub, terms_collection(v))
+	if err != nil {
+		return err
+	}
+
+	if key == nil || !v.terms_loaded {
+		return errors.New("This peer holds no key for collection " + terms_collection(v))
+	}
+
+	return nil
+}
+
+//==============================================================================================================================
+// open_terms - Opens in place new terms the client sealed with the collection key, see cmd/seal -collection. Each
+//				  value is bound to the collection and field name, so it cannot be replayed into another program.
+//==============================================================================================================================
+func (t *AssetManagementChaincode) open_terms(stub shim.ChaincodeStubInterface, v AnchorProgram, fields []PIIField) error {
+
+	collection := terms_collection(v)
+
+	key, err := t.collection_key(stub, collection)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		*f.Value, err = open_field(key, collection+"|"+f.Name, *f.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//==============================================================================================================================
+// invoice_terms - Fills in the interest an invoice carries from its program's terms when those are kept in a collection
+//==============================================================================================================================
+func invoice_terms(x *MyBoxItem, v AnchorProgram) {
+
+	if x.AnchorInterest == "" && v.TermsHash != "" && v.terms_loaded {
+		x.AnchorInterest = v.AnchorInterest
+	}
+}
+
+//==============================================================================================================================
+// Peer keys - Fabric 0.6 writes a transaction's arguments and caller metadata into every block, so keys are never sent
+//				  with a transaction. Each validating peer reads them from its own key directory, YBL_KEY_DIR or
+//				  /etc/hyperledger/ybl/keys, as <name>.key holding 32 bytes base64 encoded. Confidential values are
+//				  sealed by the client with the same key before they are sent. Personal and bank details are only
+//				  checked to be sealed when written, see require_sealed, and opened by queries, see reveal_fields, so
+//				  no transaction's writes depend on whether a peer holds the PII key; a peer without it masks them.
+//==============================================================================================================================
+//	 local_key - Returns the named key from the peer's key directory, or nil if the peer holds no such key
+//==============================================================================================================================
+func local_key(name string) ([]byte, error) {
+
+	dir := os.Getenv("YBL_KEY_DIR")
+	if dir == "" {
+		dir = KEY_DIR
+	}
+
+	encoded, err := ioutil.ReadFile(filepath.Join(dir, name+".key"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read key %s, [%v]", name, err)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("Invalid key " + name + ", expected 32 bytes base64 encoded")
+	}
+
+	return key, nil
+}
+
+//==============================================================================================================================
+// no_keys_in_metadata - Rejects a transaction whose caller metadata carries a key, which would be written to every block
+//==============================================================================================================================
+func no_keys_in_metadata(stub shim.ChaincodeStubInterface) error {
+
+	metadata, err := stub.GetCallerMetadata()
+	if err != nil || len(metadata) == 0 {
+		return nil
+	}
+
+	var m PIIKeyMetadata
+
+	if json.Unmarshal(metadata, &m) == nil && (m.PIIKey != "" || len(m.CollectionKeys) != 0) {
+		return errors.New("Keys must not be sent with a transaction, they would be written to the ledger")
+	}
+
+	return nil
+}
+
+//==============================================================================================================================
+// pii_key - Returns the peer's PII key for opening details in a query, or nil if the peer holds none. The key must match
+//				  the check value stored by register_pii_key. Until one is registered no peer is taken to hold a key,
+//				  so the rollout order is: install pii.key on the peers, register its check value, then seal new details
+//				  and run migrate_pii_fields. Details are masked, not refused, until then.
+//==============================================================================================================================
+func (t *AssetManagementChaincode) pii_key(stub shim.ChaincodeStubInterface) ([]byte, error) {
+
+	key, err := local_key("pii")
+	if err != nil || key == nil {
+		return nil, err
+	}
+
+	check, err := stub.GetState("piiKeyCheck")
+	if err != nil {
+		return nil, errors.New("Unable to get piiKeyCheck")
+	}
+	if len(check) == 0 {
+		return nil, nil
+	}
+
+	if !hmac.Equal(check, []byte(pii_key_check(key))) {
+		return nil, errors.New("The peer's PII key does not match the registered key")
+	}
+
+	return key, nil
+}
+
+//==============================================================================================================================
+// pii_key_check - Value stored to recognise the PII key without storing the key itself
+//==============================================================================================================================
+func pii_key_check(key []byte) string {
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("pii-key-check"))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+//==============================================================================================================================
+// decrypt_field - Opens a value sealed by the client, see cmd/seal, or in the older PII_PREFIX form. Values that are not
+//				  encrypted, such as records written before encryption was introduced, are returned unchanged.
+//==============================================================================================================================
+func decrypt_field(key []byte, name, value string) (string, error) {
+
+	var encoded string
+
+	if strings.HasPrefix(value, PII_SEALED) {
+		encoded = value[len(PII_SEALED):]
+	} else if strings.HasPrefix(value, PII_PREFIX) {
+		encoded = value[strings.LastIndex(value, ":")+1:]
+	} else {
+		return value, nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", errors.New("Corrupt encrypted value for " + name)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("Corrupt encrypted value for " + name)
+	}
+
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(name))
+	if err != nil {
+		return "", errors.New("Unable to decrypt " + name)
+	}
+
+	return string(plain), nil
+}
+
+//==============================================================================================================================
+// open_field - Opens a value the client sealed for field name, see cmd/seal. Empty and UNDEFINED values are returned as
+//				  they are; anything else sent in the clear is rejected so it never reaches a block. Used for commercial
+//				  terms, which the chaincode must read to check limits; personal details are never opened on write.
+//==============================================================================================================================
+func open_field(key []byte, name, value string) (string, error) {
+
+	if value == "" || value == "UNDEFINED" {
+		return value, nil
+	}
+
+	if !strings.HasPrefix(value, PII_SEALED) {
+		return "", errors.New(name + " must be sealed by the client before it is sent")
+	}
+
+	if key == nil {
+		return "", errors.New("This peer holds no key to open " + name)
+	}
+
+	return decrypt_field(key, name, value)
+}
+
+//==============================================================================================================================
+// require_sealed - Checks that each field holds a value sealed by the client, in form only. The value is not opened, so
+//				  the check comes out the same on every peer whether or not it holds the PII key.
+//==============================================================================================================================
+func require_sealed(fields []PIIField) error {
+
+	for _, f := range fields {
+
+		value := *f.Value
+		if value == "" || value == "UNDEFINED" {
+			continue
+		}
+
+		if !sealed_envelope(value) {
+			return errors.New(f.Name + " must be sealed by the client before it is sent")
+		}
+	}
+
+	return nil
+}
+
+//==============================================================================================================================
+// sealed_envelope - Whether value is PII_SEALED + base64(nonce | ciphertext) with room for the GCM nonce and tag
+//==============================================================================================================================
+func sealed_envelope(value string) bool {
+
+	if !strings.HasPrefix(value, PII_SEALED) {
+		return false
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(value[len(PII_SEALED):])
+
+	return err == nil && len(sealed) >= 12+16
+}
+
+//==============================================================================================================================
+// mask_value - Returns the masked form of a plain value, e.g. "****4821"
+//==============================================================================================================================
+func mask_value(value string) string {
+
+	if value == "" || value == "UNDEFINED" {
+		return value
+	}
+
+	runes := []rune(value)
+	if len(runes) <= 4 {
+		return "****"
+	}
+
+	return "****" + string(runes[len(runes)-4:])
+}
+
+//==============================================================================================================================
+// reveal_fields - Decrypts each field in place if the caller may see it in full. Otherwise the field is masked, the mask
+//				  being worked out from the decrypted value so no part of it is stored in the clear.
+//==============================================================================================================================
+func reveal_fields(key []byte, allowed bool, fields []PIIField) {
+
+	for _, f := range fields {
+
+		plain := *f.Value
+		if key != nil {
+			opened, err := decrypt_field(key, f.Name, *f.Value)
+			if err == nil {
+				plain = opened
+			}
+		}
+
+		if strings.HasPrefix(plain, PII_SEALED) || strings.HasPrefix(plain, PII_PREFIX) {
+			*f.Value = "****"
+		} else if allowed {
+			*f.Value = plain
+		} else {
+			*f.Value = mask_value(plain)
+		}
+	}
+}
+
+//==============================================================================================================================
+// program_pii_fields / invoice_pii_fields - The personal and bank detail fields of each record. The invoice fields are
+//				  copied from the program, so they share its names and its ciphertext.
+//==============================================================================================================================
+func program_pii_fields(v *AnchorProgram) []PIIField {
+
+	return []PIIField{
+		{"anchoraccountno", &v.AnchorAccountNo},
+		{"anchorifsc", &v.AnchorIFSCCode},
+		{"vendoremail", &v.Vendoremail},
+		{"vendorphone", &v.Vendorphone},
+		{"vendorpanno", &v.Vendorpanno},
+		{"vendorbank", &v.Vendorbank},
+		{"vendorbaddress", &v.Vendorbaddress},
+		{"vendoraccountno", &v.Vendoraccountno},
+		{"vendorifsccode", &v.Vendorifsccode},
+	}
+}
+
+func invoice_pii_fields(x *MyBoxItem) []PIIField {
+
+	return []PIIField{
+		{"anchoraccountno", &x.AnchorAccountNo},
+		{"anchorifsc", &x.AnchorIFSCCode},
+		{"vendorbank", &x.Vendorbank},
+		{"vendorifsccode", &x.Vendorifsccode},
+	}
+}
+
+//==============================================================================================================================
+// vendor_key - Identifies the vendor of a program for duplicate detection
+//==============================================================================================================================
+func vendor_key(v AnchorProgram) string {
+
+	if v.VendorPanDigest != "" {
+		return v.VendorPanDigest
+	}
+
+	return strings.ToUpper(strings.TrimSpace(v.Vendorpanno)) // Programs set up before PAN encryption
+}
+
+//==============================================================================================================================
+//	 Router Functions
+//==============================================================================================================================
+//	Invoke - Called on chaincode invoke. Takes a function name passed and calls that function. Converts some
+//		  initial arguments passed to other things for use in the called function e.g. name -> ecert
+//==============================================================================================================================
+func (t *AssetManagementChaincode) Invoke(stub shim.ChaincodeStubInterface, function string, args []string) (result []byte, err error) {
+	var pending *ActionRecord // Set once a call on a program is authorised, and logged only if the call changes its record
+	var pendingID string
+	var before []byte
+
+	defer func() {
+		if err == nil && pending != nil {
+			err = t.record_if_changed(stub, pendingID, before, *pending)
+		}
+	}()
+
+	callerRole, err := stub.ReadCertAttribute("role")
+	if err != nil {
+		fmt.Printf("Error reading attribute 'role' [%v] \n", err)
+		return nil, fmt.Errorf("Failed fetching caller role. Error was [%v]", err)
+	}
+	caller_affiliation := string(callerRole[:])
+
+	callerAccount, err := stub.ReadCertAttribute("account")
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching caller account. Error was [%v]", err)
+	}
+
+	callerOrg, _ := stub.ReadCertAttribute("org") // Optional, only needed by sameOrg policy rules
+
+	err = no_keys_in_metadata(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, registered, err := t.check_caller(stub, callerAccount, caller_affiliation)
+	if err != nil {
+		return nil, err
+	}
+	if registered {
+		callerOrg = []byte(participant.Organization)
+	}
+
+	request := PolicyRequest{Function: function, CallerAccount: string(callerAccount), CallerRole: caller_affiliation, CallerOrg: string(callerOrg), State: -1}
+	request.Admin = t.is_admin(stub, request.CallerAccount, request.CallerRole)
+
+	if function == "create_anchorprogram" ||
+		function == "propose_admin_change" ||
+		function == "approve_admin_change" ||
+		function == "register_participant" ||
+		function == "update_participant_status" ||
+		function == "freeze" ||
+		function == "request_unfreeze" ||
+		function == "approve_unfreeze" ||
+		function == "assign_kyc_account" ||
+		function == "submit_kyc_item" ||
+		function == "review_kyc_item" ||
+		function == "register_pii_key" ||
+		function == "migrate_pii_fields" ||
+		function == "update_access_policy" ||
+		function == "update_sod_rules" ||
+		function == "update_channel_catalogue" ||
+		function == "update_gl_accounts" ||
+		function == "create_delegation" ||
+		function == "revoke_delegation" { // Functions that do not act on an existing program
+		err = t.authorize(stub, request)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if function == "create_anchorprogram" {
+		return t.create_anchorprogram(stub, callerAccount, caller_affiliation, args[0])
+	} else if function == "propose_admin_change" {
+		return t.propose_admin_change(stub, request, args[0], args[1], args[2], args[3], optional_arg(args, 4), optional_arg(args, 5))
+	} else if function == "approve_admin_change" {
+		proposal, err := t.retrieve_admin_proposal(stub, args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return t.approve_admin_change(stub, request, proposal)
+	} else if function == "register_pii_key" {
+		return t.register_pii_key(stub, optional_arg(args, 0))
+	} else if function == "migrate_pii_fields" {
+		return t.migrate_pii_fields(stub, optional_arg(args, 0))
+	} else if function == "update_access_policy" {
+		return t.update_access_policy(stub, callerAccount, args[0])
+	} else if function == "update_sod_rules" {
+		return t.update_sod_rules(stub, callerAccount, args[0])
+	} else if function == "update_channel_catalogue" {
+		return t.update_channel_catalogue(stub, callerAccount, args[0])
+	} else if function == "update_gl_accounts" {
+		return t.update_gl_accounts(stub, callerAccount, args[0])
+	} else if function == "register_participant" {
+		return t.register_participant(stub, callerAccount, args[0], args[1], args[2], args[3], optional_arg(args, 4))
+	} else if function == "update_participant_status" {
+		return t.update_participant_status(stub, callerAccount, args[0], args[1])
+	} else if function == "freeze" {
+		return t.freeze(stub, request, args[0], args[1], args[2], optional_arg(args, 3))
+	} else if function == "request_unfreeze" {
+		return t.request_unfreeze(stub, request, args[0], args[1], optional_arg(args, 2))
+	} else if function == "approve_unfreeze" {
+		return t.approve_unfreeze(stub, request, args[0], args[1])
+	} else if function == "assign_kyc_account" {
+		return t.assign_kyc_account(stub, request, args[0], args[1])
+	} else if function == "submit_kyc_item" {
+		return t.submit_kyc_item(stub, request, args[0], args[1], args[2], optional_arg(args, 3))
+	} else if function == "review_kyc_item" {
+		return t.review_kyc_item(stub, request, args[0], args[1], args[2], optional_arg(args, 3), optional_arg(args, 4))
+	} else if function == "create_delegation" {
+		return t.create_delegation(stub, request, args[0], args[1], args[2], args[3], args[4], args[5], optional_arg(args, 6))
+	} else if function == "revoke_delegation" {
+		return t.revoke_delegation(stub, request, args[0])
+	} else { // If the function is not a create then there must be a order so we need to retrieve the order.
+
+		argPos := 0
+
+		v, err := t.retrieve_anchorprogram(stub, args[argPos])
+		if err != nil {
+			fmt.Printf("INVOKE: Error retrieving Anchor Program: %s", err)
+			return nil, errors.New("Error retrieving Anchor Program")
+		}
+
+		err = t.load_terms(stub, &v)
+		if err != nil {
+			return nil, err
+		}
+
+		frozen, err := t.program_freeze(stub, v)
+		if err != nil {
+			return nil, err
+		}
+		if frozen != nil {
+			fmt.Printf("INVOKE: %s %s is frozen (%s)", frozen.Scope, frozen.Target, frozen.ReasonCode)
+			return nil, errors.New("The " + frozen.Scope + " " + frozen.Target + " is frozen: " + frozen.ReasonCode)
+		}
+
+		request.State = v.Status
+		request.Owner = v.Owner
+		request.Amount = v.AnchorPOAmount
+
+		transfer := strings.Contains(function, "update") == false && function != "settlement_anchorprogram"
+		recordID := v.AnchorProgramID
+
+		var invoice MyBoxItem
+
+		if strings.Contains(function, "invoice") && function != "update_vendor_create_invoice" { // Invoice functions are checked against the invoice
+			moid := optional_arg(args, 1)
+			if transfer {
+				moid = optional_arg(args, 2)
+			}
+
+			x, err := t.retrieve_invoice(stub, moid)
+			if err != nil || x.MOID != moid || x.POID != v.AnchorProgramID { // Never fall back to the program's state and owner
+				fmt.Printf("INVOKE: Error retrieving Invoice %s: %v", moid, err)
+				return nil, errors.New("Error retrieving invoice " + moid + " of program " + v.AnchorProgramID)
+			}
+
+			invoice = x
+			recordID = moid
+			request.State = x.MOStatus
+			request.Owner = x.MOOwner
+			request.Amount = x.MOAmount
+			if x.ApprovedInvoiceAmount > 0 {
+				request.Amount = x.ApprovedInvoiceAmount
+			}
+		}
+
+		action := ActionRecord{Function: function, Account: string(callerAccount), Role: caller_affiliation}
+
+		if request.Owner != "" && request.Owner != string(callerAccount) { // Someone may be covering for the owner
+			d, acting, err := t.active_delegation(stub, request.Owner, string(callerAccount), v.AnchorProgramID)
+			if err != nil {
+				return nil, err
+			}
+
+			if acting {
+				action.Account = d.Principal
+				action.Role = d.Role
+				action.Delegate = string(callerAccount)
+				action.DelegationID = d.DelegationID
+
+				request.Delegate = string(callerAccount)
+
+				callerAccount = []byte(d.Principal)
+				caller_affiliation = d.Role
+				request.CallerAccount = d.Principal
+				request.CallerRole = d.Role
+				request.Admin = t.is_admin(stub, d.Principal, d.Role)
+			}
+		}
+
+		if !transfer {
+			err = t.authorize(stub, request)
+			if err != nil {
+				return nil, err
+			}
+
+			err = t.check_segregation(stub, request, v, invoice, "")
+			if err != nil {
+				return nil, err
+			}
+
+			before, _ = stub.GetState(recordID)
+			pending, pendingID = &action, recordID
+		}
+
+		if transfer { // If the function is not an update or a scrappage it must be a transfer so we need to look up the recipient.
+			receiverAccount := args[1]
+
+			request, err = t.resolve_recipient(stub, request, receiverAccount)
+			if err != nil {
+				return nil, err
+			}
+
+			rec_affiliation := request.RecipientRole
+
+			err = t.check_segregation(stub, request, v, invoice, receiverAccount)
+			if err != nil {
+				return nil, err
+			}
+
+			before, _ = stub.GetState(recordID)
+			pending, pendingID = &action, recordID
+
+			if function == "admin_to_anchor" {
+				return t.admin_to_anchor(stub, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
+			} else if function == "anchor_to_admin_rev" {
+				return t.anchor_to_admin_rev(stub, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation, args[2])
+			} else if function == "anchor_to_vendor" {
+				return t.anchor_to_vendor(stub, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
+			} else if function == "vendor_to_anchor_rev" {
+				return t.vendor_to_anchor_rev(stub, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation, args[2])
+			} else if function == "transfer_vendor_to_anchor_invoice" {
+				x, err := t.retrieve_invoice(stub, args[2])
+				if err != nil {
+					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+					return nil, errors.New("Error retrieving INVOICE")
+				}
+				return t.transfer_vendor_to_anchor_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
+			} else if function == "transfer_rev_anchor_to_vendor_invoice" {
+				x, err := t.retrieve_invoice(stub, args[2])
+				if err != nil {
+					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+					return nil, errors.New("Error retrieving INVOICE")
+				}
+				return t.transfer_rev_anchor_to_vendor_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation, args[3])
+			} else if function == "transfer_anchor_to_vendor_invoice" {
+				x, err := t.retrieve_invoice(stub, args[2])
+				if err != nil {
+					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+					return nil, errors.New("Error retrieving INVOICE")
+				}
+				return t.transfer_anchor_to_vendor_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
+			} else if function == "transfer_rev_vendor_to_anchor_invoice" {
+				x, err := t.retrieve_invoice(stub, args[2])
+				if err != nil {
+					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+					return nil, errors.New("Error retrieving INVOICE")
+				}
+				return t.transfer_rev_vendor_to_anchor_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation, args[3])
+			} else if function == "transfer_vendor_to_admin_invoice" {
+				x, err := t.retrieve_invoice(stub, args[2])
+				if err != nil {
+					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+					return nil, errors.New("Error retrieving INVOICE")
+				}
+				return t.transfer_vendor_to_admin_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
+			} else if function == "transfer_rev_admin_to_vendor_invoice" {
+				x, err := t.retrieve_invoice(stub, args[2])
+				if err != nil {
+					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+					return nil, errors.New("Error retrieving INVOICE")
+				}
+				return t.transfer_rev_admin_to_vendor_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation, args[3])
+			} else if function == "transfer_admin_to_payment_invoice" {
+				x, err := t.retrieve_invoice(stub, args[2])
+				if err != nil {
+					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+					return nil, errors.New("Error retrieving INVOICE")
+				}
+				return t.transfer_admin_to_payment_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
+			} else if function == "transfer_rev_payment_to_admin_invoice" {
+				x, err := t.retrieve_invoice(stub, args[2])
+				if err != nil {
+					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+					return nil, errors.New("Error retrieving INVOICE")
+				}
+				return t.transfer_rev_payment_to_admin_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation, args[3])
+			} else if function == "transfer_payment_maker_to_payment_checker_invoice" {
+				x, err := t.retrieve_invoice(stub, args[2])
+				if err != nil {
+					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+					return nil, errors.New("Error retrieving INVOICE")
+				}
+				return t.transfer_payment_maker_to_payment_checker_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
+			} else if function == "transfer_rev_payment_checker_to_payment_maker_invoice" {
+				x, err := t.retrieve_invoice(stub, args[2])
+				if err != nil {
+					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+					return nil, errors.New("Error retrieving INVOICE")
+				}
+				return t.transfer_rev_payment_checker_to_payment_maker_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation, args[3])
+			} else if function == "transfer_payment_checker_to_payment_maker_invoice" {
+				x, err := t.retrieve_invoice(stub, args[2])
+				if err != nil {
+					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+					return nil, errors.New("Error retrieving INVOICE")
+				}
+				return t.transfer_payment_checker_to_payment_maker_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
+			} else if function == "transfer_payment_maker_to_payment_checker_batch" {
+				return t.transfer_payment_maker_to_payment_checker_batch(stub, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation, args[2], args[3])
+			} /*else if function == "transfer_payment_checker_to_anchor_invoice" {
+				x, err := t.retrieve_invoice(stub, args[2])
+				if err != nil {
+					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+					return nil, errors.New("Error retrieving INVOICE")
+				}
+				return t.transfer_payment_checker_to_anchor_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
+			} else if function == "transfer_anchor_to_payment_checker_invoice" {
+				x, err := t.retrieve_invoice(stub, args[2])
+				if err != nil {
+					fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+					return nil, errors.New("Error retrieving INVOICE")
+				}
+				return t.transfer_anchor_to_payment_checker_invoice(stub, x, v, []byte(callerAccount), string(caller_affiliation), receiverAccount, rec_affiliation)
+			}
+			*/
+
+			//transfer payment checker to anchor invoice
+			//transfer anchor to payment checker invoice
+
+		} else if function == "update_anchor_details" {
+			return t.update_anchor_details(stub, v, callerAccount, caller_affiliation, args[1], args[2], args[3], args[4], args[5], args[6], args[7], args[8], args[9], args[10], args[11], args[12])
+		} else if function == "update_vendor_details" {
+			return t.update_vendor_details(stub, v, callerAccount, caller_affiliation, args[1], args[2], args[3], args[4], args[5], args[6], args[7], args[8], args[9], args[10], args[11], args[12], args[13], args[14], optional_arg(args, 15), optional_arg(args, 16))
+		} else if function == "update_anchor_purchase_order" {
+			return t.update_anchor_purchase_order(stub, v, callerAccount, caller_affiliation, args[1], args[2], args[3], optional_arg(args, 4))
+		} else if function == "update_anchor_goods_receipt" {
+			return t.update_anchor_goods_receipt(stub, v, callerAccount, caller_affiliation, args[1], args[2], args[3], args[4])
+		} else if function == "update_admin_match_tolerances" {
+			return t.update_admin_match_tolerances(stub, v, callerAccount, caller_affiliation, args[1], args[2])
+		} else if function == "update_admin_approval_bands" {
+			return t.update_admin_approval_bands(stub, v, callerAccount, caller_affiliation, args[1])
+		} else if function == "update_admin_program_type" {
+			return t.update_admin_program_type(stub, v, callerAccount, caller_affiliation, args[1])
+		} else if function == "update_admin_max_payment_failures" {
+			return t.update_admin_max_payment_failures(stub, v, callerAccount, caller_affiliation, args[1])
+		} else if function == "update_admin_finance_base" {
+			return t.update_admin_finance_base(stub, v, callerAccount, caller_affiliation, args[1])
+		} else if function == "update_admin_fee_schedule" {
+			return t.update_admin_fee_schedule(stub, v, callerAccount, caller_affiliation, args[1])
+		} else if function == "update_admin_program_fees" {
+			return t.update_admin_program_fees(stub, v, callerAccount, caller_affiliation, args[1])
+		} else if function == "update_checker_payment_batch" {
+			return t.update_checker_payment_batch(stub, request, v, args[1], args[2], optional_arg(args, 3))
+		} else if function == "settlement_anchorprogram" {
+			return t.settlement_anchorprogram(stub, v, callerAccount, caller_affiliation)
+		} else if function == "update_vendor_po_acknowledgement" {
+			return t.update_vendor_po_acknowledgement(stub, v, callerAccount, caller_affiliation)
+		} else if function == "update_vendor_create_invoice" {
+			return t.update_vendor_create_invoice(stub, v, callerAccount, caller_affiliation, args[1])
+		} else if function == "update_vendor_invoice_details" {
+			x, err := t.retrieve_invoice(stub, args[1])
+			if err != nil {
+				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+				return nil, errors.New("Error retrieving INVOICE")
+			}
+			return t.update_vendor_invoice_details(stub, x, v, callerAccount, caller_affiliation, args[2], args[3], args[4], optional_arg(args, 5), optional_arg(args, 6), optional_arg(args, 7))
+		} else if function == "update_anchor_invoice_authorized_amount" {
+			x, err := t.retrieve_invoice(stub, args[1])
+			if err != nil {
+				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+				return nil, errors.New("Error retrieving INVOICE")
+			}
+			return t.update_anchor_invoice_authorized_amount(stub, x, v, callerAccount, caller_affiliation, args[2], optional_arg(args, 3))
+		} else if function == "update_maker_invoice_payment" {
+			x, err := t.retrieve_invoice(stub, args[1])
+			if err != nil {
+				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+				return nil, errors.New("Error retrieving INVOICE")
+			}
+			return t.update_maker_invoice_payment(stub, x, v, callerAccount, caller_affiliation, args[2], args[3])
+		} else if function == "update_checker_invoice_approval" {
+			x, err := t.retrieve_invoice(stub, args[1])
+			if err != nil {
+				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+				return nil, errors.New("Error retrieving INVOICE")
+			}
+			return t.update_checker_invoice_approval(stub, x, v, callerAccount, caller_affiliation)
+		} else if function == "update_rev_checker_invoice_approval" {
+			x, err := t.retrieve_invoice(stub, args[1])
+			if err != nil {
+				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+				return nil, errors.New("Error retrieving INVOICE")
+			}
+			return t.update_rev_checker_invoice_approval(stub, x, v, callerAccount, caller_affiliation, args[2])
+		} else if function == "update_checker_invoice_payment" {
+			x, err := t.retrieve_invoice(stub, args[1])
+			if err != nil {
+				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+				return nil, errors.New("Error retrieving INVOICE")
+			}
+			return t.update_checker_invoice_payment(stub, x, v, callerAccount, caller_affiliation, args[2], args[3], optional_arg(args, 4))
+		} else if function == "update_rev_checker_invoice_payment" {
+			x, err := t.retrieve_invoice(stub, args[1])
+			if err != nil {
+				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+				return nil, errors.New("Error retrieving INVOICE")
+			}
+			return t.update_rev_checker_invoice_payment(stub, x, v, callerAccount, caller_affiliation, args[2])
+		} else if function == "update_checker_invoice_settlement" {
+			x, err := t.retrieve_invoice(stub, args[1])
+			if err != nil {
+				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+				return nil, errors.New("Error retrieving INVOICE")
+			}
+			return t.update_checker_invoice_settlement(stub, x, v, callerAccount, caller_affiliation, args[2])
+		} else if function == "update_rev_checker_invoice_settlement" {
+			x, err := t.retrieve_invoice(stub, args[1])
+			if err != nil {
+				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+				return nil, errors.New("Error retrieving INVOICE")
+			}
+			return t.update_rev_checker_invoice_settlement(stub, x, v, callerAccount, caller_affiliation, args[2])
+		} else if function == "update_admin_invoice_payment_failure" {
+			x, err := t.retrieve_invoice(stub, args[1])
+			if err != nil {
+				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+				return nil, errors.New("Error retrieving INVOICE")
+			}
+			return t.update_admin_invoice_payment_failure(stub, x, v, callerAccount, caller_affiliation, args[2], args[3])
+		} else if function == "update_admin_invoice_write_off" {
+			x, err := t.retrieve_invoice(stub, args[1])
+			if err != nil {
+				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+				return nil, errors.New("Error retrieving INVOICE")
+			}
+			return t.update_admin_invoice_write_off(stub, x, v, callerAccount, caller_affiliation, args[2], optional_arg(args, 3))
+		} else if function == "update_admin_invoice_write_off_approval" {
+			x, err := t.retrieve_invoice(stub, args[1])
+			if err != nil {
+				fmt.Printf("INVOKE: A Error retrieving Invoice: %s", err)
+				return nil, errors.New("Error retrieving INVOICE")
+			}
+			return t.update_admin_invoice_write_off_approval(stub, x, v, callerAccount, caller_affiliation)
+		}
+
+		return nil, errors.New("Function of that name doesn't exist.")
+	}
+
+}
+
+//=================================================================================================================================
+//	 Access Policy Functions
+//=================================================================================================================================
+//	 default_access_policy - The policy seeded at deploy. Matches the role checks the functions carried before roles
+//							 moved into the ledger; the functions still check state and ownership themselves.
+//=================================================================================================================================
+func default_access_policy() AccessPolicy {
+
+	var policy AccessPolicy
+
+	rule := func(function string, roles []string, recipients []string) {
+		policy.Rules = append(policy.Rules, PolicyRule{Function: function, Roles: roles, RecipientRoles: recipients})
+	}
+
+	admin := []string{ROLE_ADMIN}
+	anchor := []string{ROLE_ANCHOR}
+	vendor := []string{ROLE_VENDOR}
+	maker := []string{ROLE_PAYMENT_MAKER}
+	checker := []string{ROLE_PAYMENT_CHECKER}
+
+	rule("create_anchorprogram", admin, nil)
+	rule("propose_admin_change", admin, nil)
+	rule("approve_admin_change", admin, nil)
+	rule("register_pii_key", admin, nil)
+	rule("migrate_pii_fields", admin, nil)
+	rule("update_access_policy", admin, nil)
+	rule("create_delegation", []string{ROLE_ADMIN, ROLE_ANCHOR, ROLE_VENDOR, ROLE_PAYMENT_MAKER, ROLE_PAYMENT_CHECKER}, nil)
+	rule("revoke_delegation", []string{ROLE_ADMIN, ROLE_ANCHOR, ROLE_VENDOR, ROLE_PAYMENT_MAKER, ROLE_PAYMENT_CHECKER}, nil)
+	rule("update_sod_rules", admin, nil)
+	rule("update_channel_catalogue", admin, nil)
+	rule("view_channel_catalogue", []string{ROLE_ADMIN, ROLE_PAYMENT_MAKER, ROLE_PAYMENT_CHECKER}, nil)
+	rule("update_gl_accounts", admin, nil)
+	rule("view_journals", []string{ROLE_ADMIN, ROLE_PAYMENT_CHECKER}, nil)
+	rule("register_participant", admin, nil)
+	rule("update_participant_status", admin, nil)
+	rule("freeze", admin, nil)
+	rule("request_unfreeze", admin, nil)
+	rule("approve_unfreeze", admin, nil)
+	rule("view_freezes", admin, nil)
+	rule("assign_kyc_account", admin, nil)
+	rule("submit_kyc_item", []string{ROLE_ADMIN, ROLE_VENDOR}, nil)
+	rule("review_kyc_item", []string{ROLE_KYC_OFFICER}, nil)
+	rule("view_kyc_records", []string{ROLE_ADMIN, ROLE_KYC_OFFICER}, nil)
+	rule("create_delegation_on_behalf", admin, nil)
+	rule("revoke_delegation_on_behalf", admin, nil)
+
+	rule("admin_to_anchor", admin, anchor)
+	rule("anchor_to_admin_rev", anchor, admin)
+	rule("anchor_to_vendor", anchor, vendor)
+	rule("vendor_to_anchor_rev", vendor, anchor)
+	rule("transfer_vendor_to_anchor_invoice", vendor, anchor)
+	rule("transfer_rev_anchor_to_vendor_invoice", anchor, vendor)
+	rule("transfer_anchor_to_vendor_invoice", anchor, vendor)
+	rule("transfer_rev_vendor_to_anchor_invoice", vendor, anchor)
+	rule("transfer_vendor_to_admin_invoice", vendor, admin)
+	rule("transfer_rev_admin_to_vendor_invoice", admin, vendor)
+	rule("transfer_admin_to_payment_invoice", admin, maker)
+	rule("transfer_rev_payment_to_admin_invoice", maker, admin)
+	rule("transfer_payment_maker_to_payment_checker_invoice", maker, checker)
+	rule("transfer_rev_payment_checker_to_payment_maker_invoice", checker, maker)
+	rule("transfer_payment_checker_to_payment_maker_invoice", checker, maker)
+	rule("transfer_payment_maker_to_payment_checker_batch", maker, checker)
+
+	rule("update_anchor_details", admin, nil)
+	rule("update_vendor_details", admin, nil)
+	rule("update_admin_match_tolerances", admin, nil)
+	rule("update_admin_approval_bands", admin, nil)
+	rule("update_admin_program_type", admin, nil)
+	rule("update_admin_max_payment_failures", admin, nil)
+	rule("update_admin_fee_schedule", admin, nil)
+	rule("update_admin_finance_base", admin, nil)
+	rule("update_admin_program_fees", admin, nil)
+	rule("update_anchor_purchase_order", anchor, nil)
+	rule("update_anchor_goods_receipt", anchor, nil)
+	rule("update_vendor_po_acknowledgement", vendor, nil)
+	rule("update_vendor_create_invoice", vendor, nil)
+	rule("update_vendor_invoice_details", vendor, nil)
+	rule("update_anchor_invoice_authorized_amount", anchor, nil)
+	rule("update_rev_anchor_invoice_authorized_amount", anchor, nil)
+	rule("update_maker_invoice_payment", maker, nil)
+	rule("update_checker_invoice_approval", checker, nil)
+	rule("update_checker_payment_batch", checker, nil)
+	rule("update_rev_checker_invoice_approval", checker, nil)
+	rule("update_checker_invoice_payment", checker, nil)
+	rule("update_rev_checker_invoice_payment", checker, nil)
+	rule("update_checker_invoice_settlement", checker, nil)
+	rule("update_rev_checker_invoice_settlement", checker, nil)
+	rule("settlement_anchorprogram", checker, nil)
+	rule("update_admin_invoice_payment_failure", admin, nil)
+	rule("update_admin_invoice_write_off", admin, nil)
+	rule("update_admin_invoice_write_off_approval", admin, nil)
+
+	rule("view_all_records", admin, nil)
+
+	policy.AllowUnregistered = true // Until an admin has filled the registry and turns this off, see check_caller
+
+	policy.Redactions = map[string][]string{
+		ROLE_VENDOR: {"anchorlimit", "anchorinterest", "anchorgraceinterest", "anchorgraceinterestperiod", "anchorpenalinterest", "anchorliquidation"},
+		ROLE_ANCHOR: {"vendoraccountno", "vendorbank", "vendorbaddress", "vendorifsccode", "vendorBank", "venDorbank"},
+	}
+
+	return policy
+}
+
+//=================================================================================================================================
+//	 retrieve_access_policy - Gets the current access policy, or the given version of it
+//=================================================================================================================================
+func (t *AssetManagementChaincode) retrieve_access_policy(stub shim.ChaincodeStubInterface, version string) (AccessPolicy, error) {
+
+	var policy AccessPolicy
+
+	key := "accessPolicy"
+	if version != "" {
+		key = "accessPolicy_v" + version
+	}
+
+	bytes, err := stub.GetState(key)
+	if err != nil || len(bytes) == 0 {
+		return policy, errors.New("No access policy found at " + key)
+	}
+
+	err = json.Unmarshal(bytes, &policy)
+	if err != nil {
+		return policy, errors.New("Corrupt access policy record")
+	}
+
+	return policy, nil
+}
+
+//=================================================================================================================================
+//	 save_access_policy - Writes the policy as the current version and into its version history
+//=================================================================================================================================
+func (t *AssetManagementChaincode) save_access_policy(stub shim.ChaincodeStubInterface, policy AccessPolicy) (bool, error) {
+
+	bytes, err := json.Marshal(policy)
+	if err != nil {
+		return false, errors.New("Error converting access policy")
+	}
+
+	err = stub.PutState("accessPolicy_v"+strconv.Itoa(policy.Version), bytes)
+	if err != nil {
+		return false, errors.New("Error storing access policy")
+	}
+
+	err = stub.PutState("accessPolicy", bytes)
+	if err != nil {
+		return false, errors.New("Error storing access policy")
+	}
+
+	return true, nil
+}
+
+//=================================================================================================================================
+//	 authorize - Checks a call against the current access policy
+//=================================================================================================================================
+func (t *AssetManagementChaincode) authorize(stub shim.ChaincodeStubInterface, request PolicyRequest) error {
+
+	policy, err := t.retrieve_access_policy(stub, "")
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range policy.Rules {
+		if rule.Function == request.Function && rule_allows(rule, request) {
+			return nil
+		}
+	}
+
+	fmt.Printf("AUTHORIZE: Policy v%d denies role %v calling %v in state %d\n", policy.Version, request.CallerRole, request.Function, request.State)
+	return fmt.Errorf("Permission Denied. Access policy v%d has no rule allowing role [%v] to call %v", policy.Version, request.CallerRole, request.Function)
+}
+
+//=================================================================================================================================
+//	 contains - Whether list holds value
+//=================================================================================================================================
+func contains(list []string, value string) bool {
+
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+//=================================================================================================================================
+//	 rule_allows - Checks a call against one rule
+//=================================================================================================================================
+func rule_allows(rule PolicyRule, request PolicyRequest) bool {
+
+	if request.CallerRole == ROLE_ADMIN || !contains(rule.Roles, request.CallerRole) {
+		if !(request.Admin && contains(rule.Roles, ROLE_ADMIN)) {
+			return false
+		}
+	}
+
+	if len(rule.RecipientRoles) > 0 && !contains(rule.RecipientRoles, request.RecipientRole) {
+		return false
+	}
+
+	if len(rule.States) > 0 {
+		in_state := false
+		for _, state := range rule.States {
+			if state == request.State {
+				in_state = true
+			}
+		}
+		if !in_state {
+			return false
+		}
+	}
+
+	if rule.Owner && request.Owner != request.CallerAccount {
+		return false
+	}
+
+	if rule.SameOrg && (request.CallerOrg == "" || request.CallerOrg != request.RecipientOrg) {
+		return false
+	}
+
+	if rule.AmountMax > 0 && request.Amount > rule.AmountMax {
+		return false
+	}
+
+	return true
+}
+
+//=================================================================================================================================
+//	 update_access_policy - Replaces the rules of the access policy with those in the JSON passed, as a new version. A
+//							policy that leaves out allowUnregistered keeps the current setting.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_access_policy(stub shim.ChaincodeStubInterface, callerAccount []byte, raw string) ([]byte, error) {
+
+	current, err := t.retrieve_access_policy(stub, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var update AccessPolicy
+	update.AllowUnregistered = current.AllowUnregistered
+
+	err = json.Unmarshal([]byte(raw), &update)
+	if err != nil {
+		return nil, errors.New("Invalid access policy, expected JSON with a rules list")
+	}
+
+	can_update := false
+
+	for _, rule := range update.Rules {
+		if rule.Function == "" || len(rule.Roles) == 0 {
+			return nil, errors.New("Every access policy rule needs a function and at least one role")
+		}
+		if rule.Function == "update_access_policy" {
+			can_update = true
+		}
+	}
+
+	if !can_update { // Otherwise nobody could ever change the policy again
+		return nil, errors.New("The access policy must keep a rule for update_access_policy")
+	}
+
+	update.Version = current.Version + 1
+	update.UpdatedBy = string(callerAccount)
+	update.UpdatedAt, err = tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = t.save_access_policy(stub, update)
+	if err != nil {
+		fmt.Printf("UPDATE_ACCESS_POLICY: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 Administrator Functions
+//=================================================================================================================================
+//	 retrieve_admin_set - Gets the managed set of administrators. Ledgers deployed before the set existed fall back to
+//						  the assignerRole recorded by Init.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) retrieve_admin_set(stub shim.ChaincodeStubInterface) (AdminSet, error) {
+
+	var admins AdminSet
+
+	bytes, err := stub.GetState("adminSet")
+	if err != nil {
+		return admins, errors.New("Unable to get adminSet")
+	}
+
+	if len(bytes) == 0 {
+		assignerRole, _ := stub.GetState("assignerRole")
+		if len(assignerRole) == 0 {
+			assignerRole = []byte(ROLE_ADMIN)
+		}
+		admins.Principals = []AdminPrincipal{{Kind: ADMIN_BY_ROLE, Value: string(assignerRole)}}
+		admins.Quorum = 1
+		return admins, nil
+	}
+
+	err = json.Unmarshal(bytes, &admins)
+	if err != nil {
+		return admins, errors.New("Corrupt adminSet record")
+	}
+
+	return admins, nil
+}
+
+func (t *AssetManagementChaincode) save_admin_set(stub shim.ChaincodeStubInterface, admins AdminSet) (bool, error) {
+
+	bytes, err := json.Marshal(admins)
+	if err != nil {
+		return false, errors.New("Error converting AdminSet record")
+	}
+
+	err = stub.PutState("adminSet", bytes)
+	if err != nil {
+		return false, errors.New("Error storing AdminSet record")
+	}
+
+	return true, nil
+}
+
+//=================================================================================================================================
+//	 is_admin - Whether the caller is in the managed admin set, by their role or their account
+//=================================================================================================================================
+func (t *AssetManagementChaincode) is_admin(stub shim.ChaincodeStubInterface, account, role string) bool {
+
+	admins, err := t.retrieve_admin_set(stub)
+	if err != nil {
+		return false
+	}
+
+	for _, p := range admins.Principals {
+		if (p.Kind == ADMIN_BY_ROLE && p.Value == role) || (p.Kind == ADMIN_BY_ACCOUNT && p.Value == account) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//=================================================================================================================================
+//	 propose_admin_change - Proposes adding, removing or rotating an admin principal, or changing the quorum. The proposer's
+//							approval is counted, so with a quorum of one the change applies at once.
+//							  add / remove:	kind ("role" or "account") and value
+//							  rotate:		kind and value to replace with newKind and newValue
+//							  quorum:		value is the number of admins that must approve later changes
+//=================================================================================================================================
+func (t *AssetManagementChaincode) propose_admin_change(stub shim.ChaincodeStubInterface, request PolicyRequest, id, action, kind, value, newKind, newValue string) ([]byte, error) {
+
+	if id == "" {
+		return nil, errors.New("Invalid proposal ID provided")
+	}
+
+	bytes, err := stub.GetState("adminProposal_" + id)
+	if err != nil || len(bytes) != 0 {
+		return nil, errors.New("Admin change proposal " + id + " already exists")
+	}
+
+	proposal := AdminProposal{ProposalID: id, Action: action, Kind: kind, Value: value, NewKind: newKind, NewValue: newValue, Status: ADMIN_CHANGE_PENDING}
+
+	proposal.ProposedBy = request.CallerAccount
+	proposal.ProposedAt, err = tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	admins, err := t.retrieve_admin_set(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = apply_admin_change(admins, proposal) // Reject proposals that could never apply
+	if err != nil {
+		return nil, err
+	}
+
+	return t.approve_admin_change(stub, request, proposal)
+}
+
+//=================================================================================================================================
+//	 approve_admin_change - Adds a distinct admin's approval to a pending proposal, and applies it once the quorum in force
+//							is reached. Every proposal, approval and applied change is emitted as an admin_change event.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) approve_admin_change(stub shim.ChaincodeStubInterface, request PolicyRequest, proposal AdminProposal) ([]byte, error) {
+
+	if proposal.Status != ADMIN_CHANGE_PENDING {
+		return nil, errors.New("Admin change proposal " + proposal.ProposalID + " is " + proposal.Status)
+	}
+
+	for _, approval := range proposal.Approvals {
+		if approval.Account == request.CallerAccount {
+			return nil, errors.New("Admin change proposal already approved by " + request.CallerAccount)
+		}
+	}
+
+	now, err := tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	proposal.Approvals = append(proposal.Approvals, AdminApproval{Account: request.CallerAccount, ApprovedAt: now})
+
+	admins, err := t.retrieve_admin_set(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	event := "admin_change_approved"
+	if len(proposal.Approvals) == 1 {
+		event = "admin_change_proposed"
+	}
+
+	if len(proposal.Approvals) >= admins.Quorum {
+		admins, err = apply_admin_change(admins, proposal)
+		if err != nil {
+			return nil, err
+		}
+
+		admins.Version++
+		admins.UpdatedAt = now
+		admins.UpdatedBy = proposal.ProposalID
+
+		_, err = t.save_admin_set(stub, admins)
+		if err != nil {
+			return nil, err
+		}
+
+		proposal.Status = ADMIN_CHANGE_APPLIED
+		proposal.AppliedAt = now
+		event = "admin_change_applied"
+	}
+
+	bytes, err := json.Marshal(proposal)
+	if err != nil {
+		return nil, errors.New("Error converting AdminProposal record")
+	}
+
+	err = stub.PutState("adminProposal_"+proposal.ProposalID, bytes)
+	if err != nil {
+		return nil, errors.New("Error storing AdminProposal record")
+	}
+
+	payload, err := json.Marshal(AdminChangeEvent{Event: event, Proposal: proposal, By: request.CallerAccount, AdminSetVersion: admins.Version})
+	if err != nil {
+		return nil, errors.New("Error converting admin change event")
+	}
+
+	err = stub.SetEvent("admin_change", payload)
+	if err != nil {
+		return nil, errors.New("Error setting admin change event")
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 retrieve_admin_proposal - Gets an admin change proposal from "adminProposal_<id>"
+//=================================================================================================================================
+func (t *AssetManagementChaincode) retrieve_admin_proposal(stub shim.ChaincodeStubInterface, id string) (AdminProposal, error) {
+
+	var proposal AdminProposal
+
+	bytes, err := stub.GetState("adminProposal_" + id)
+	if err != nil || len(bytes) == 0 {
+		return proposal, errors.New("No admin change proposal " + id)
+	}
+
+	err = json.Unmarshal(bytes, &proposal)
+	if err != nil {
+		return proposal, errors.New("Corrupt admin change proposal " + id)
+	}
+
+	return proposal, nil
+}
+
+//=================================================================================================================================
+//	 apply_admin_change - Returns the admin set with the proposed change made. The set must keep at least as many
+//						  principals as the quorum, so changes can always still be approved.
+//=================================================================================================================================
+func apply_admin_change(admins AdminSet, proposal AdminProposal) (AdminSet, error) {
+
+	valid_kind := func(kind, value string) bool {
+		return (kind == ADMIN_BY_ROLE || kind == ADMIN_BY_ACCOUNT) && value != ""
+	}
+
+	index := func(kind, value string) int {
+		for i, p := range admins.Principals {
+			if p.Kind == kind && p.Value == value {
+				return i
+			}
+		}
+		return -1
+	}
+
+	principals := []AdminPrincipal{}
+
+	switch proposal.Action {
+	case "add":
+		if !valid_kind(proposal.Kind, proposal.Value) {
+			return admins, errors.New("Invalid admin principal, expected a kind of role or account and a value")
+		}
+		if index(proposal.Kind, proposal.Value) >= 0 {
+			return admins, errors.New("Admin principal " + proposal.Value + " already in the admin set")
+		}
+		principals = append(principals, admins.Principals...)
+		principals = append(principals, AdminPrincipal{Kind: proposal.Kind, Value: proposal.Value})
+
+	case "remove", "rotate":
+		i := index(proposal.Kind, proposal.Value)
+		if i < 0 {
+			return admins, errors.New("Admin principal " + proposal.Value + " not in the admin set")
+		}
+		principals = append(principals, admins.Principals[:i]...)
+		principals = append(principals, admins.Principals[i+1:]...)
+
+		if proposal.Action == "rotate" {
+			if !valid_kind(proposal.NewKind, proposal.NewValue) {
+				return admins, errors.New("Invalid replacement admin principal, expected a kind of role or account and a value")
+			}
+			if index(proposal.NewKind, proposal.NewValue) >= 0 {
+				return admins, errors.New("Admin principal " + proposal.NewValue + " already in the admin set")
+			}
+			principals = append(principals, AdminPrincipal{Kind: proposal.NewKind, Value: proposal.NewValue})
+		}
+
+	case "quorum":
+		quorum, err := strconv.Atoi(proposal.Value)
+		if err != nil || quorum < 1 {
+			return admins, errors.New("Invalid quorum, expected a whole number of at least 1")
+		}
+		principals = admins.Principals
+		admins.Quorum = quorum
+
+	default:
+		return admins, errors.New("Invalid admin change, expected add, remove, rotate or quorum")
+	}
+
+	if len(principals) < admins.Quorum {
+		return admins, fmt.Errorf("The admin set must keep at least %d principals for its quorum", admins.Quorum)
+	}
+
+	admins.Principals = principals
+
+	return admins, nil
+}
+
+//=================================================================================================================================
+//	 Freeze Functions
+//=================================================================================================================================
+//	 freeze - Stops every transition on a program and its revisions, or on every program of a vendor or an anchor,
+//			  until it is unfrozen
+//=================================================================================================================================
+func (t *AssetManagementChaincode) freeze(stub shim.ChaincodeStubInterface, request PolicyRequest, scope, target, reasonCode, reason string) ([]byte, error) {
+
+	if scope != FREEZE_PROGRAM && scope != FREEZE_VENDOR && scope != FREEZE_ANCHOR {
+		return nil, errors.New("Invalid freeze scope, expected " + FREEZE_PROGRAM + ", " + FREEZE_VENDOR + " or " + FREEZE_ANCHOR)
+	}
+
+	if target == "" || target == "UNDEFINED" {
+		return nil, errors.New("Invalid freeze target provided")
+	}
+
+	valid := false
+	for _, code := range FREEZE_REASON_CODES {
+		if code == reasonCode {
+			valid = true
+		}
+	}
+	if !valid {
+		return nil, errors.New("Invalid freeze reason code, expected one of " + strings.Join(FREEZE_REASON_CODES, ", "))
+	}
+
+	existing, frozen, err := t.retrieve_freeze(stub, scope, target)
+	if err != nil {
+		return nil, err
+	}
+	if frozen {
+		return nil, errors.New("The " + scope + " " + target + " is already frozen")
+	}
+
+	f := Freeze{Scope: scope, Target: target, ReasonCode: reasonCode, Reason: reason, FrozenBy: request.CallerAccount}
+
+	f.FrozenAt, err = tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = t.save_freeze(stub, f)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.Scope != "" { // Refreezing replaces the lifted freeze, which is already listed
+		return nil, nil
+	}
+
+	bytes, err := stub.GetState("freezeIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get freezeIDs")
+	}
+
+	var freezeIDs Freeze_Holder
+
+	if len(bytes) != 0 {
+		err = json.Unmarshal(bytes, &freezeIDs)
+		if err != nil {
+			return nil, errors.New("Corrupt Freeze_Holder record")
+		}
+	}
+
+	freezeIDs.FREEZEs = append(freezeIDs.FREEZEs, scope+"_"+target)
+
+	bytes, err = json.Marshal(freezeIDs)
+	if err != nil {
+		return nil, errors.New("Error creating Freeze_Holder record")
+	}
+
+	err = stub.PutState("freezeIDs", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 request_unfreeze - First step of lifting a freeze. A second admin must approve it with approve_unfreeze.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) request_unfreeze(stub shim.ChaincodeStubInterface, request PolicyRequest, scope, target, reason string) ([]byte, error) {
+
+	f, frozen, err := t.retrieve_freeze(stub, scope, target)
+	if err != nil {
+		return nil, err
+	}
+	if !frozen {
+		return nil, errors.New("The " + scope + " " + target + " is not frozen")
+	}
+
+	if reason == "" {
+		return nil, errors.New("A reason must be given for lifting a freeze")
+	}
+
+	f.UnfreezeRequestedBy = request.CallerAccount
+	f.UnfreezeReason = reason
+	f.UnfreezeRequestedAt, err = tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = t.save_freeze(stub, f)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 approve_unfreeze - Lifts a freeze once a different admin from the one who requested it approves
+//=================================================================================================================================
+func (t *AssetManagementChaincode) approve_unfreeze(stub shim.ChaincodeStubInterface, request PolicyRequest, scope, target string) ([]byte, error) {
+
+	f, frozen, err := t.retrieve_freeze(stub, scope, target)
+	if err != nil {
+		return nil, err
+	}
+	if !frozen {
+		return nil, errors.New("The " + scope + " " + target + " is not frozen")
+	}
+
+	if f.UnfreezeRequestedBy == "" {
+		return nil, errors.New("No request to unfreeze the " + scope + " " + target)
+	}
+
+	if f.UnfreezeRequestedBy == request.CallerAccount {
+		return nil, errors.New("Unfreezing must be approved by a second admin")
+	}
+
+	f.UnfrozenBy = request.CallerAccount
+	f.UnfrozenAt, err = tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = t.save_freeze(stub, f)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 retrieve_freeze / save_freeze - Read and write the Freeze at "freeze_<scope>_<target>". A lifted freeze is kept for
+//									 the record and reported as not frozen.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) retrieve_freeze(stub shim.ChaincodeStubInterface, scope, target string) (Freeze, bool, error) {
+
+	var f Freeze
+
+	bytes, err := stub.GetState("freeze_" + scope + "_" + target)
+	if err != nil {
+		return f, false, errors.New("Unable to get freeze record")
+	}
+
+	if len(bytes) == 0 {
+		return f, false, nil
+	}
+
+	err = json.Unmarshal(bytes, &f)
+	if err != nil {
+		return f, false, errors.New("Corrupt freeze record " + scope + " " + target)
+	}
+
+	return f, f.UnfrozenAt == "", nil
+}
+
+func (t *AssetManagementChaincode) save_freeze(stub shim.ChaincodeStubInterface, f Freeze) (bool, error) {
+
+	bytes, err := json.Marshal(f)
+	if err != nil {
+		return false, errors.New("Error converting Freeze record")
+	}
+
+	err = stub.PutState("freeze_"+f.Scope+"_"+f.Target, bytes)
+	if err != nil {
+		return false, errors.New("Error storing Freeze record")
+	}
+
+	return true, nil
+}
+
+//=================================================================================================================================
+//	 program_freeze - Returns the freeze in force on a program, if any: on the program or the original it was revised
+//					  from, on its vendor, or on its anchor
+//=================================================================================================================================
+func (t *AssetManagementChaincode) program_freeze(stub shim.ChaincodeStubInterface, v AnchorProgram) (*Freeze, error) {
+
+	targets := [][2]string{{FREEZE_VENDOR, v.VendorID}, {FREEZE_ANCHOR, v.AnchorID}}
+
+	id := v.AnchorProgramID
+	for {
+		targets = append(targets, [2]string{FREEZE_PROGRAM, id})
+
+		i := strings.LastIndex(id, "-R")
+		if i <= 0 {
+			break
+		}
+		id = id[:i]
+	}
+
+	for _, target := range targets {
+		if target[1] == "" || target[1] == "UNDEFINED" {
+			continue
+		}
+
+		f, frozen, err := t.retrieve_freeze(stub, target[0], target[1])
+		if err != nil {
+			return nil, err
+		}
+		if frozen {
+			return &f, nil
+		}
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 Participant Functions
+//=================================================================================================================================
+//	 register_participant - Adds an account to the participant registry, or replaces its entry. Roles is a JSON list and
+//							certFingerprint the hex SHA-256 of the account's enrolment certificate, or empty.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) register_participant(stub shim.ChaincodeStubInterface, callerAccount []byte, account, roles, org, name, certFingerprint string) ([]byte, error) {
+
+	if account == "" {
+		return nil, errors.New("Invalid account provided")
+	}
+
+	var p Participant
+
+	existing, err := t.retrieve_participant(stub, account)
+	if err == nil {
+		p = existing
+	}
+
+	p.AccountID = account
+	p.Organization = org
+	p.DisplayName = name
+	p.CertFingerprint = strings.ToLower(certFingerprint)
+	p.UpdatedBy = string(callerAccount)
+
+	err = json.Unmarshal([]byte(roles), &p.Roles)
+	if err != nil || len(p.Roles) == 0 {
+		return nil, errors.New("Invalid roles, expected a JSON list with at least one role")
+	}
+
+	if p.CertFingerprint != "" {
+		decoded, err := hex.DecodeString(p.CertFingerprint)
+		if err != nil || len(decoded) != sha256.Size {
+			return nil, errors.New("Invalid certificate fingerprint, expected a hex encoded SHA-256")
+		}
+	}
+
+	if p.Status == "" {
+		p.Status = PARTICIPANT_ACTIVE
+	}
+
+	p.UpdatedAt, err = tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = t.save_participant(stub, p)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.AccountID == account {
+		return nil, nil
+	}
+
+	bytes, err := stub.GetState("participantIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get participantIDs")
+	}
+
+	var participantIDs Participant_Holder
+
+	if len(bytes) != 0 {
+		err = json.Unmarshal(bytes, &participantIDs)
+		if err != nil {
+			return nil, errors.New("Corrupt Participant_Holder record")
+		}
+	}
+
+	participantIDs.PARTICIPANTs = append(participantIDs.PARTICIPANTs, account)
+
+	bytes, err = json.Marshal(participantIDs)
+	if err != nil {
+		fmt.Print("Error creating Participant_Holder record")
+	}
+
+	err = stub.PutState("participantIDs", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 update_participant_status - Suspends or reactivates a registered account
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_participant_status(stub shim.ChaincodeStubInterface, callerAccount []byte, account, status string) ([]byte, error) {
+
+	if status != PARTICIPANT_ACTIVE && status != PARTICIPANT_SUSPENDED {
+		return nil, errors.New("Invalid status, expected " + PARTICIPANT_ACTIVE + " or " + PARTICIPANT_SUSPENDED)
+	}
+
+	p, err := t.retrieve_participant(stub, account)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Status = status
+	p.UpdatedBy = string(callerAccount)
+	p.UpdatedAt, err = tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = t.save_participant(stub, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 retrieve_participant / save_participant - Read and write a Participant at "participant_<account>"
+//=================================================================================================================================
+func (t *AssetManagementChaincode) retrieve_participant(stub shim.ChaincodeStubInterface, account string) (Participant, error) {
+
+	var p Participant
+
+	bytes, err := stub.GetState("participant_" + account)
+	if err != nil || len(bytes) == 0 {
+		return p, errors.New("No registered participant with account " + account)
+	}
+
+	err = json.Unmarshal(bytes, &p)
+	if err != nil {
+		return p, errors.New("Corrupt participant record " + account)
+	}
+
+	return p, nil
+}
+
+func (t *AssetManagementChaincode) save_participant(stub shim.ChaincodeStubInterface, p Participant) (bool, error) {
+
+	bytes, err := json.Marshal(p)
+	if err != nil {
+		return false, errors.New("Error converting Participant record")
+	}
+
+	err = stub.PutState("participant_"+p.AccountID, bytes)
+	if err != nil {
+		return false, errors.New("Error storing Participant record")
+	}
+
+	return true, nil
+}
+
+//=================================================================================================================================
+//	 check_caller - Rejects callers whose registry entry is suspended, who call in a role they are not registered for, or
+//					whose certificate is not the one registered. Accounts not in the registry may call while the access
+//					policy sets allowUnregistered, as it does when deployed; once an admin has registered the participants
+//					and turned it off, only admins may call without being registered.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) check_caller(stub shim.ChaincodeStubInterface, callerAccount []byte, caller_affiliation string) (Participant, bool, error) {
+
+	p, err := t.retrieve_participant(stub, string(callerAccount))
+	if err != nil {
+		if t.is_admin(stub, string(callerAccount), caller_affiliation) {
+			return p, false, nil
+		}
+
+		policy, err := t.retrieve_access_policy(stub, "")
+		if err != nil {
+			return p, false, err
+		}
+
+		if !policy.AllowUnregistered {
+			return p, false, errors.New("Account " + string(callerAccount) + " is not a registered participant")
+		}
+
+		return p, false, nil
+	}
+
+	if p.Status != PARTICIPANT_ACTIVE {
+		return p, true, errors.New("Participant " + p.AccountID + " is " + p.Status)
+	}
+
+	if !contains(p.Roles, caller_affiliation) {
+		return p, true, errors.New("Participant " + p.AccountID + " is not registered in role " + caller_affiliation)
+	}
+
+	if p.CertFingerprint != "" {
+		cert, err := stub.GetCallerCertificate()
+		if err != nil {
+			return p, true, errors.New("Failed fetching caller certificate")
+		}
+
+		hash := sha256.Sum256(cert)
+		if hex.EncodeToString(hash[:]) != p.CertFingerprint {
+			return p, true, errors.New("Caller certificate does not match the one registered for " + p.AccountID)
+		}
+	}
+
+	return p, true, nil
+}
+
+//=================================================================================================================================
+//	 resolve_recipient - Looks up the recipient of a transfer in the registry and picks the role it receives in: the
+//						 first of its registered roles the access policy allows for the transfer.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) resolve_recipient(stub shim.ChaincodeStubInterface, request PolicyRequest, account string) (PolicyRequest, error) {
+
+	recipient, err := t.retrieve_participant(stub, account)
+	if err != nil {
+		return request, errors.New("Recipient " + account + " is not a registered participant")
+	}
+
+	if recipient.Status != PARTICIPANT_ACTIVE {
+		return request, errors.New("Recipient " + account + " is " + recipient.Status)
+	}
+
+	request.RecipientOrg = recipient.Organization
+
+	err = errors.New("Recipient " + account + " has no role registered")
+
+	for _, role := range recipient.Roles {
+		request.RecipientRole = role
+
+		err = t.authorize(stub, request)
+		if err == nil {
+			return request, nil
+		}
+	}
+
+	return request, err
+}
+
+//=================================================================================================================================
+//	 KYC Functions
+//=================================================================================================================================
+//	 submit_kyc_item - Records a KYC document and its details for a vendor, replacing any earlier item of the same type.
+//					   The item waits for a KYC officer, so the vendor's KYC is pending again until it is reviewed.
+//					   A vendor may only submit for a vendor ID an admin has assigned to its account.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) submit_kyc_item(stub shim.ChaincodeStubInterface, request PolicyRequest, vendorID, itemType, document, data string) ([]byte, error) {
+
+	if vendorID == "" || vendorID == "UNDEFINED" || itemType == "" {
+		return nil, errors.New("A vendor ID and item type must be provided")
+	}
+
+	r, err := t.retrieve_kyc(stub, vendorID)
+	if err != nil {
+		r = KYCRecord{VendorID: vendorID}
+	}
+
+	if request.CallerRole == ROLE_VENDOR && (r.Account == "" || r.Account != request.CallerAccount) {
+		return nil, errors.New("KYC for vendor " + vendorID + " has not been assigned to this account")
+	}
+
+	item := KYCItem{ItemType: itemType, Data: data, Status: KYC_PENDING, SubmittedBy: request.CallerAccount}
+
+	item.Document, err = t.parse_document(stub, document, []byte(request.CallerAccount))
+	if err != nil {
+		return nil, err
+	}
+	item.SubmittedAt = item.Document.RecordedAt
+
+	err = require_sealed([]PIIField{{Name: "kyc_" + itemType, Value: &item.Data}})
+	if err != nil {
+		return nil, err
+	}
+
+	replaced := false
+	for i := range r.Items {
+		if r.Items[i].ItemType == itemType {
+			r.Items[i] = item
+			replaced = true
+		}
+	}
+	if !replaced {
+		r.Items = append(r.Items, item)
+	}
+
+	r.Status = kyc_status(r)
+	r.UpdatedAt = item.SubmittedAt
+
+	_, err = t.save_kyc(stub, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 assign_kyc_account - Binds a vendor ID's KYC to the registered vendor account that may submit items for it
+//=================================================================================================================================
+func (t *AssetManagementChaincode) assign_kyc_account(stub shim.ChaincodeStubInterface, request PolicyRequest, vendorID, account string) ([]byte, error) {
+
+	if vendorID == "" || vendorID == "UNDEFINED" || account == "" {
+		return nil, errors.New("A vendor ID and account must be provided")
+	}
+
+	p, err := t.retrieve_participant(stub, account)
+	if err != nil || p.Status != PARTICIPANT_ACTIVE || !contains(p.Roles, ROLE_VENDOR) {
+		return nil, errors.New("Account " + account + " is not an active registered vendor")
+	}
+
+	r, err := t.retrieve_kyc(stub, vendorID)
+	if err != nil {
+		r = KYCRecord{VendorID: vendorID, Status: KYC_PENDING}
+	}
+
+	r.Account = account
+	r.UpdatedAt, err = tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = t.save_kyc(stub, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 review_kyc_item - A KYC officer verifies or rejects one item. Once every required item is verified the vendor's KYC is
+//					   verified until expiry (YYYY-MM-DD), by default KYC_VALIDITY_DAYS from the review. An officer
+//					   cannot review an item they submitted.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) review_kyc_item(stub shim.ChaincodeStubInterface, request PolicyRequest, vendorID, itemType, decision, reason, expiry string) ([]byte, error) {
+
+	if decision != KYC_VERIFIED && decision != KYC_REJECTED {
+		return nil, errors.New("Invalid decision, expected " + KYC_VERIFIED + " or " + KYC_REJECTED)
+	}
+
+	if decision == KYC_REJECTED && reason == "" {
+		return nil, errors.New("A reason must be given when rejecting a KYC item")
+	}
+
+	r, err := t.retrieve_kyc(stub, vendorID)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	found := false
+	for i := range r.Items {
+		if r.Items[i].ItemType != itemType {
+			continue
+		}
+
+		if r.Items[i].SubmittedBy == request.CallerAccount {
+			return nil, errors.New("KYC items cannot be reviewed by the account that submitted them")
+		}
+
+		r.Items[i].Status = decision
+		r.Items[i].ReviewedBy = request.CallerAccount
+		r.Items[i].ReviewedAt = now
+		r.Items[i].Reason = reason
+		found = true
+	}
+
+	if !found {
+		return nil, errors.New("No " + itemType + " KYC item submitted for vendor " + vendorID)
+	}
+
+	r.Status = kyc_status(r)
+	r.UpdatedAt = now
+
+	if r.Status == KYC_VERIFIED {
+		if expiry == "" {
+			verified, err := time.Parse("2006-01-02", now[:10])
+			if err != nil {
+				return nil, errors.New("Invalid transaction timestamp " + now)
+			}
+			expiry = verified.AddDate(0, 0, KYC_VALIDITY_DAYS).Format("2006-01-02")
+		}
+
+		_, err = time.Parse("2006-01-02", expiry)
+		if err != nil || expiry <= now[:10] {
+			return nil, errors.New("Invalid KYC expiry, expected a future date as YYYY-MM-DD")
+		}
+
+		r.VerifiedAt = now
+		r.VerifiedBy = request.CallerAccount
+		r.ExpiryDate = expiry
+	}
+
+	_, err = t.save_kyc(stub, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 kyc_status - The overall KYC status: rejected if any item is, verified once every required item is, otherwise pending
+//=================================================================================================================================
+func kyc_status(r KYCRecord) string {
+
+	verified := map[string]bool{}
+
+	for _, item := range r.Items {
+		if item.Status == KYC_REJECTED {
+			return KYC_REJECTED
+		}
+		if item.Status == KYC_VERIFIED {
+			verified[item.ItemType] = true
+		}
+	}
+
+	for _, required := range KYC_REQUIRED_ITEMS {
+		if !verified[required] {
+			return KYC_PENDING
+		}
+	}
+
+	return KYC_VERIFIED
+}
+
+//=================================================================================================================================
+//	 kyc_verified - Returns an error unless the vendor's KYC is verified and has not expired
+//=================================================================================================================================
+func (t *AssetManagementChaincode) kyc_verified(stub shim.ChaincodeStubInterface, vendorID string) error {
+
+	r, err := t.retrieve_kyc(stub, vendorID)
+	if err != nil {
+		return errors.New("KYC has not been submitted for vendor " + vendorID)
+	}
+
+	if r.Status != KYC_VERIFIED {
+		return errors.New("KYC for vendor " + vendorID + " is " + r.Status)
+	}
+
+	now, err := tx_timestamp(stub)
+	if err != nil {
+		return err
+	}
+
+	if now[:10] > r.ExpiryDate {
+		return errors.New("KYC for vendor " + vendorID + " expired on " + r.ExpiryDate)
+	}
+
+	return nil
+}
+
+//=================================================================================================================================
+//	 retrieve_kyc / save_kyc - Read and write a vendor's KYCRecord at "kyc_<vendorID>". save_kyc adds new vendors to kycIDs.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) retrieve_kyc(stub shim.ChaincodeStubInterface, vendorID string) (KYCRecord, error) {
+
+	var r KYCRecord
+
+	bytes, err := stub.GetState("kyc_" + vendorID)
+	if err != nil || len(bytes) == 0 {
+		return r, errors.New("No KYC record for vendor " + vendorID)
+	}
+
+	err = json.Unmarshal(bytes, &r)
+	if err != nil {
+		return r, errors.New("Corrupt KYC record " + vendorID)
+	}
+
+	return r, nil
+}
+
+func (t *AssetManagementChaincode) save_kyc(stub shim.ChaincodeStubInterface, r KYCRecord) (bool, error) {
+
+	existing, err := stub.GetState("kyc_" + r.VendorID)
+	if err != nil {
+		return false, errors.New("Error reading KYC record")
+	}
+
+	bytes, err := json.Marshal(r)
+	if err != nil {
+		return false, errors.New("Error converting KYC record")
+	}
+
+	err = stub.PutState("kyc_"+r.VendorID, bytes)
+	if err != nil {
+		return false, errors.New("Error storing KYC record")
+	}
+
+	if len(existing) != 0 {
+		return true, nil
+	}
+
+	bytes, err = stub.GetState("kycIDs")
+	if err != nil {
+		return false, errors.New("Unable to get kycIDs")
+	}
+
+	var kycIDs KYC_Holder
+
+	if len(bytes) != 0 {
+		err = json.Unmarshal(bytes, &kycIDs)
+		if err != nil {
+			return false, errors.New("Corrupt KYC_Holder record")
+		}
+	}
+
+	kycIDs.VENDORs = append(kycIDs.VENDORs, r.VendorID)
+
+	bytes, err = json.Marshal(kycIDs)
+	if err != nil {
+		fmt.Print("Error creating KYC_Holder record")
+	}
+
+	err = stub.PutState("kycIDs", bytes)
+	if err != nil {
+		return false, errors.New("Unable to put the state")
+	}
+
+	return true, nil
+}
+
+//=================================================================================================================================
+//	 Payment Batch Functions
+//=================================================================================================================================
+//	 transfer_payment_maker_to_payment_checker_batch - Sends a list of the maker's initiated invoices, e.g. ["MO1","MO2"],
+//													   to a payment checker as one batch. Every invoice must be ready
+//													   to send or none is.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) transfer_payment_maker_to_payment_checker_batch(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string, batchID string, moids string) ([]byte, error) {
+
+	var list []string
+
+	err := json.Unmarshal([]byte(moids), &list)
+	if err != nil || len(list) == 0 {
+		return nil, errors.New("Invalid invoice list, expected a JSON list of invoice IDs")
+	}
+
+	if batchID == "" {
+		return nil, errors.New("A batch ID must be given")
+	}
+
+	record, err := stub.GetState("batch_" + batchID)
+	if err != nil {
+		return nil, errors.New("Unable to get batch record")
+	}
+	if len(record) != 0 {
+		return nil, errors.New("Payment batch " + batchID + " already exists")
+	}
+
+	if v.Status != STATE_PURCHASE_ORDER_PLACED || v.Settled == true {
+		return nil, errors.New("Permission denied")
+	}
+
+	now, err := tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	b := PaymentBatch{BatchID: batchID, AnchorProgramID: v.AnchorProgramID, Status: BATCH_PENDING_APPROVAL, Maker: string(callerAccount), Checker: receiverAccount, CreatedAt: now}
+
+	seen := map[string]bool{}
+
+	for _, moid := range list {
+
+		if seen[moid] {
+			return nil, errors.New("Invoice " + moid + " is listed twice")
+		}
+		seen[moid] = true
+
+		x, err := t.retrieve_invoice(stub, moid)
+		if err != nil {
+			return nil, errors.New("Error retrieving invoice " + moid)
+		}
+
+		if x.InvoiceID == "UNDEFINED" ||
+			!has_invoice_document(x) ||
+			x.MOStatus != STATE_INVOICE_PAYMENT_INITIATED ||
+			x.MOOwner != string(callerAccount) ||
+			x.MOReceivableAmount <= 0 ||
+			x.PaymentChannel == "" ||
+			x.MOPaid == true ||
+			x.MOSettled == true {
+			return nil, errors.New("Invoice " + moid + " is not ready to send for payment approval")
+		}
+
+		found := false
+
+		for i := range v.Items {
+			if x.MOID == v.Items[i].MOID {
+
+				found = true
+
+				x.MOOwner = receiverAccount
+				v.Items[i].MOOwner = receiverAccount
+
+				x.MOStatus = STATE_INVOICE_PAYMENT_PENDING_APPROVAL
+				v.Items[i].MOStatus = STATE_INVOICE_PAYMENT_PENDING_APPROVAL
+
+				x.PaymentBatch = batchID
+				v.Items[i].PaymentBatch = batchID
+
+				break
+			}
+		}
+
+		if !found {
+			return nil, errors.New("Invoice " + moid + " is not part of program " + v.AnchorProgramID)
+		}
+
+		err = t.retire_parent(stub, x, &v)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = t.save_invoice(stub, x)
+		if err != nil {
+			fmt.Printf("TRANSFER_PAYMENT_MAKER_TO_PAYMENT_CHECKER_BATCH: Error saving changes to Invoice: %s", err)
+			return nil, errors.New("Error saving changes to invoice")
+		}
+
+		b.Items = append(b.Items, BatchItem{MOID: moid, Amount: x.MOReceivableAmount, Result: BATCH_PENDING_APPROVAL})
+		b.Total += x.MOReceivableAmount
+		b.Count += 1
+	}
+
+	_, err = t.save_batch(stub, b)
+	if err != nil {
+		return nil, err
+	}
+
+	v.PaymentBatches = append(v.PaymentBatches, batchID)
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_PAYMENT_MAKER_TO_PAYMENT_CHECKER_BATCH: Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New("Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 update_checker_payment_batch - The batch's checker approves or rejects every invoice in it. An approval counts towards
+//									each invoice's approval band, so an invoice needing co-signers stays pending. A rejected
+//									invoice returns to its payment maker. Invoices that have moved on since the batch was
+//									sent, that the checker has already approved, or that segregation of duties keeps
+//									from the checker are skipped.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_checker_payment_batch(stub shim.ChaincodeStubInterface, request PolicyRequest, v AnchorProgram, batchID string, decision string, reason string) ([]byte, error) {
+
+	callerAccount := []byte(request.CallerAccount)
+
+	decision = strings.ToUpper(strings.TrimSpace(decision))
+
+	if decision != "APPROVE" && decision != "REJECT" {
+		return nil, errors.New("Invalid decision, expected APPROVE or REJECT")
+	}
+
+	if decision == "REJECT" && strings.TrimSpace(reason) == "" {
+		return nil, errors.New("A reason must be given to reject a batch")
+	}
+
+	b, err := t.retrieve_batch(stub, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.AnchorProgramID != v.AnchorProgramID ||
+		b.Status != BATCH_PENDING_APPROVAL ||
+		b.Checker != string(callerAccount) ||
+		v.Status != STATE_PURCHASE_ORDER_PLACED ||
+		v.Settled == true {
+		return nil, errors.New("Permission denied")
+	}
+
+	now, err := tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	done := 0
+
+	for k := range b.Items {
+
+		item := &b.Items[k]
+
+		x, err := t.retrieve_invoice(stub, item.MOID)
+		if err != nil {
+			return nil, errors.New("Error retrieving invoice " + item.MOID)
+		}
+
+		if x.PaymentBatch != batchID ||
+			x.MOStatus != STATE_INVOICE_PAYMENT_PENDING_APPROVAL ||
+			x.MOOwner != string(callerAccount) ||
+			x.MOPaid == true ||
+			x.MOSettled == true {
+			item.Result = BATCH_SKIPPED
+			item.Detail = "Invoice is no longer pending approval in this batch"
+			continue
+		}
+
+		if decision == "APPROVE" {
+			err = t.check_segregation(stub, request, v, x, "") // The same checks as approving the invoice on its own
+			if err == nil && approved_by(x, string(callerAccount)) {
+				err = errors.New("Invoice has already been approved by this checker")
+			}
+			if err != nil {
+				item.Result = BATCH_SKIPPED
+				item.Detail = err.Error()
+				continue
+			}
+		}
+
+		x.PaymentBatch = ""
+
+		if decision == "REJECT" {
+
+			x.MOStatus = STATE_INVOICE_PAYMENT_INITIATED
+			x.MOOwner = x.PaymentMaker
+			x.Approvals = nil
+			x.MORemarks = reason
+
+			item.Result = BATCH_REJECTED
+			item.Detail = reason
+
+		} else {
+
+			amount := x.MOReceivableAmount
+			if amount == 0 {
+				amount = x.ApprovedInvoiceAmount
+			}
+
+			x.RequiredApprovals = required_approvals(v, amount)
+			x.Approvals = append(x.Approvals, PaymentApproval{Account: string(callerAccount), ApprovedAt: now})
+
+			if len(x.Approvals) < x.RequiredApprovals {
+				item.Result = BATCH_AWAITING_APPROVALS
+				item.Detail = fmt.Sprintf("%d of %d approvals", len(x.Approvals), x.RequiredApprovals)
+			} else {
+				x.CheckerApprovedPayment = true
+				x.MOStatus = STATE_INVOICE_PAYMENT_APPROVED
+
+				item.Result = BATCH_APPROVED
+				item.Detail = ""
+			}
+		}
+
+		for i := range v.Items {
+			if x.MOID == v.Items[i].MOID {
+
+				v.Items[i].MOStatus = x.MOStatus
+				v.Items[i].MOOwner = x.MOOwner
+				v.Items[i].MORemarks = x.MORemarks
+				v.Items[i].PaymentBatch = x.PaymentBatch
+				v.Items[i].RequiredApprovals = x.RequiredApprovals
+				v.Items[i].Approvals = x.Approvals
+				v.Items[i].CheckerApprovedPayment = x.CheckerApprovedPayment
+
+				break
+			}
+		}
+
+		_, err = t.save_invoice(stub, x)
+		if err != nil {
+			fmt.Printf("UPDATE_CHECKER_PAYMENT_BATCH: Error saving changes to Invoice: %s", err)
+			return nil, errors.New("Error saving changes to invoice")
+		}
+
+		done += 1
+	}
+
+	if decision == "REJECT" {
+		b.Status = BATCH_REJECTED
+	} else {
+		b.Status = BATCH_APPROVED
+	}
+
+	if done < len(b.Items) {
+		b.Status = BATCH_PARTIAL
+	}
+
+	for _, item := range b.Items {
+		if item.Result == BATCH_AWAITING_APPROVALS {
+			b.Status = BATCH_PARTIAL
+		}
+	}
+
+	b.DecidedAt = now
+	b.Reason = reason
+
+	_, err = t.save_batch(stub, b)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("UPDATE_CHECKER_PAYMENT_BATCH: Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New("Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 approved_by - Whether account has already approved the invoice's payment
+//=================================================================================================================================
+func approved_by(x MyBoxItem, account string) bool {
+
+	for _, approval := range x.Approvals {
+		if approval.Account == account {
+			return true
+		}
+	}
+
+	return false
+}
+
+//=================================================================================================================================
+//	 retire_parent - Retires the invoice a revision was raised from, as each invoice transition does when a revision moves on
+//=================================================================================================================================
+func (t *AssetManagementChaincode) retire_parent(stub shim.ChaincodeStubInterface, x MyBoxItem, v *AnchorProgram) error {
+
+	if x.MOParent == "" || x.MOParent == "UNDEFINED" {
+		return nil
+	}
+
+	f, err := t.retrieve_invoice(stub, x.MOParent)
+	if err != nil {
+		return errors.New("Error retrieving invoice " + err.Error())
+	}
+
+	f.MOStatus = STATE_INVOICE_RETIRED
+
+	for j := range v.Items {
+		if f.MOID == v.Items[j].MOID {
+			v.Items[j].MOStatus = STATE_INVOICE_RETIRED
+			break
+		}
+	}
+
+	_, err = t.save_invoice(stub, f)
+	if err != nil {
+		return errors.New("Error saving changes to invoice")
+	}
+
+	return nil
+}
+
+//=================================================================================================================================
+//	 retrieve_batch / save_batch - Read and write the PaymentBatch at "batch_<batchID>"
+//=================================================================================================================================
+func (t *AssetManagementChaincode) retrieve_batch(stub shim.ChaincodeStubInterface, batchID string) (PaymentBatch, error) {
+
+	var b PaymentBatch
+
+	bytes, err := stub.GetState("batch_" + batchID)
+	if err != nil || len(bytes) == 0 {
+		return b, errors.New("No payment batch " + batchID)
+	}
+
+	err = json.Unmarshal(bytes, &b)
+	if err != nil {
+		return b, errors.New("Corrupt payment batch record " + batchID)
+	}
+
+	return b, nil
+}
+
+func (t *AssetManagementChaincode) save_batch(stub shim.ChaincodeStubInterface, b PaymentBatch) (bool, error) {
+
+	bytes, err := json.Marshal(b)
+	if err != nil {
+		return false, errors.New("Error converting PaymentBatch record")
+	}
+
+	err = stub.PutState("batch_"+b.BatchID, bytes)
+	if err != nil {
+		return false, errors.New("Error storing PaymentBatch record")
+	}
+
+	return true, nil
+}
+
+//=================================================================================================================================
+//	 Journal Functions
+//=================================================================================================================================
+//	 default_gl_accounts - The GL account codes used before any are stored
+//=================================================================================================================================
+func default_gl_accounts() GLAccounts {
+
+	return GLAccounts{Codes: map[string]string{
+		GL_RECEIVABLE:      "140100",
+		GL_FEE_RECEIVABLE:  "140200",
+		GL_SETTLEMENT:      "110100",
+		GL_INTEREST_INCOME: "410100",
+		GL_FEE_INCOME:      "420100",
+		GL_GST_PAYABLE:     "230100",
+		GL_WRITE_OFF:       "510100",
+	}}
+}
+
+//=================================================================================================================================
+//	 retrieve_gl_accounts - Gets the GL account codes from "glAccounts", or the defaults if none are stored
+//=================================================================================================================================
+func (t *AssetManagementChaincode) retrieve_gl_accounts(stub shim.ChaincodeStubInterface) (GLAccounts, error) {
+
+	bytes, err := stub.GetState("glAccounts")
+	if err != nil {
+		return GLAccounts{}, errors.New("Unable to get glAccounts")
+	}
+
+	if len(bytes) == 0 {
+		return default_gl_accounts(), nil
+	}
+
+	var accounts GLAccounts
+
+	err = json.Unmarshal(bytes, &accounts)
+	if err != nil {
+		return accounts, errors.New("Corrupt glAccounts record")
+	}
+
+	return accounts, nil
+}
+
+//=================================================================================================================================
+//	 update_gl_accounts - Replaces the GL account codes with the JSON passed, e.g. {"RECEIVABLE":"140100","SETTLEMENT":"110100",
+//						  ...}. Every ledger account must be given a code.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_gl_accounts(stub shim.ChaincodeStubInterface, callerAccount []byte, raw string) ([]byte, error) {
+
+	var codes map[string]string
+
+	err := json.Unmarshal([]byte(raw), &codes)
+	if err != nil {
+		return nil, errors.New("Invalid GL accounts, expected a JSON object of ledger account to GL code")
+	}
+
+	for account := range default_gl_accounts().Codes {
+		if strings.TrimSpace(codes[account]) == "" {
+			return nil, errors.New("No GL code given for " + account)
+		}
+	}
+
+	for account := range codes {
+		if _, ok := default_gl_accounts().Codes[account]; !ok {
+			return nil, errors.New("Unknown ledger account " + account)
+		}
+	}
+
+	existing, err := t.retrieve_gl_accounts(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := GLAccounts{Codes: codes, Version: existing.Version + 1, UpdatedBy: string(callerAccount)}
+
+	accounts.UpdatedAt, err = tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := json.Marshal(accounts)
+	if err != nil {
+		return nil, errors.New("Error converting glAccounts")
+	}
+
+	err = stub.PutState("glAccounts", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 post_journal - Posts balanced journal lines for a transition and returns the key of the entry. Lines of zero are dropped
+//					and each line is given the GL code of its ledger account.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) post_journal(stub shim.ChaincodeStubInterface, event string, programID string, moid string, callerAccount []byte, lines []JournalLine, reverses string) (string, error) {
+
+	accounts, err := t.retrieve_gl_accounts(stub)
+	if err != nil {
+		return "", err
+	}
+
+	now, err := tx_timestamp(stub)
+	if err != nil {
+		return "", err
+	}
+
+	e := JournalEntry{Date: now[:10], At: now, TxID: stub.GetTxID(), Event: event, AnchorProgramID: programID, MOID: moid, PostedBy: string(callerAccount), Reverses: reverses}
+	e.EntryID = "journal_" + e.Date + "_" + e.TxID
+
+	debits, credits := 0.0, 0.0
+
+	for _, line := range lines {
+
+		line.Debit = round_amount(line.Debit)
+		line.Credit = round_amount(line.Credit)
+
+		if line.Debit == 0 && line.Credit == 0 {
+			continue
+		}
+
+		if line.Debit < 0 || line.Credit < 0 {
+			return "", errors.New("Journal line for " + line.Account + " is negative")
+		}
+
+		line.GLCode = accounts.Codes[line.Account]
+		if line.GLCode == "" {
+			return "", errors.New("No GL code for ledger account " + line.Account)
+		}
+
+		debits += line.Debit
+		credits += line.Credit
+
+		e.Lines = append(e.Lines, line)
+	}
+
+	if len(e.Lines) == 0 {
+		return "", nil
+	}
+
+	if round_amount(debits) != round_amount(credits) {
+		return "", fmt.Errorf("Journal for %s does not balance: debits %.2f, credits %.2f", event, debits, credits)
+	}
+
+	existing, err := stub.GetState(e.EntryID)
+	if err != nil {
+		return "", errors.New("Unable to get journal entry")
+	}
+	if len(existing) != 0 {
+		return "", errors.New("A journal entry has already been posted by this transaction")
+	}
+
+	bytes, err := json.Marshal(e)
+	if err != nil {
+		return "", errors.New("Error converting JournalEntry record")
+	}
+
+	err = stub.PutState(e.EntryID, bytes)
+	if err != nil {
+		return "", errors.New("Error storing JournalEntry record")
+	}
+
+	return e.EntryID, nil
+}
+
+//=================================================================================================================================
+//	 reverse_journal - Posts the mirror image of the latest unreversed entry of event among an invoice's entries, and marks
+//					   that entry reversed. Returns "" if there is nothing to reverse.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) reverse_journal(stub shim.ChaincodeStubInterface, x MyBoxItem, event string, callerAccount []byte) (string, error) {
+
+	for i := len(x.JournalEntries) - 1; i >= 0; i-- {
+
+		bytes, err := stub.GetState(x.JournalEntries[i])
+		if err != nil || len(bytes) == 0 {
+			return "", errors.New("Unable to get journal entry " + x.JournalEntries[i])
+		}
+
+		var original JournalEntry
+
+		err = json.Unmarshal(bytes, &original)
+		if err != nil {
+			return "", errors.New("Corrupt journal entry " + x.JournalEntries[i])
+		}
+
+		if original.Event != event || original.ReversedBy != "" {
+			continue
+		}
+
+		var lines []JournalLine
+		for _, line := range original.Lines {
+			lines = append(lines, JournalLine{Account: line.Account, Debit: line.Credit, Credit: line.Debit, Narrative: "Reversal: " + line.Narrative})
+		}
+
+		key, err := t.post_journal(stub, event+"_REVERSAL", original.AnchorProgramID, x.MOID, callerAccount, lines, original.EntryID)
+		if err != nil {
+			return "", err
+		}
+
+		original.ReversedBy = key
+
+		bytes, err = json.Marshal(original)
+		if err != nil {
+			return "", errors.New("Error converting JournalEntry record")
+		}
+
+		err = stub.PutState(original.EntryID, bytes)
+		if err != nil {
+			return "", errors.New("Error storing JournalEntry record")
+		}
+
+		return key, nil
+	}
+
+	return "", nil
+}
+
+//=================================================================================================================================
+//	 fee_journal_lines - Credits fee income and GST payable for fee lines, and returns their total
+//=================================================================================================================================
+func fee_journal_lines(fees []FeeLine) ([]JournalLine, float64) {
+
+	var lines []JournalLine
+	total := 0.0
+
+	for _, fee := range fees {
+		lines = append(lines,
+			JournalLine{Account: GL_FEE_INCOME, Credit: fee.Fee, Narrative: "Fee " + fee.Code},
+			JournalLine{Account: GL_GST_PAYABLE, Credit: fee.GST, Narrative: "GST on fee " + fee.Code})
+		total += fee.Total
+	}
+
+	return lines, total
+}
+
+//=================================================================================================================================
+//	 disbursement_journal - Debits the receivable with the financed amount, credits the settlement account with the cash paid
+//							out and the fee and GST accounts with the fees kept back
+//=================================================================================================================================
+func (t *AssetManagementChaincode) disbursement_journal(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte) (string, error) {
+
+	lines, fees := fee_journal_lines(x.FeeLines)
+
+	lines = append(lines,
+		JournalLine{Account: GL_RECEIVABLE, Debit: x.MOReceivableAmount + fees, Narrative: "Disbursement " + x.MOID},
+		JournalLine{Account: GL_SETTLEMENT, Credit: x.MOReceivableAmount, Narrative: "Paid " + x.UTRNumber})
+
+	return t.post_journal(stub, JOURNAL_DISBURSEMENT, v.AnchorProgramID, x.MOID, callerAccount, lines, "")
+}
+
+//=================================================================================================================================
+//	 settlement_journal - Debits the settlement account with the repayment and credits the receivable with up to the
+//						  financed amount, the rest being interest
+//=================================================================================================================================
+func (t *AssetManagementChaincode) settlement_journal(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte) (string, error) {
+
+	repaid, err := strconv.ParseFloat(x.SettlementAmount, 64)
+	if err != nil {
+		return "", errors.New("Invalid settlement amount " + x.SettlementAmount)
+	}
+
+	_, fees := fee_journal_lines(x.FeeLines)
+
+	principal := x.MOReceivableAmount + fees
+	if repaid < principal {
+		principal = repaid
+	}
+
+	lines := []JournalLine{
+		{Account: GL_SETTLEMENT, Debit: repaid, Narrative: "Repayment " + x.MOID},
+		{Account: GL_RECEIVABLE, Credit: principal, Narrative: "Principal " + x.MOID},
+		{Account: GL_INTEREST_INCOME, Credit: repaid - principal, Narrative: "Interest " + x.MOID},
+	}
+
+	return t.post_journal(stub, JOURNAL_SETTLEMENT, v.AnchorProgramID, x.MOID, callerAccount, lines, "")
+}
+
+//=================================================================================================================================
+//	 Tax Functions
+//=================================================================================================================================
+//	 update_admin_finance_base - Sets whether the program finances an invoice's GROSS amount or its taxable value
+//								 (NET_OF_TAX). Either way the TDS the buyer withholds is not financed.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_admin_finance_base(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, base string) ([]byte, error) {
+
+	base = strings.ToUpper(strings.TrimSpace(base))
+
+	if base != FINANCE_GROSS && base != FINANCE_NET_OF_TAX {
+		return nil, errors.New("Invalid finance base, expected GROSS or NET_OF_TAX")
+	}
+
+	if v.Settled == false {
+
+		v.FinanceBase = base
+
+	} else {
+
+		return nil, errors.New("Permission denied")
+
+	}
+
+	_, err := t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("update_admin_finance_base: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 parse_invoice_tax - Reads and checks the JSON tax breakdown of an invoice of amount, e.g.
+//						 {"supplierGSTIN":"27AAPFU0939F1ZV","buyerGSTIN":"27AACCB1234C1ZD","taxableValue":100000,
+//						  "cgst":9000,"sgst":9000,"igst":0,"tds":2000}
+//=================================================================================================================================
+func parse_invoice_tax(v AnchorProgram, raw string, amount float64) (InvoiceTax, error) {
+
+	var tax InvoiceTax
+
+	err := json.Unmarshal([]byte(raw), &tax)
+	if err != nil {
+		return tax, errors.New("Invalid invoice tax details, expected JSON")
+	}
+
+	tax.SupplierGSTIN = strings.ToUpper(strings.TrimSpace(tax.SupplierGSTIN))
+	tax.BuyerGSTIN = strings.ToUpper(strings.TrimSpace(tax.BuyerGSTIN))
+
+	if !valid_gstin(tax.SupplierGSTIN) {
+		return tax, errors.New("Invalid supplier GSTIN " + tax.SupplierGSTIN)
+	}
+
+	if !valid_gstin(tax.BuyerGSTIN) {
+		return tax, errors.New("Invalid buyer GSTIN " + tax.BuyerGSTIN)
+	}
+
+	gstin := strings.ToUpper(strings.TrimSpace(v.Vendorgstin))
+	if gstin != "" && gstin != "UNDEFINED" && gstin != tax.SupplierGSTIN {
+		return tax, errors.New("Supplier GSTIN does not match the vendor's GSTIN")
+	}
+
+	if tax.TaxableValue <= 0 || tax.CGST < 0 || tax.SGST < 0 || tax.IGST < 0 || tax.TDS < 0 || tax.TDS > tax.TaxableValue {
+		return tax, errors.New("Invalid taxable value, GST or TDS amount")
+	}
+
+	if tax.SupplierGSTIN[:2] == tax.BuyerGSTIN[:2] {
+		if tax.IGST != 0 || tax.CGST != tax.SGST {
+			return tax, errors.New("A supply within a state is taxed with equal CGST and SGST")
+		}
+	} else if tax.CGST != 0 || tax.SGST != 0 {
+		return tax, errors.New("A supply between states is taxed with IGST")
+	}
+
+	if round_amount(tax.TaxableValue+tax.CGST+tax.SGST+tax.IGST) != round_amount(amount) {
+		return tax, fmt.Errorf("Taxable value and GST add up to %.2f, not the invoice amount %.2f", tax.TaxableValue+tax.CGST+tax.SGST+tax.IGST, amount)
+	}
+
+	return tax, nil
+}
+
+//=================================================================================================================================
+//	 financeable_amount - The part of an invoice of amount the program finances under its finance base
+//=================================================================================================================================
+func financeable_amount(v AnchorProgram, amount float64, tax InvoiceTax) float64 {
+
+	if v.FinanceBase == FINANCE_NET_OF_TAX {
+		amount = tax.TaxableValue
+	}
+
+	return round_amount(amount - tax.TDS)
+}
+
+//=================================================================================================================================
+//	 valid_gstin - Checks the format and check character of a GSTIN: a two digit state code, the holder's PAN, an entity
+//				   number, Z and a base 36 check character
+//=================================================================================================================================
+func valid_gstin(gstin string) bool {
+
+	if !GSTIN_FORMAT.MatchString(gstin) {
+		return false
+	}
+
+	const chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+	sum := 0
+	for i := 0; i < 14; i++ {
+		product := strings.IndexByte(chars, gstin[i]) * (i%2 + 1)
+		sum += product/36 + product%36
+	}
+
+	return chars[(36-sum%36)%36] == gstin[14]
+}
+
+//=================================================================================================================================
+//	 Fee Functions
+//=================================================================================================================================
+//	 update_admin_fee_schedule - Sets the program's fee schedule, e.g.
+//								 [{"code":"PF","event":"DISBURSEMENT","basis":"PERCENT","rate":0.5,"gstRate":18},
+//								  {"code":"DOC","event":"ONBOARDING","basis":"FLAT","amount":5000,"gstRate":18}]
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_admin_fee_schedule(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, schedule string) ([]byte, error) {
+
+	var rules []FeeRule
+
+	err := json.Unmarshal([]byte(schedule), &rules)
+	if err != nil {
+		return nil, errors.New("Invalid fee schedule, expected a JSON list of fee rules")
+	}
+
+	seen := map[string]bool{}
+
+	for i, r := range rules {
+		r.Code = strings.ToUpper(strings.TrimSpace(r.Code))
+		r.Event = strings.ToUpper(strings.TrimSpace(r.Event))
+		r.Basis = strings.ToUpper(strings.TrimSpace(r.Basis))
+		rules[i] = r
+
+		if r.Code == "" || seen[r.Code] {
+			return nil, errors.New("Every fee needs a code, listed once")
+		}
+		seen[r.Code] = true
+
+		if r.Event != FEE_ONBOARDING && r.Event != FEE_DISBURSEMENT && r.Event != FEE_RENEWAL {
+			return nil, errors.New("Invalid event for fee " + r.Code + ", expected ONBOARDING, DISBURSEMENT or RENEWAL")
+		}
+
+		if r.Basis != FEE_FLAT && r.Basis != FEE_PERCENT && r.Basis != FEE_SLAB {
+			return nil, errors.New("Invalid basis for fee " + r.Code + ", expected FLAT, PERCENT or SLAB")
+		}
+
+		if r.Basis == FEE_SLAB && len(r.Slabs) == 0 {
+			return nil, errors.New("Fee " + r.Code + " has no slabs")
+		}
+
+		if r.Amount < 0 || r.Rate < 0 || r.Rate > 100 || r.GSTRate < 0 || r.GSTRate > 100 || r.PeriodMonths < 0 {
+			return nil, errors.New("Invalid amount or rate for fee " + r.Code)
+		}
+
+		for _, slab := range r.Slabs {
+			if slab.MinAmount < 0 || slab.Amount < 0 || slab.Rate < 0 || slab.Rate > 100 {
+				return nil, errors.New("Invalid slab for fee " + r.Code)
+			}
+		}
+	}
+
+	if v.Settled == false {
+
+		v.FeeSchedule = rules
+
+	} else {
+
+		return nil, errors.New("Permission denied")
+
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("update_admin_fee_schedule: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 update_admin_program_fees - Charges the program's ONBOARDING or RENEWAL fees. Onboarding fees are charged when the
+//								 program is activated, see admin_to_anchor, so this only charges them for programs
+//								 activated before then. Each renewal fee is charged at most once in its period.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_admin_program_fees(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, event string) ([]byte, error) {
+
+	event = strings.ToUpper(strings.TrimSpace(event))
+
+	if event != FEE_ONBOARDING && event != FEE_RENEWAL {
+		return nil, errors.New("Invalid event, expected ONBOARDING or RENEWAL")
+	}
+
+	if v.Settled == true {
+		return nil, errors.New("Permission denied")
+	}
+
+	charged, err := t.charge_program_fees(stub, &v, event, callerAccount)
+	if err != nil {
+		return nil, err
+	}
+	if !charged {
+		return nil, errors.New("The program has no " + event + " fees due")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("update_admin_program_fees: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 charge_program_fees - Adds the program's fees due at event to its fee lines and posts them. Fees are worked out on
+//						   the anchor's limit, as the program has no PO amount when it is activated. Onboarding fees
+//						   are only charged once, and renewal fees once per period. Reports whether any fee was charged.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) charge_program_fees(stub shim.ChaincodeStubInterface, v *AnchorProgram, event string, callerAccount []byte) (bool, error) {
+
+	now, err := tx_timestamp(stub)
+	if err != nil {
+		return false, err
+	}
+
+	all, _ := fee_lines(*v, event, v.AnchorLimit, now, stub.GetTxID())
+
+	var lines []FeeLine
+	for _, line := range all {
+		if fee_due(*v, line, now) {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) == 0 {
+		return false, nil
+	}
+
+	v.FeeLines = append(v.FeeLines, lines...)
+
+	journal, total := fee_journal_lines(lines)
+	journal = append(journal, JournalLine{Account: GL_FEE_RECEIVABLE, Debit: total, Narrative: event + " fees " + v.AnchorProgramID})
+
+	key, err := t.post_journal(stub, JOURNAL_PROGRAM_FEES, v.AnchorProgramID, "", callerAccount, journal, "")
+	if err != nil {
+		return false, err
+	}
+	if key != "" {
+		v.JournalEntries = append(v.JournalEntries, key)
+	}
+
+	return true, nil
+}
+
+//=================================================================================================================================
+//	 fee_due - Whether a program fee may be charged now: an onboarding fee if it has never been charged, a renewal fee if
+//			   its period has passed since it was last charged, or since onboarding
+//=================================================================================================================================
+func fee_due(v AnchorProgram, line FeeLine, now string) bool {
+
+	if line.Event != FEE_ONBOARDING && line.Event != FEE_RENEWAL {
+		return true
+	}
+
+	last := ""
+	for _, charged := range v.FeeLines {
+		if (charged.Event == line.Event && charged.Code == line.Code) ||
+			(line.Event == FEE_RENEWAL && charged.Event == FEE_ONBOARDING && last == "") {
+			last = charged.At
+		}
+	}
+
+	if last == "" {
+		return true
+	}
+	if line.Event == FEE_ONBOARDING {
+		return false
+	}
+
+	months := DEFAULT_RENEWAL_MONTHS
+	for _, r := range v.FeeSchedule {
+		if r.Code == line.Code && r.PeriodMonths > 0 {
+			months = r.PeriodMonths
+		}
+	}
+
+	from, err := time.Parse(time.RFC3339, last)
+	if err != nil {
+		return false
+	}
+	at, err := time.Parse(time.RFC3339, now)
+	if err != nil {
+		return false
+	}
+
+	return !at.Before(from.AddDate(0, months, 0))
+}
+
+//=================================================================================================================================
+//	 fee_lines - The fee lines the program charges on base at event, and their total including GST
+//=================================================================================================================================
+func fee_lines(v AnchorProgram, event string, base float64, at string, txID string) ([]FeeLine, float64) {
+
+	var lines []FeeLine
+	total := 0.0
+
+	for _, r := range v.FeeSchedule {
+
+		if r.Event != event {
+			continue
+		}
+
+		fee := 0.0
+
+		switch r.Basis {
+		case FEE_FLAT:
+			fee = r.Amount
+		case FEE_PERCENT:
+			fee = base * r.Rate / 100
+		case FEE_SLAB:
+			var slab *FeeSlab
+			for i := range r.Slabs {
+				if base >= r.Slabs[i].MinAmount && (slab == nil || r.Slabs[i].MinAmount > slab.MinAmount) {
+					slab = &r.Slabs[i]
+				}
+			}
+			if slab != nil {
+				fee = slab.Amount + base*slab.Rate/100
+			}
+		}
+
+		fee = round_amount(fee)
+		gst := round_amount(fee * r.GSTRate / 100)
+
+		lines = append(lines, FeeLine{Code: r.Code, Event: event, Base: base, Fee: fee, GST: gst, Total: fee + gst, At: at, TxID: txID})
+		total += fee + gst
+	}
+
+	return lines, round_amount(total)
+}
+
+//=================================================================================================================================
+//	 round_amount - Rounds an amount to the paisa
+//=================================================================================================================================
+func round_amount(amount float64) float64 {
+
+	if amount < 0 {
+		return -round_amount(-amount)
+	}
+
+	return float64(int64(amount*100+0.5)) / 100
+}
+
+//=================================================================================================================================
+//	 Payment Channel Functions
+//=================================================================================================================================
+//	 default_channel_catalogue - The channels in use before a catalogue is stored. RTGS has a floor of two lakh and IMPS
+//								 a ceiling of five lakh; an internal transfer between accounts at the bank has no UTR.
+//=================================================================================================================================
+func default_channel_catalogue() ChannelCatalogue {
+
+	return ChannelCatalogue{Channels: []PaymentChannelRule{
+		{Channel: "NEFT", MinAmount: 1, UTRFormat: `^[A-Z]{4}N[0-9A-Z]{11}$`},
+		{Channel: "RTGS", MinAmount: 200000, UTRFormat: `^[A-Z]{4}R[0-9A-Z]{17}$`},
+		{Channel: "IMPS", MinAmount: 1, MaxAmount: 500000, UTRFormat: `^[0-9]{12}$`},
+		{Channel: "IFT", MinAmount: 1},
+	}}
+}
+
+//=================================================================================================================================
+//	 retrieve_channel_catalogue - Gets the payment channel catalogue from "channelCatalogue", or the default if none is stored
+//=================================================================================================================================
+func (t *AssetManagementChaincode) retrieve_channel_catalogue(stub shim.ChaincodeStubInterface) (ChannelCatalogue, error) {
+
+	bytes, err := stub.GetState("channelCatalogue")
+	if err != nil {
+		return ChannelCatalogue{}, errors.New("Unable to get channelCatalogue")
+	}
+
+	if len(bytes) == 0 {
+		return default_channel_catalogue(), nil
+	}
+
+	var catalogue ChannelCatalogue
+
+	err = json.Unmarshal(bytes, &catalogue)
+	if err != nil {
+		return catalogue, errors.New("Corrupt channelCatalogue record")
+	}
+
+	return catalogue, nil
+}
+
+//=================================================================================================================================
+//	 update_channel_catalogue - Replaces the payment channel catalogue with the JSON passed, e.g.
+//								{"channels":[{"channel":"NEFT","minAmount":1,"maxAmount":0,"programTypes":[],"utrFormat":"..."}]}
+//								A maxAmount of 0 is no limit and empty programTypes allows every program type.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_channel_catalogue(stub shim.ChaincodeStubInterface, callerAccount []byte, raw string) ([]byte, error) {
+
+	var catalogue ChannelCatalogue
+
+	err := json.Unmarshal([]byte(raw), &catalogue)
+	if err != nil || len(catalogue.Channels) == 0 {
+		return nil, errors.New("Invalid channel catalogue, expected JSON with at least one channel")
+	}
+
+	seen := map[string]bool{}
+
+	for i, c := range catalogue.Channels {
+		c.Channel = strings.ToUpper(strings.TrimSpace(c.Channel))
+		catalogue.Channels[i].Channel = c.Channel
+
+		if c.Channel == "" || seen[c.Channel] {
+			return nil, errors.New("Every channel needs a name, listed once")
+		}
+		seen[c.Channel] = true
+
+		if c.MinAmount < 0 || c.MaxAmount < 0 || (c.MaxAmount > 0 && c.MaxAmount < c.MinAmount) {
+			return nil, errors.New("Invalid amount limits for channel " + c.Channel)
+		}
+
+		if c.UTRFormat != "" {
+			_, err = regexp.Compile(c.UTRFormat)
+			if err != nil {
+				return nil, errors.New("Invalid UTR format for channel " + c.Channel)
+			}
+		}
+	}
+
+	existing, err := t.retrieve_channel_catalogue(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	catalogue.Version = existing.Version + 1
+	catalogue.UpdatedBy = string(callerAccount)
+	catalogue.UpdatedAt, err = tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := json.Marshal(catalogue)
+	if err != nil {
+		return nil, errors.New("Error converting channelCatalogue")
+	}
+
+	err = stub.PutState("channelCatalogue", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 payment_channel - Returns the catalogue entry for a channel
+//=================================================================================================================================
+func (t *AssetManagementChaincode) payment_channel(stub shim.ChaincodeStubInterface, channel string) (PaymentChannelRule, error) {
+
+	catalogue, err := t.retrieve_channel_catalogue(stub)
+	if err != nil {
+		return PaymentChannelRule{}, err
+	}
+
+	channel = strings.ToUpper(strings.TrimSpace(channel))
+
+	for _, c := range catalogue.Channels {
+		if c.Channel == channel {
+			return c, nil
+		}
+	}
+
+	return PaymentChannelRule{}, errors.New("Unknown payment channel " + channel)
+}
+
+//=================================================================================================================================
+//	 check_payment_channel - Checks a maker's payment against the channel catalogue and the invoice's approved amount
+//=================================================================================================================================
+func (t *AssetManagementChaincode) check_payment_channel(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, channel string, amount float64) (PaymentChannelRule, error) {
+
+	c, err := t.payment_channel(stub, channel)
+	if err != nil {
+		return c, err
+	}
+
+	approved := x.ApprovedInvoiceAmount
+	if approved == 0 {
+		approved = x.MOAmount
+	}
+
+	if amount <= 0 {
+		return c, errors.New("Invalid receivable amount")
+	}
+
+	if amount > approved {
+		return c, fmt.Errorf("Receivable amount %.2f exceeds the approved amount %.2f", amount, approved)
+	}
+
+	if amount < c.MinAmount || (c.MaxAmount > 0 && amount > c.MaxAmount) {
+		return c, fmt.Errorf("Receivable amount %.2f is outside the %s limits", amount, c.Channel)
+	}
+
+	if len(c.ProgramTypes) > 0 {
+		allowed := false
+		for _, programType := range c.ProgramTypes {
+			if programType == v.ProgramType {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return c, errors.New(c.Channel + " cannot be used for programs of type " + v.ProgramType)
+		}
+	}
+
+	return c, nil
+}
+
+//=================================================================================================================================
+//	 Segregation of Duties Functions
+//=================================================================================================================================
+//	 retrieve_sod_rules - Gets the segregation of duties rules. Every rule is on until an admin changes them.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) retrieve_sod_rules(stub shim.ChaincodeStubInterface) (SoDRules, error) {
+
+	rules := SoDRules{DistinctMakerChecker: true, DistinctAnchorVendor: true, NoSelfApprovedRevisions: true}
+
+	bytes, err := stub.GetState("sodRules")
+	if err != nil {
+		return rules, errors.New("Unable to get sodRules")
+	}
+
+	if len(bytes) != 0 {
+		err = json.Unmarshal(bytes, &rules)
+		if err != nil {
+			return rules, errors.New("Corrupt sodRules record")
+		}
+	}
+
+	return rules, nil
+}
+
+//=================================================================================================================================
+//	 update_sod_rules - Changes the segregation of duties rules to those in the JSON passed. Rules the JSON leaves out
+//						keep their current setting.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_sod_rules(stub shim.ChaincodeStubInterface, callerAccount []byte, raw string) ([]byte, error) {
+
+	rules, err := t.retrieve_sod_rules(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(raw), &rules)
+	if err != nil {
+		return nil, errors.New("Invalid segregation of duties rules, expected JSON")
+	}
+
+	rules.UpdatedBy = string(callerAccount)
+	rules.UpdatedAt, err = tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := json.Marshal(rules)
+	if err != nil {
+		return nil, errors.New("Error converting sodRules")
+	}
+
+	err = stub.PutState("sodRules", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 check_segregation - Checks a call against the segregation of duties rules. x is the invoice the call acts on, if
+//						 any, and receiverAccount the recipient of a transfer. A delegate is held to the rules both as
+//						 itself and as the principal it acts for.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) check_segregation(stub shim.ChaincodeStubInterface, request PolicyRequest, v AnchorProgram, x MyBoxItem, receiverAccount string) error {
+
+	rules, err := t.retrieve_sod_rules(stub)
+	if err != nil {
+		return err
+	}
+
+	err = segregation(rules, request, request.CallerAccount, v, x, receiverAccount)
+	if err == nil && request.Delegate != "" {
+		err = segregation(rules, request, request.Delegate, v, x, receiverAccount)
+	}
+
+	return err
+}
+
+func segregation(rules SoDRules, request PolicyRequest, caller string, v AnchorProgram, x MyBoxItem, receiverAccount string) error {
+
+	approval := request.Function == "update_anchor_invoice_authorized_amount" ||
+		request.Function == "update_checker_invoice_approval" ||
+		request.Function == "update_checker_payment_batch" ||
+		request.Function == "update_checker_invoice_payment" ||
+		request.Function == "update_checker_invoice_settlement"
+
+	if rules.DistinctMakerChecker {
+
+		if approval && request.Function != "update_anchor_invoice_authorized_amount" && x.PaymentMaker == caller {
+			return errors.New("Segregation of duties: the payment maker of an invoice cannot also check its payment")
+		}
+
+		if (request.Function == "transfer_payment_maker_to_payment_checker_invoice" || request.Function == "transfer_payment_maker_to_payment_checker_batch") && receiverAccount == caller {
+			return errors.New("Segregation of duties: the payment maker cannot send a payment to themselves for checking")
+		}
+
+		if request.Function == "update_maker_invoice_payment" {
+			for _, checker := range x.Approvals {
+				if checker.Account == caller {
+					return errors.New("Segregation of duties: a payment checker of an invoice cannot also make its payment")
+				}
+			}
+		}
+	}
+
+	if rules.DistinctAnchorVendor {
+
+		anchor_vendor := (request.CallerRole == ROLE_ANCHOR && request.RecipientRole == ROLE_VENDOR) ||
+			(request.CallerRole == ROLE_VENDOR && request.RecipientRole == ROLE_ANCHOR)
+
+		if anchor_vendor && receiverAccount == caller {
+			return errors.New("Segregation of duties: the anchor and vendor must be different accounts")
+		}
+
+		if anchor_vendor && request.CallerOrg != "" && request.CallerOrg == request.RecipientOrg {
+			return errors.New("Segregation of duties: the anchor and vendor must be different organizations")
+		}
+
+		if request.Function == "anchor_to_vendor" && v.POraisedBy != "" && receiverAccount == v.POraisedBy {
+			return errors.New("Segregation of duties: the vendor cannot be the account that raised the purchase order")
+		}
+
+		if request.Function == "admin_to_anchor" && v.PORaisedAgainst != "" && receiverAccount == v.PORaisedAgainst {
+			return errors.New("Segregation of duties: the anchor cannot be the program's vendor")
+		}
+
+		if request.Function == "update_vendor_create_invoice" && v.POraisedBy == caller {
+			return errors.New("Segregation of duties: the anchor cannot raise invoices against its own purchase order")
+		}
+	}
+
+	if rules.NoSelfApprovedRevisions && approval && x.RevisedBy != "" && x.RevisedBy == caller {
+		return errors.New("Segregation of duties: a revision cannot be approved by the account that revised it")
+	}
+
+	return nil
+}
+
+//=================================================================================================================================
+//	 Delegation Functions
+//=================================================================================================================================
+//	 create_delegation - Lets delegate act for principal in role between two dates (YYYY-MM-DD, inclusive), optionally
+//						 only on the programs listed as JSON. A principal may delegate their own role; delegating for
+//						 someone else needs the create_delegation_on_behalf policy rule. The delegate must be registered
+//						 in the role, and may not hold the opposite side of a maker/checker pair.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) create_delegation(stub shim.ChaincodeStubInterface, request PolicyRequest, id, principal, delegate, role, from, to, programs string) ([]byte, error) {
+
+	if principal != request.CallerAccount {
+		request.Function = "create_delegation_on_behalf"
+		err := t.authorize(stub, request)
+		if err != nil {
+			return nil, err
+		}
+	} else if role != request.CallerRole {
+		return nil, errors.New("A principal can only delegate their own role")
+	}
+
+	if id == "" || principal == "" || delegate == "" || role == "" {
+		return nil, errors.New("A delegation needs an ID, principal, delegate and role")
+	}
+
+	if principal == delegate {
+		return nil, errors.New("An account cannot delegate to itself")
+	}
+
+	p, err := t.retrieve_participant(stub, delegate)
+	if err != nil || p.Status != PARTICIPANT_ACTIVE {
+		return nil, errors.New("Delegate " + delegate + " is not an active registered participant")
+	}
+
+	if !contains(p.Roles, role) {
+		return nil, errors.New("Delegate " + delegate + " is not registered in role " + role)
+	}
+
+	rules, err := t.retrieve_sod_rules(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	if rules.DistinctMakerChecker &&
+		((role == ROLE_PAYMENT_CHECKER && contains(p.Roles, ROLE_PAYMENT_MAKER)) ||
+			(role == ROLE_PAYMENT_MAKER && contains(p.Roles, ROLE_PAYMENT_CHECKER))) {
+		return nil, errors.New("Segregation of duties: a payment maker cannot be given a payment checker's rights, or the reverse")
+	}
+
+	_, err = time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil, errors.New("Invalid from date, expected YYYY-MM-DD")
+	}
+
+	_, err = time.Parse("2006-01-02", to)
+	if err != nil || to < from {
+		return nil, errors.New("Invalid to date, expected YYYY-MM-DD on or after the from date")
+	}
+
+	record, err := stub.GetState("delegation_" + id)
+	if record != nil {
+		return nil, errors.New("Delegation already exists")
+	}
+
+	var d Delegation
+
+	d.DelegationID = id
+	d.Principal = principal
+	d.Delegate = delegate
+	d.Role = role
+	d.From = from
+	d.To = to
+	d.CreatedBy = request.CallerAccount
+
+	if programs != "" {
+		err = json.Unmarshal([]byte(programs), &d.Programs)
+		if err != nil {
+			return nil, errors.New("Invalid programs, expected a JSON list of anchor program IDs")
+		}
+	}
+
+	d.CreatedAt, err = tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = t.save_delegation(stub, d)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := stub.GetState("delegations_" + principal)
+	if err != nil {
+		return nil, errors.New("Unable to get delegations")
+	}
+
+	var holder Delegation_Holder
+
+	if len(bytes) != 0 {
+		err = json.Unmarshal(bytes, &holder)
+		if err != nil {
+			return nil, errors.New("Corrupt Delegation_Holder record")
+		}
+	}
+
+	holder.DELEGATIONs = append(holder.DELEGATIONs, id)
+
+	bytes, err = json.Marshal(holder)
+	if err != nil {
+		return nil, errors.New("Error creating Delegation_Holder record")
+	}
+
+	err = stub.PutState("delegations_"+principal, bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 revoke_delegation - Ends a delegation. Open to its principal and delegate, and to others through the
+//						 revoke_delegation_on_behalf policy rule.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) revoke_delegation(stub shim.ChaincodeStubInterface, request PolicyRequest, id string) ([]byte, error) {
+
+	d, err := t.retrieve_delegation(stub, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Principal != request.CallerAccount && d.Delegate != request.CallerAccount {
+		request.Function = "revoke_delegation_on_behalf"
+		err = t.authorize(stub, request)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if d.Revoked {
+		return nil, errors.New("Delegation already revoked")
+	}
+
+	d.Revoked = true
+	d.RevokedBy = request.CallerAccount
+	d.RevokedAt, err = tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = t.save_delegation(stub, d)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 retrieve_delegation / save_delegation - Read and write a Delegation at "delegation_<id>"
+//=================================================================================================================================
+func (t *AssetManagementChaincode) retrieve_delegation(stub shim.ChaincodeStubInterface, id string) (Delegation, error) {
+
+	var d Delegation
+
+	bytes, err := stub.GetState("delegation_" + id)
+	if err != nil || len(bytes) == 0 {
+		return d, errors.New("No delegation with ID " + id)
+	}
+
+	err = json.Unmarshal(bytes, &d)
+	if err != nil {
+		return d, errors.New("Corrupt delegation record " + id)
+	}
+
+	return d, nil
+}
+
+func (t *AssetManagementChaincode) save_delegation(stub shim.ChaincodeStubInterface, d Delegation) (bool, error) {
+
+	bytes, err := json.Marshal(d)
+	if err != nil {
+		return false, errors.New("Error converting Delegation record")
+	}
+
+	err = stub.PutState("delegation_"+d.DelegationID, bytes)
+	if err != nil {
+		return false, errors.New("Error storing Delegation record")
+	}
+
+	return true, nil
+}
+
+//=================================================================================================================================
+//	 active_delegation - Finds a delegation letting delegate act for principal on the program today, if there is one.
+//						 A delegation limited to a program also covers its revisions.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) active_delegation(stub shim.ChaincodeStubInterface, principal, delegate, programID string) (Delegation, bool, error) {
+
+	var found Delegation
+
+	bytes, err := stub.GetState("delegations_" + principal)
+	if err != nil || len(bytes) == 0 {
+		return found, false, nil
+	}
+
+	var holder Delegation_Holder
+
+	err = json.Unmarshal(bytes, &holder)
+	if err != nil {
+		return found, false, errors.New("Corrupt Delegation_Holder record")
+	}
+
+	now, err := tx_timestamp(stub)
+	if err != nil {
+		return found, false, err
+	}
+	today := now[:10]
+
+	for _, id := range holder.DELEGATIONs {
+
+		d, err := t.retrieve_delegation(stub, id)
+		if err != nil {
+			return found, false, err
+		}
+
+		if d.Revoked || d.Delegate != delegate || today < d.From || today > d.To {
+			continue
+		}
+
+		covered := len(d.Programs) == 0
+		for _, p := range d.Programs {
+			if programID == p || strings.HasPrefix(programID, p+"-R") {
+				covered = true
+			}
+		}
+
+		if covered {
+			return d, true, nil
+		}
+	}
+
+	return found, false, nil
+}
+
+//=================================================================================================================================
+//	 record_if_changed - Records an authorised call in the action log of its program or invoice if the call changed the
+//						 record; calls that are turned away or leave the record as it was are not logged
+//=================================================================================================================================
+func (t *AssetManagementChaincode) record_if_changed(stub shim.ChaincodeStubInterface, recordID string, before []byte, action ActionRecord) error {
+
+	after, err := stub.GetState(recordID)
+	if err != nil {
+		return errors.New("Unable to get " + recordID)
+	}
+
+	if bytes.Equal(before, after) {
+		return nil
+	}
+
+	var record struct {
+		MOID            string      `json:"moID"`
+		InvoiceDocument DocumentRef `json:"invoicedocument"`
+		PoDocument      DocumentRef `json:"anchorpodocument"`
+	}
+
+	err = json.Unmarshal(after, &record)
+	if err != nil {
+		return errors.New("Corrupt record " + recordID)
+	}
+
+	// Programs and invoices share the key space, an invoice record is the one that carries its own ID in moID
+	if record.MOID == recordID {
+		action.Document = record.InvoiceDocument
+	} else {
+		action.Document = record.PoDocument
+	}
+
+	return t.record_action(stub, recordID, action)
+}
+
+//=================================================================================================================================
+//	 record_action - Appends to the action log of a program or invoice at "actions_<id>"
+//=================================================================================================================================
+func (t *AssetManagementChaincode) record_action(stub shim.ChaincodeStubInterface, recordID string, action ActionRecord) error {
+
+	var actions []ActionRecord
+
+	bytes, err := stub.GetState("actions_" + recordID)
+	if err != nil {
+		return errors.New("Unable to get action log")
+	}
+
+	if len(bytes) != 0 {
+		err = json.Unmarshal(bytes, &actions)
+		if err != nil {
+			return errors.New("Corrupt action log for " + recordID)
+		}
+	}
+
+	action.TxID = stub.GetTxID()
+	action.At, err = tx_timestamp(stub)
+	if err != nil {
+		return err
+	}
+
+	actions = append(actions, action)
+
+	bytes, err = json.Marshal(actions)
+	if err != nil {
+		return errors.New("Error converting action log")
+	}
+
+	return stub.PutState("actions_"+recordID, bytes)
+}
+
+//=================================================================================================================================
+//	 register_pii_key - Records the check value of the PII key held by the peers, see local_key. Done once by the admin
+//						before any personal or bank details are entered. The check value is worked out by the client,
+//						see cmd/seal -check, so registering does not depend on which peers hold the key.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) register_pii_key(stub shim.ChaincodeStubInterface, check string) ([]byte, error) {
+
+	decoded, err := hex.DecodeString(check)
+	if err != nil || len(decoded) != sha256.Size || check != strings.ToLower(check) {
+		return nil, errors.New("Invalid key check value, expected the hex output of cmd/seal -check")
+	}
+
+	existing, err := stub.GetState("piiKeyCheck")
+	if err != nil {
+		return nil, errors.New("Unable to get piiKeyCheck")
+	}
+	if len(existing) != 0 {
+		return nil, errors.New("A PII key has already been registered")
+	}
+
+	err = stub.PutState("piiKeyCheck", []byte(check))
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	return nil, nil
+}
+
+//=================================================================================================================================
+//	 migrate_pii_fields - Rewrites personal and bank details stored before values were sealed by the client. Values in the
+//						  older PII_PREFIX form lose the mask they carried in the clear. Values stored in the clear are
+//						  replaced by the sealed values the admin supplies, made with cmd/seal, as JSON keyed by record
+//						  and field, e.g. {"AP1":{"vendorpanno":"enc:v2:..."},"kyc_V1":{"kyc_PAN":"enc:v2:..."}}, where
+//						  the record is the program ID, an invoice's MOID or kyc_<vendorID>. Nothing is opened or sealed
+//						  by the chaincode, so every peer writes the same. Returns the record|field of each value still
+//						  in the clear. Safe to run more than once.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) migrate_pii_fields(stub shim.ChaincodeStubInterface, sealed string) ([]byte, error) {
+
+	replacements := make(map[string]map[string]string)
+
+	if sealed != "" {
+		err := json.Unmarshal([]byte(sealed), &replacements)
+		if err != nil {
+			return nil, errors.New("Invalid sealed values, expected JSON keyed by record and field")
+		}
+	}
+
+	clear := []string{}
+
+	var programIDs Anchor_Program_Holder
+
+	bytes, err := stub.GetState("anchorProgramIDs")
+	if err == nil && len(bytes) != 0 {
+		err = json.Unmarshal(bytes, &programIDs)
+	}
+	if err != nil {
+		return nil, errors.New("Corrupt Anchor_Program_Holder record")
+	}
+
+	for _, id := range programIDs.ANCHOR_PROGRAMs {
+
+		v, err := t.retrieve_anchorprogram(stub, id)
+		if err != nil {
+			return nil, err
+		}
+
+		changed, err := migrate_fields(v.AnchorProgramID, program_pii_fields(&v), replacements, &clear)
+		if err != nil {
+			return nil, err
+		}
+		for i := range v.Items {
+			item_changed, err := migrate_fields(v.Items[i].MOID, invoice_pii_fields(&v.Items[i]), replacements, nil)
+			if err != nil {
+				return nil, err
+			}
+			changed = changed || item_changed
+		}
+		if changed {
+			_, err = t.save_changes(stub, v)
+			if err != nil {
+				return nil, errors.New("Error saving changes")
+			}
+		}
+	}
+
+	var invoiceIDs Invoice_Holder
+
+	bytes, err = stub.GetState("invoiceIDs")
+	if err == nil && len(bytes) != 0 {
+		err = json.Unmarshal(bytes, &invoiceIDs)
+	}
+	if err != nil {
+		return nil, errors.New("Corrupt Invoice_Holder")
+	}
+
+	for _, moid := range invoiceIDs.INVOICEs {
+
+		x, err := t.retrieve_invoice(stub, moid)
+		if err != nil {
+			return nil, err
+		}
+
+		changed, err := migrate_fields(x.MOID, invoice_pii_fields(&x), replacements, &clear)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			_, err = t.save_invoice(stub, x)
+			if err != nil {
+				return nil, errors.New("Error saving invoice")
+			}
+		}
+	}
+
+	var kycIDs KYC_Holder
+
+	bytes, err = stub.GetState("kycIDs")
+	if err == nil && len(bytes) != 0 {
+		err = json.Unmarshal(bytes, &kycIDs)
+	}
+	if err != nil {
+		return nil, errors.New("Corrupt KYC_Holder")
+	}
+
+	for _, vendorID := range kycIDs.VENDORs {
+
+		r, err := t.retrieve_kyc(stub, vendorID)
+		if err != nil {
+			return nil, err
+		}
+
+		var fields []PIIField
+		for i := range r.Items {
+			fields = append(fields, PIIField{Name: "kyc_" + r.Items[i].ItemType, Value: &r.Items[i].Data})
+		}
+
+		changed, err := migrate_fields("kyc_"+vendorID, fields, replacements, &clear)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			_, err = t.save_kyc(stub, r)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return json.Marshal(clear)
+}
+
+//=================================================================================================================================
+//	 migrate_fields - Brings each field of a record to the PII_SEALED form where it can, reporting whether any changed.
+//					  Fields left in the clear are added to clear, unless it is nil because the record is a copy.
+//=================================================================================================================================
+func migrate_fields(record string, fields []PIIField, replacements map[string]map[string]string, clear *[]string) (bool, error) {
+
+	changed := false
+
+	for _, f := range fields {
+
+		value := *f.Value
+
+		if value == "" || value == "UNDEFINED" || strings.HasPrefix(value, PII_SEALED) {
+			continue
+		}
+
+		if strings.HasPrefix(value, PII_PREFIX) { // Same nonce, ciphertext and additional data, only the mask goes
+			*f.Value = PII_SEALED + value[strings.LastIndex(value, ":")+1:]
+		} else if replacement, ok := replacements[record][f.Name]; ok {
+			if !sealed_envelope(replacement) {
+				return false, errors.New(record + " " + f.Name + " must be sealed by the client before it is sent")
+			}
+			*f.Value = replacement
+		} else {
+			if clear != nil {
+				*clear = append(*clear, record+"|"+f.Name)
+			}
+			continue
+		}
+
+		changed = true
+	}
+
+	return changed, nil
+}
+
+//=================================================================================================================================
+//	 Create Function
+//=================================================================================================================================
+//	 Create AnchorProgram - Creates the initial JSON for the order and then saves it to the ledger.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) create_anchorprogram(stub shim.ChaincodeStubInterface, callerAccount []byte, caller_affiliation string, anchorprogramID string) ([]byte, error) {
+	var v AnchorProgram
+
+	v.AnchorProgramID = anchorprogramID
+	v.Owner = string(callerAccount)
+
+	/*matched, err := regexp.Match("^[A-z][A-z][0-9]{7}", []byte(anchorprogramID)) // matched = true if the poID passed fits format of two letters followed by seven digits
+	if err != nil {
+		fmt.Printf("CREATE_ANCHORPROGRAM: Invalid anchorprogramID: %s", err)
+		return nil, errors.New("Invalid anchorprogramID")
+	}*/
+
+	if v.AnchorProgramID == "" { /*||
+		matched == false */
+		fmt.Printf("CREATE_ANCHORPROGRAM: Invalid anchorprogramID provided")
+		return nil, errors.New("Invalid poID provided")
+	}
+
+	record, err := stub.GetState(v.AnchorProgramID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
+	if record != nil {
+		return nil, errors.New("AnchorProgram already exists")
+	}
+
+	_, err = t.save_changes(stub, v)
+	if err != nil {
+		fmt.Printf("CREATE_ANCHORPROGRAM: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	bytes, err := stub.GetState("anchorProgramIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get anchorProgramIDs")
+	}
+
+	var anchorProgramIDs Anchor_Program_Holder
+
+	err = json.Unmarshal(bytes, &anchorProgramIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Anchor_Program_Holder record")
+	}
+
+	anchorProgramIDs.ANCHOR_PROGRAMs = append(anchorProgramIDs.ANCHOR_PROGRAMs, anchorprogramID)
+
+	bytes, err = json.Marshal(anchorProgramIDs)
+	if err != nil {
+		fmt.Print("Error creating Anchor_Program_Holder record")
+	}
+
+	err = stub.PutState("anchorProgramIDs", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	//	myLogger.Debugf("New owner of [%s] is [% x]", v, v.Owner)
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 Create Invoice - Creates the initial JSON for the invoice and then saves it to the ledger.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_vendor_create_invoice(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, id string) ([]byte, error) {
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		v.Owner == string(callerAccount) &&
+		//v.POAcknowledged == true &&
+		v.Settled == false { // If the roles and users are ok
+
+		var item MyBoxItem
+
+		//	id := randStr(9, "alphanum")
+		item.POID = v.AnchorProgramID
+		item.MOID = id
+		item.AnchorName = v.AnchorName
+		item.AnchorAccountNo = v.AnchorAccountNo
+		item.AnchorPOAmount = v.AnchorPOAmount
+		item.AnchorIFSCCode = v.AnchorIFSCCode
+		if v.TermsHash == "" { // Private terms are filled in when the invoice is read
+			item.AnchorInterest = v.AnchorInterest
+		}
+		item.Vendorfname = v.VendorFName
+		item.Vendorbank = v.Vendorbank
+		item.Vendorifsccode = v.Vendorifsccode
+		item.AnchorPoID = v.AnchorPoID
+
+		item.MOOwner = string(callerAccount)
+		item.InvoiceRaisedBy = string(callerAccount)
+
+		/*matched, err := regexp.Match("^[A-z][A-z][0-9]{7}", []byte(id)) // matched = true if the poID passed fits format of two letters followed by seven digits
+		if err != nil {
+			fmt.Printf("CREATE_INVOICE: Invalid InvoiceID: %s", err)
+			return nil, errors.New("Invalid InvoiceID")
+		}*/
+
+		if item.MOID == "" {
+			fmt.Printf("CREATE_INVOICE: Invalid InvoiceID provided")
+			return nil, errors.New("Invalid moID provided")
+		}
+
+		record, err := stub.GetState(item.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
+		if record != nil {
+			return nil, errors.New("Invoice already exists")
+		}
+
+		_, err = t.save_invoice(stub, item)
+		if err != nil {
+			fmt.Printf("CREATE_INVOICE: Error saving changes: %s", err)
+			return nil, errors.New("Error saving changes")
+		}
+
+		bytes, err := stub.GetState("invoiceIDs")
+		if err != nil {
+			return nil, errors.New("Unable to get invoiceIDs")
+		}
+
+		var invoiceIDs Invoice_Holder
+
+		err = json.Unmarshal(bytes, &invoiceIDs)
+		if err != nil {
+			return nil, errors.New("Corrupt Invoice_Holder record")
+		}
+
+		invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, id)
+
+		bytes, err = json.Marshal(invoiceIDs)
+		if err != nil {
+			fmt.Print("Error creating Invoice_Holder record")
+		}
+
+		err = stub.PutState("invoiceIDs", bytes)
+		if err != nil {
+			return nil, errors.New("Unable to put the state")
+		}
+
+		v.Items = append(v.Items, (item))
+
+	} else { // Otherwise if there is an error
+
+		fmt.Printf("CREATE_INVOICE: Permission Denied")
+		return nil, errors.New("Permission Denied")
+
+	}
+	_, err := t.save_changes(stub, v)
+	if err != nil {
+		fmt.Printf("CREATE_INVOICE: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes to Anchor Program")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 Transfer Functions
+//=================================================================================================================================
+//	 admin_to_anchor
+//=================================================================================================================================
+func (t *AssetManagementChaincode) admin_to_anchor(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
+	if !v.terms_loaded {
+		return nil, errors.New("The key for collection " + v.TermsCollection + " must be supplied to check the commercial terms")
+	}
+
+	if v.VendorFName == "UNDEFINED" ||
+		v.VendorLName == "UNDEFINED" ||
+		v.Vendorphone == "UNDEFINED" ||
+		v.Vendoraddress == "UNDEFINED" ||
+		v.Vendoremail == "UNDEFINED" ||
+		v.Vendorlimit == 0 ||
+		v.AnchorName == "UNDEFINED" ||
+		v.AnchorID == "UNDEFINED" ||
+		v.AnchorAccountNo == "UNDEFINED" ||
+		v.AnchorIFSCCode == "UNDEFINED" ||
+		v.AnchorLimit == 0 ||
+		v.AnchorExpiryDate == "UNDEFINED" ||
+		v.AnchorInterest == "UNDEFINED" ||
+		v.AnchorGarceInterest == "UNDEFINED" ||
+		v.AnchorGarceInterestperiod == "UNDEFINED" ||
+		v.AnchorPenalInterest == "UNDEFINED" ||
+		v.AnchorLiquidation == "UNDEFINED" { //If any part of the order is undefined it has not been fully manufacturered so cannot be sent
+
+		fmt.Printf("ADMIN_TO_ANCHOR: AnchorProgram not fully defined")
+
+		return nil, errors.New("AnchorProgram not fully defined")
+	}
+
+	err := t.kyc_verified(stub, v.VendorID)
+	if err != nil {
+		fmt.Printf("ADMIN_TO_ANCHOR: %s", err)
+		return nil, err
+	}
+
+	// Verify the identity of the caller
+	// Only the owner can transfer one of his assets
+
+	prvOwner := []byte(v.Owner)
+
+	// Verify ownership
+
+	if bytes.Compare(prvOwner, callerAccount) != 0 {
+		return nil, fmt.Errorf("Failed verifying caller ownership.")
+	}
+
+	if v.Status == STATE_TEMPLATE &&
+		v.Settled == false { // If the roles and users are ok
+
+		v.Owner = receiverAccount          // then make the owner the new owner
+		v.Status = STATE_PROGRAM_INITIATED // and mark it in the state of creating purchase order
+		v.POraisedBy = receiverAccount
+
+	} else { // Otherwise if there is an error
+
+		fmt.Printf("ADMIN_TO_ANCHOR: Permission Denied")
+		return nil, errors.New("Permission Denied")
+
+	}
+
+	_, err = t.charge_program_fees(stub, &v, FEE_ONBOARDING, callerAccount) // The program is now active
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = t.save_changes(stub, v) // Write new state
+
+	if err != nil {
+		fmt.Printf("ADMIN_TO_ANCHOR: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	return nil, nil // We are Done
+
+}
+
+//=================================================================================================================================
+//	 anchor_to_admin_rev
+//=================================================================================================================================
+func (t *AssetManagementChaincode) anchor_to_admin_rev(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string, new_value string) ([]byte, error) {
+
+	// Verify the identity of the caller
+	// Only the owner can transfer one of his assets
+
+	prvOwner := []byte(v.Owner)
+
+	// Verify ownership
+
+	if bytes.Compare(prvOwner, callerAccount) != 0 {
+		return nil, fmt.Errorf("Failed verifying caller ownership.")
+	}
+
+	var pobox AnchorProgram
+	pobox = v
+
+	if v.Status == STATE_PROGRAM_INITIATED &&
+		v.Settled == false { // If the roles and users are ok
+
+		pobox.Owner = receiverAccount // then make the owner the new owner
+		pobox.Status = STATE_TEMPLATE // and mark it in the state of creating purchase order
+		pobox.AnchorProgramID = v.AnchorProgramID + "-R1"
+		pobox.AnchorPOAmount = 0 // Update to the new value
+		pobox.AnchorPOQuantity = 0
+		pobox.AnchorPoDocument = DocumentRef{}
+		pobox.AnchorPoImage = ""
+		pobox.AnchorPoID = "UNDEFINED"
+		pobox.GoodsReceipts = nil
+		pobox.POParent = v.AnchorProgramID
+		pobox.PORemarks = new_value
+		v.PoForks = append(v.PoForks, pobox.AnchorProgramID)
+
+		record, _ := stub.GetState(pobox.AnchorProgramID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
+		if record != nil {
+			return nil, errors.New("AnchorProgramID already exists")
+		}
+
+	} else { // Otherwise if there is an error
+
+		fmt.Printf("ANCHOR_TO_ADMIN_REV: Permission Denied")
+		return nil, errors.New("Permission Denied")
+
+	}
+
+	_, err := t.save_changes(stub, pobox) // Write new state
+
+	if err != nil {
+		fmt.Printf("ANCHOR_TO_ADMIN_REV: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	_, errs := t.save_changes(stub, v) // Write new state
+
+	if errs != nil {
+		fmt.Printf("ANCHOR_TO_ADMIN_REV: Error saving changes: %s", errs)
+		return nil, errors.New("Error saving changes")
+	}
+
+	bytes, _ := stub.GetState("anchorProgramIDs")
+
+	var anchorProgramIDs Anchor_Program_Holder
+
+	err = json.Unmarshal(bytes, &anchorProgramIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Anchor_Program_Holder record")
+	}
+
+	anchorProgramIDs.ANCHOR_PROGRAMs = append(anchorProgramIDs.ANCHOR_PROGRAMs, pobox.AnchorProgramID)
+
+	bytes, err = json.Marshal(anchorProgramIDs)
+	if err != nil {
+		fmt.Print("Error creating Anchor_Program_Holder record")
+	}
+
+	err = stub.PutState("anchorProgramIDs", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	return nil, nil // We are Done
+
+}
+
+//=================================================================================================================================
+//	 anchor_to_vendor
+//=================================================================================================================================
+func (t *AssetManagementChaincode) anchor_to_vendor(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
+
+	if v.AnchorPOAmount == 0 ||
+		!has_po_document(v) ||
+		v.AnchorPoID == "UNDEFINED" ||
+		v.VendorID == "UNDEFINED" ||
+		v.VendorFName == "UNDEFINED" ||
+		v.VendorLName == "UNDEFINED" ||
+		v.Vendorphone == "UNDEFINED" ||
+		v.Vendoraddress == "UNDEFINED" ||
+		v.Vendoremail == "UNDEFINED" ||
+		v.Vendorlimit == 0 { //If any part of the order is undefined it has not been fully manufacturered so cannot be sent
+
+		fmt.Printf("ANCHOR_TO_VENDOR: AnchorProgram not fully defined")
+		return nil, errors.New("AnchorProgram not fully defined")
+	}
+
+	err := t.kyc_verified(stub, v.VendorID)
+	if err != nil {
+		fmt.Printf("ANCHOR_TO_VENDOR: %s", err)
+		return nil, err
+	}
+
+	if v.Status == STATE_PROGRAM_INITIATED &&
+		v.Owner == string(callerAccount) &&
+		v.Settled == false { // If the roles and users are ok
+
+		v.Owner = receiverAccount              // then make the owner the new owner
+		v.Status = STATE_PURCHASE_ORDER_PLACED // and mark it in the state of creating purchase order
+		v.PORaisedAgainst = receiverAccount
+		//v.POTimestamp = time.Now()
+	} else { // Otherwise if there is an error
+
+		fmt.Printf("ANCHOR_TO_VENDOR: Permission Denied")
+		return nil, errors.New("Permission Denied")
+
+	}
+
+	_, err = t.save_changes(stub, v) // Write new state
+
+	if err != nil {
+		fmt.Printf("ANCHOR_TO_VENDOR: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	return nil, nil // We are Done
+
+}
+
+//=================================================================================================================================
+//	 vendor_to_anchor_rev
+//=================================================================================================================================
+func (t *AssetManagementChaincode) vendor_to_anchor_rev(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string, new_value string) ([]byte, error) {
+
+	var pobox AnchorProgram
+	pobox = v
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		v.Owner == string(callerAccount) &&
+		v.Settled == false { // If the roles and users are ok
+
+		pobox.Owner = receiverAccount          // then make the owner the new owner
+		pobox.Status = STATE_PROGRAM_INITIATED // and mark it in the state of creating purchase order
+		pobox.AnchorProgramID = v.AnchorProgramID + "-R2"
+		pobox.AnchorPOAmount = 0 // Update to the new value
+		pobox.AnchorPOQuantity = 0
+		pobox.AnchorPoDocument = DocumentRef{}
+		pobox.AnchorPoImage = ""
+		pobox.AnchorPoID = "UNDEFINED"
+		pobox.GoodsReceipts = nil
+		pobox.POParent = v.AnchorProgramID
+		pobox.PORemarks = new_value
+		v.PoForks = append(v.PoForks, pobox.AnchorProgramID)
+
+		record, _ := stub.GetState(pobox.AnchorProgramID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
+		if record != nil {
+			return nil, errors.New("AnchorProgramID already exists")
+		}
+
+	} else { // Otherwise if there is an error
+
+		fmt.Printf("VENDOR_TO_ANCHOR_REV: Permission Denied")
+		return nil, errors.New("Permission Denied")
+
+	}
+
+	_, err := t.save_changes(stub, pobox) // Write new state
+
+	if err != nil {
+		fmt.Printf("VENDOR_TO_ANCHOR_REV: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	_, errs := t.save_changes(stub, v) // Write new state
+
+	if errs != nil {
+		fmt.Printf("VENDOR_TO_ANCHOR_REV: Error saving changes: %s", errs)
+		return nil, errors.New("Error saving changes")
+	}
+
+	bytes, _ := stub.GetState("anchorProgramIDs")
+
+	var anchorProgramIDs Anchor_Program_Holder
+
+	err = json.Unmarshal(bytes, &anchorProgramIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Anchor_Program_Holder record")
+	}
+
+	anchorProgramIDs.ANCHOR_PROGRAMs = append(anchorProgramIDs.ANCHOR_PROGRAMs, pobox.AnchorProgramID)
+
+	bytes, err = json.Marshal(anchorProgramIDs)
+	if err != nil {
+		fmt.Print("Error creating Anchor_Program_Holder record")
+	}
+
+	err = stub.PutState("anchorProgramIDs", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	return nil, nil // We are Done
+
+}
+
+//==========================================================================================================
+//transfer_vendor_to_anchor_invoice
+//==========================================================================================================
+func (t *AssetManagementChaincode) transfer_vendor_to_anchor_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
+
+	if x.InvoiceID == "UNDEFINED" ||
+		!has_invoice_document(x) ||
+		x.MOAmount == 0 { //If any part of the order is undefined it has not been fully manufacturered so cannot be sent
+
+		fmt.Printf("VENDOR_TO_ANCHOR_INVOICE: Invoice not fully defined")
+		return nil, errors.New("Invoice not fully defined")
+	}
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		v.Owner == string(callerAccount) &&
+		//v.Settled == false &&
+		x.MOStatus == STATE_TEMPLATE &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false &&
+		v.Settled == false {
+
+		for i := range v.Items {
+			if x.MOID == v.Items[i].MOID {
+
+				x.MOOwner = receiverAccount // then make the owner the new owner
+				v.Items[i].MOOwner = receiverAccount
+
+				x.MOStatus = STATE_INVOICE_RAISED // and mark it in the state of creating purchase order
+				v.Items[i].MOStatus = STATE_INVOICE_RAISED
+
+				x.InvoiceRaisedAgainst = receiverAccount
+				v.Items[i].InvoiceRaisedAgainst = receiverAccount
+
+				//x.MOTimestamp = time.Now()
+				//v.Items[i].MOTimestamp = time.Now()
+
+				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
+					fmt.Println("QUERY: Error retrieving Parent")
+				} else {
+					f, err := t.retrieve_invoice(stub, x.MOParent)
+					if err != nil {
+						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
+						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
+					}
+
+					for j := range v.Items {
+						if f.MOID == v.Items[j].MOID {
+
+							f.MoOriginal = f.MOAmount
+							v.Items[j].MoOriginal = f.MOAmount
+
+							f.MOAmount = 0
+							v.Items[j].MOAmount = 0
+
+							f.MOStatus = STATE_INVOICE_RETIRED
+							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
+
+							break
+						}
+
+					}
+
+					_, errs := t.save_invoice(stub, f)
+
+					if errs != nil {
+						fmt.Printf("VENDOR_TO_ANCHOR_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
+						return nil, errors.New(" %@((@& Error saving changes to invoice")
+					}
+
+				}
+
+				break
+			}
+		}
+	}
+
+	_, err := t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("VENDOR_TO_ANCHOR_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("VENDOR_TO_ANCHOR_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//==========================================================================================================
+//transfer_rev_anchor_to_vendor_invoice
+//==========================================================================================================
+func (t *AssetManagementChaincode) transfer_rev_anchor_to_vendor_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string, new_value string) ([]byte, error) {
+
+	var mobox MyBoxItem
+	mobox = x
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		//v.Owner == string(callerAccount) &&
+		//v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_RAISED &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false &&
+		v.Settled == false {
+
+		mobox.MOID = x.MOID + "-RIN1"
+		mobox.MOOwner = receiverAccount // then make the owner the new owner
+		mobox.MOStatus = STATE_TEMPLATE // and mark it in the state of creating purchase order
+		mobox.InvoiceID = "UNDEFINED"
+		mobox.InvoiceDocument = DocumentRef{}
+		mobox.InvoiceImage = ""
+		mobox.MOAmount = 0
+		mobox.MOParent = x.MOID
+		mobox.RevisedBy = string(callerAccount)
+		mobox.MORemarks = new_value
+		x.MOForks = append(x.MOForks, mobox.MOID)
+
+		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
+		if record != nil {
+			return nil, errors.New("Invoice already exists")
+		}
+
+	}
+
+	_, err := t.save_invoice(stub, mobox)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_REV_ANCHOR_TO_VENDOR_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	bytes, err := stub.GetState("invoiceIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get invoiceIDs")
+	}
+
+	var invoiceIDs Invoice_Holder
+
+	err = json.Unmarshal(bytes, &invoiceIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Invoice_Holder record")
+	}
+
+	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
+
+	bytes, err = json.Marshal(invoiceIDs)
+	if err != nil {
+		fmt.Print("Error creating Invoice_Holder record")
+	}
+
+	err = stub.PutState("invoiceIDs", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	v.Items = append(v.Items, (mobox))
+
+	_, errs := t.save_invoice(stub, x)
+
+	if errs != nil {
+		fmt.Printf("TRANSFER_REV_ANCHOR_TO_VENDOR_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_REV_ANCHOR_TO_VENDOR_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//==========================================================================================================
+//transfer_anchor_to_vendor_invoice
+//==========================================================================================================
+func (t *AssetManagementChaincode) transfer_anchor_to_vendor_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
+
+	if x.InvoiceID == "UNDEFINED" ||
+		!has_invoice_document(x) { //If any part of the order is undefined it has not been fully manufacturered so cannot be sent
+
+		fmt.Printf("ANCHOR_TO_VENDOR_INVOICE: Invoice not fully defined")
+		return nil, errors.New("Invoice not fully defined")
+	}
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		//v.Owner 							 == caller &&
+		//v.Settled == false &&
+		x.MOStatus == STATE_VENDOR_INVOICE_APPROVED &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false &&
+		v.Settled == false {
+
+		for i := range v.Items {
+			if x.MOID == v.Items[i].MOID {
+
+				x.MOOwner = receiverAccount // then make the owner the new owner
+				v.Items[i].MOOwner = receiverAccount
+
+				x.MOStatus = STATE_ANCHOR_AUTHORISED_INVOICE_PAYMENT // and mark it in the state of requesting invoice payment
+				v.Items[i].MOStatus = STATE_ANCHOR_AUTHORISED_INVOICE_PAYMENT
+
+				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
+					fmt.Println("QUERY: Error retrieving Parent")
+				} else {
+					f, err := t.retrieve_invoice(stub, x.MOParent)
+					if err != nil {
+						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
+						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
+					}
+
+					for j := range v.Items {
+						if f.MOID == v.Items[j].MOID {
+
+							f.MOStatus = STATE_INVOICE_RETIRED
+							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
+
+							break
+						}
+
+					}
+
+					_, errs := t.save_invoice(stub, f)
+
+					if errs != nil {
+						fmt.Printf("ANCHOR_TO_VENDOR_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
+						return nil, errors.New(" %@((@& Error saving changes to invoice")
+					}
+
+				}
+
+				break
+			}
+
+		}
+	}
+
+	_, err := t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("ANCHOR_TO_VENDOR_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("ANCHOR_TO_VENDOR_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//==========================================================================================================
+//transfer_rev_vendor_to_anchor_invoice
+//==========================================================================================================
+func (t *AssetManagementChaincode) transfer_rev_vendor_to_anchor_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string, new_value string) ([]byte, error) {
+
+	var mobox MyBoxItem
+	mobox = x
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		//v.Owner 							 == caller &&
+		//v.Settled == false &&
+		x.MOStatus == STATE_ANCHOR_AUTHORISED_INVOICE_PAYMENT &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false &&
+		v.Settled == false {
+
+		mobox.MOID = x.MOID + "-RIN2"
+		mobox.MOOwner = receiverAccount                // then make the owner the new owner
+		mobox.MOStatus = STATE_VENDOR_INVOICE_APPROVED // and mark it in the state of creating purchase order
+
+		mobox.ApprovedInvoiceAmount = 0
+		mobox.MOParent = x.MOID
+		mobox.MORemarks = new_value
+		x.MOForks = append(x.MOForks, mobox.MOID)
+
+		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
+		if record != nil {
+			return nil, errors.New("Invoice already exists")
+		}
+
+	}
+
+	_, err := t.save_invoice(stub, mobox)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_REV_VENDOR_TO_ANCHOR_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	bytes, err := stub.GetState("invoiceIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get invoiceIDs")
+	}
+
+	var invoiceIDs Invoice_Holder
+
+	err = json.Unmarshal(bytes, &invoiceIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Invoice_Holder record")
+	}
+
+	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
+
+	bytes, err = json.Marshal(invoiceIDs)
+	if err != nil {
+		fmt.Print("Error creating Invoice_Holder record")
+	}
+
+	err = stub.PutState("invoiceIDs", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	v.Items = append(v.Items, (mobox))
+
+	_, errs := t.save_invoice(stub, x)
+
+	if errs != nil {
+		fmt.Printf("TRANSFER_REV_VENDOR_TO_ANCHOR_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_REV_VENDOR_TO_ANCHOR_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//==========================================================================================================
+//transfer_vendor_to_admin_invoice
+//==========================================================================================================
+func (t *AssetManagementChaincode) transfer_vendor_to_admin_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
+
+	if x.InvoiceID == "UNDEFINED" ||
+		!has_invoice_document(x) { //If any part of the order is undefined it has not been fully manufacturered so cannot be sent
+
+		fmt.Printf("VENDOR_TO_ADMIN_INVOICE: Invoice not fully defined")
+		return nil, errors.New("Invoice not fully defined")
+	}
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		//v.Owner 							 == caller &&
+		//v.Settled == false &&
+		x.MOStatus == STATE_ANCHOR_AUTHORISED_INVOICE_PAYMENT &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false &&
+		v.Settled == false {
+
+		for i := range v.Items {
+			if x.MOID == v.Items[i].MOID {
+
+				x.MOOwner = receiverAccount // then make the owner the new owner
+				v.Items[i].MOOwner = receiverAccount
+
+				x.MOStatus = STATE_INVOICE_PAYMENT_REQUESTED // and mark it in the state of requesting invoice payment
+				v.Items[i].MOStatus = STATE_INVOICE_PAYMENT_REQUESTED
+
+				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
+					fmt.Println("QUERY: Error retrieving Parent")
+				} else {
+					f, err := t.retrieve_invoice(stub, x.MOParent)
+					if err != nil {
+						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
+						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
+					}
+
+					for j := range v.Items {
+						if f.MOID == v.Items[j].MOID {
+
+							f.MOStatus = STATE_INVOICE_RETIRED
+							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
+
+							break
+						}
+
+					}
+
+					_, errs := t.save_invoice(stub, f)
+
+					if errs != nil {
+						fmt.Printf("VENDOR_TO_ADMIN_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
+						return nil, errors.New(" %@((@& Error saving changes to invoice")
+					}
+
+				}
+
+				break
+			}
+
+		}
+	}
+
+	_, err := t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("VENDOR_TO_ADMIN_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("VENDOR_TO_ADMIN_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//==========================================================================================================
+//transfer_rev_admin_to_vendor_invoice
+//==========================================================================================================
+func (t *AssetManagementChaincode) transfer_rev_admin_to_vendor_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string, new_value string) ([]byte, error) {
+
+	var mobox MyBoxItem
+	mobox = x
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		//v.Owner 							 == caller &&
+		//v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_PAYMENT_REQUESTED &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false &&
+		v.Settled == false {
+
+		mobox.MOID = x.MOID + "-RIN3"
+		mobox.MOOwner = receiverAccount                          // then make the owner the new owner
+		mobox.MOStatus = STATE_ANCHOR_AUTHORISED_INVOICE_PAYMENT // and mark it in the state of creating purchase order
+		mobox.MOParent = x.MOID
+		mobox.RevisedBy = string(callerAccount)
+		mobox.MORemarks = new_value
+		x.MOForks = append(x.MOForks, mobox.MOID)
+
+		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
+		if record != nil {
+			return nil, errors.New("Invoice already exists")
+		}
+
+	}
+
+	_, err := t.save_invoice(stub, mobox)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_REV_ADMIN_TO_VENDOR_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	bytes, err := stub.GetState("invoiceIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get invoiceIDs")
+	}
+
+	var invoiceIDs Invoice_Holder
+
+	err = json.Unmarshal(bytes, &invoiceIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Invoice_Holder record")
+	}
+
+	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
+
+	bytes, err = json.Marshal(invoiceIDs)
+	if err != nil {
+		fmt.Print("Error creating Invoice_Holder record")
+	}
+
+	err = stub.PutState("invoiceIDs", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	v.Items = append(v.Items, (mobox))
+
+	_, errs := t.save_invoice(stub, x)
+
+	if errs != nil {
+		fmt.Printf("TRANSFER_REV_ADMIN_TO_VENDOR_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_REV_ADMIN_TO_VENDOR_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//==========================================================================================================
+//transfer_admin_to_payment_invoice
+//==========================================================================================================
+func (t *AssetManagementChaincode) transfer_admin_to_payment_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
+
+	if x.InvoiceID == "UNDEFINED" ||
+		!has_invoice_document(x) { //If any part of the order is undefined it has not been fully manufacturered so cannot be sent
+
+		fmt.Printf("ANCHOR_TO_ADMIN_INVOICE: Invoice not fully defined")
+		return nil, errors.New("Invoice not fully defined")
+	}
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		//v.Owner 							 == caller &&
+		//v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_PAYMENT_REQUESTED &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false &&
+		v.Settled == false {
+
+		for i := range v.Items {
+			if x.MOID == v.Items[i].MOID {
+
+				x.MOOwner = receiverAccount // then make the owner the new owner
+				v.Items[i].MOOwner = receiverAccount
+
+				x.MOStatus = STATE_INVOICE_PAYMENT_INITIATED // and mark it in the state of requesting invoice payment
+				v.Items[i].MOStatus = STATE_INVOICE_PAYMENT_INITIATED
+
+				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
+					fmt.Println("QUERY: Error retrieving Parent")
+				} else {
+					f, err := t.retrieve_invoice(stub, x.MOParent)
+					if err != nil {
+						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
+						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
+					}
+
+					for j := range v.Items {
+						if f.MOID == v.Items[j].MOID {
+
+							f.MOStatus = STATE_INVOICE_RETIRED
+							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
+
+							break
+						}
+
+					}
+
+					_, errs := t.save_invoice(stub, f)
+
+					if errs != nil {
+						fmt.Printf("TRANSFER_ADMIN_TO_PAYMENT_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
+						return nil, errors.New(" %@((@& Error saving changes to invoice")
+					}
+
+				}
+
+				break
+
+			}
+
+		}
+	}
+
+	_, err := t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_ADMIN_TO_PAYMENT_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_ADMIN_TO_PAYMENT_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//==========================================================================================================
+//transfer_rev_payment_to_admin_invoice
+//==========================================================================================================
+func (t *AssetManagementChaincode) transfer_rev_payment_to_admin_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string, new_value string) ([]byte, error) {
+
+	var mobox MyBoxItem
+	mobox = x
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		//v.Owner 							 == caller &&
+		//v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_PAYMENT_INITIATED &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false &&
+		v.Settled == false {
+
+		mobox.MOID = x.MOID + "-RIN4"
+		mobox.MOOwner = receiverAccount                  // then make the owner the new owner
+		mobox.MOStatus = STATE_INVOICE_PAYMENT_REQUESTED // and mark it in the state of creating purchase order
+		mobox.MOParent = x.MOID
+		mobox.MORemarks = new_value
+		x.MOForks = append(x.MOForks, mobox.MOID)
+
+		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
+		if record != nil {
+			return nil, errors.New("Invoice already exists")
+		}
+
+	}
+
+	_, err := t.save_invoice(stub, mobox)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_REV_PAYMENT_TO_ADMIN_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	bytes, err := stub.GetState("invoiceIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get invoiceIDs")
+	}
+
+	var invoiceIDs Invoice_Holder
+
+	err = json.Unmarshal(bytes, &invoiceIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Invoice_Holder record")
+	}
+
+	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
+
+	bytes, err = json.Marshal(invoiceIDs)
+	if err != nil {
+		fmt.Print("Error creating Invoice_Holder record")
+	}
+
+	err = stub.PutState("invoiceIDs", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	v.Items = append(v.Items, (mobox))
+
+	_, errs := t.save_invoice(stub, x)
+
+	if errs != nil {
+		fmt.Printf("TRANSFER_REV_PAYMENT_TO_ADMIN_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_REV_PAYMENT_TO_ADMIN_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//==========================================================================================================
+//transfer_payment_maker_to_payment_checker_invoice
+//==========================================================================================================
+func (t *AssetManagementChaincode) transfer_payment_maker_to_payment_checker_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
+
+	if x.InvoiceID == "UNDEFINED" ||
+		!has_invoice_document(x) { //If any part of the order is undefined it has not been fully manufacturered so cannot be sent
+
+		fmt.Printf("TRANSFER PAYMENT MAKER TO PAYMENT CHECKER_INVOICE: Invoice not fully defined")
+		return nil, errors.New("Invoice not fully defined")
+	}
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		//v.Owner 							 == caller &&
+		//v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_PAYMENT_INITIATED &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false &&
+		v.Settled == false {
+
+		for i := range v.Items {
+			if x.MOID == v.Items[i].MOID {
+
+				x.MOOwner = receiverAccount // then make the owner the new owner
+				v.Items[i].MOOwner = receiverAccount
+
+				x.MOStatus = STATE_INVOICE_PAYMENT_PENDING_APPROVAL // and mark it in the state of requesting invoice payment
+				v.Items[i].MOStatus = STATE_INVOICE_PAYMENT_PENDING_APPROVAL
+
+				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
+					fmt.Println("QUERY: Error retrieving Parent")
+				} else {
+					f, err := t.retrieve_invoice(stub, x.MOParent)
+					if err != nil {
+						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
+						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
+					}
+
+					for j := range v.Items {
+						if f.MOID == v.Items[j].MOID {
+
+							f.MOStatus = STATE_INVOICE_RETIRED
+							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
+
+							break
+						}
+
+					}
+
+					_, errs := t.save_invoice(stub, f)
+
+					if errs != nil {
+						fmt.Printf("TRANSFER_PAYMENT_MAKER_TO_PAYMENT_CHECKER_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
+						return nil, errors.New(" %@((@& Error saving changes to invoice")
+					}
+
+				}
+
+				break
+
+			}
+
+		}
+	}
+
+	_, err := t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_PAYMENT_MAKER_TO_PAYMENT_CHECKER_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_PAYMENT_MAKER_TO_PAYMENT_CHECKER_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//==========================================================================================================
+//transfer_rev_payment_checker_to_payment_maker_invoice
+//==========================================================================================================
+func (t *AssetManagementChaincode) transfer_rev_payment_checker_to_payment_maker_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string, new_value string) ([]byte, error) {
+
+	var mobox MyBoxItem
+	mobox = x
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		//v.Owner 							 == caller &&
+		//v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_PAYMENT_PENDING_APPROVAL &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false &&
+		v.Settled == false {
+
+		mobox.MOID = x.MOID + "-RIN5"
+		mobox.Approvals = nil
+		mobox.MOOwner = receiverAccount                  // then make the owner the new owner
+		mobox.MOStatus = STATE_INVOICE_PAYMENT_INITIATED // and mark it in the state of creating purchase order
+		mobox.MOParent = x.MOID
+		x.MOForks = append(x.MOForks, mobox.MOID)
+		mobox.MORemarks = new_value
+		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
+		if record != nil {
+			return nil, errors.New("Invoice already exists")
+		}
+
+	}
+
+	_, err := t.save_invoice(stub, mobox)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_REV_PAYMENT_CHECKER_TO_PAYMENT_MAKER_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	bytes, err := stub.GetState("invoiceIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get invoiceIDs")
+	}
+
+	var invoiceIDs Invoice_Holder
+
+	err = json.Unmarshal(bytes, &invoiceIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Invoice_Holder record")
+	}
+
+	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
+
+	bytes, err = json.Marshal(invoiceIDs)
+	if err != nil {
+		fmt.Print("Error creating Invoice_Holder record")
+	}
+
+	err = stub.PutState("invoiceIDs", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	v.Items = append(v.Items, (mobox))
+
+	_, errs := t.save_invoice(stub, x)
+
+	if errs != nil {
+		fmt.Printf("TRANSFER_REV_PAYMENT_CHECKER_TO_PAYMENT_MAKER_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_REV_PAYMENT_CHECKER_TO_PAYMENT_MAKER_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//==========================================================================================================
+//transfer_payment_checker_to_payment_maker_invoice
+//==========================================================================================================
+func (t *AssetManagementChaincode) transfer_payment_checker_to_payment_maker_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		//v.Owner 							 == caller &&
+		//v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_PAYMENT_PENDING_APPROVAL &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false &&
+		v.Settled == false {
+
+		for i := range v.Items {
+			if x.MOID == v.Items[i].MOID {
+
+				x.MOOwner = receiverAccount // then make the owner the new owner
+				v.Items[i].MOOwner = receiverAccount
+
+				x.MOStatus = STATE_INVOICE_PAYMENT_INITIATED // and mark it in the state of requesting invoice payment
+				v.Items[i].MOStatus = STATE_INVOICE_PAYMENT_INITIATED
+
+				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
+					fmt.Println("QUERY: Error retrieving Parent")
+				} else {
+					f, err := t.retrieve_invoice(stub, x.MOParent)
+					if err != nil {
+						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
+						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
+					}
+
+					for j := range v.Items {
+						if f.MOID == v.Items[j].MOID {
+
+							f.MOStatus = STATE_INVOICE_RETIRED
+							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
+
+							break
+						}
+
+					}
+
+					_, errs := t.save_invoice(stub, f)
+
+					if errs != nil {
+						fmt.Printf("TRANSFER_PAYMENT_CHECKER_TO_PAYMENT_MAKER_INVOICE: $!^!* Error saving changes to Invoice: %s", errs)
+						return nil, errors.New(" %@((@& Error saving changes to invoice")
+					}
+
+				}
+
+				break
+
+			}
+
+		}
+	}
+
+	_, err := t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_PAYMENT_CHECKER_TO_PAYMENT_MAKER_INVOICE: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("TRANSFER_PAYMENT_CHECKER_TO_PAYMENT_MAKER_INVOICE: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+/*//==========================================================================================================
+//transfer_payment_checker_to_anchor_invoice
+//==========================================================================================================
+func (t *AssetManagementChaincode) transfer_payment_checker_to_anchor_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		//v.Owner 							 == caller &&
+		//v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_PAID &&
+		x.MOOwner == string(callerAccount) &&
+		caller_affiliation == ROLE_PAYMENT_CHECKER &&
+		x.MOPaid == true &&
+		x.AnchorPaidInv == false &&
+		x.MOSettled == false &&
+		recipient_affiliation == ROLE_ANCHOR &&
+		v.Settled == false {
+
+		for i := range v.Items {
+			if x.MOID == v.Items[i].MOID {
+
+				x.MOOwner = receiverAccount // then make the owner the new owner
+				v.Items[i].MOOwner = receiverAccount
+
+				break
+
+			}
+
+		}
+	}
+
+	_, err := t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("transfer_payment_checker_to_anchor_invoice: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("transfer_payment_checker_to_anchor_invoice: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//==========================================================================================================
+//transfer_anchor_to_payment_checker_invoice
+//==========================================================================================================
+func (t *AssetManagementChaincode) transfer_anchor_to_payment_checker_invoice(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, receiverAccount string, recipient_affiliation string) ([]byte, error) {
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		//v.Owner 							 == caller &&
+		//v.Settled == false &&
+		x.MOStatus == STATE_ANCHOR_PAID_INVOICE &&
+		x.MOOwner == string(callerAccount) &&
+		caller_affiliation == ROLE_ANCHOR &&
+		x.MOPaid == true &&
+		x.AnchorPaidInv == true &&
+		x.MOSettled == false &&
+		recipient_affiliation == ROLE_PAYMENT_CHECKER &&
+		v.Settled == false {
+
+		for i := range v.Items {
+			if x.MOID == v.Items[i].MOID {
+
+				x.MOOwner = receiverAccount // then make the owner the new owner
+				v.Items[i].MOOwner = receiverAccount
+
+				break
+
+			}
+
+		}
+	}
+
+	_, err := t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("transfer_anchor_to_payment_checker_invoice: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("transfer_anchor_to_payment_checker_invoice: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+*/
+//=================================================================================================================================
+//	 Update Functions
+//---------------------------------------------------------------------------------------------------------------------------------
+//   ADMIN UPDATE ANCHOR FUNCTIONS
+//=================================================================================================================================
+//	 update_anchor_details
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_anchor_details(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation, name, id, ifsc, agreement, account, limit, expiry, interest, graceInterest, graceinterestPeriod, penalInterest, anchorLiquidation string) ([]byte, error) {
+	err := t.require_terms(stub, v) // The limits, interest and agreements are kept in the program's collection
+	if err != nil {
+		return nil, err
+	}
+
+	err = t.open_terms(stub, v, []PIIField{
+		{"anchorAgreement", &agreement},
+		{"anchorlimit", &limit},
+		{"anchorinterest", &interest},
+		{"anchorgraceinterest", &graceInterest},
+		{"anchorgraceinterestperiod", &graceinterestPeriod},
+		{"anchorpenalinterest", &penalInterest},
+		{"anchorliquidation", &anchorLiquidation},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	new_amount, _ := strconv.ParseFloat(string(limit), 64) // will return an error if the new purchase amount contains non numerical chars
+
+	if v.Status == STATE_TEMPLATE &&
+		v.Owner == string(callerAccount) &&
+		v.Settled == false {
+
+		v.AnchorName = name
+		v.AnchorID = id
+		v.AnchorIFSCCode = ifsc
+		v.AnchorAgreement = agreement
+		v.AnchorAccountNo = account
+		v.AnchorLimit = new_amount
+		v.AnchorExpiryDate = expiry
+		v.AnchorInterest = interest
+		v.AnchorGarceInterest = graceInterest
+		v.AnchorGarceInterestperiod = graceinterestPeriod
+		v.AnchorPenalInterest = penalInterest
+		v.AnchorLiquidation = anchorLiquidation
+
+	} else {
+
+		return nil, errors.New("Permission denied")
+
+	}
+
+	err = require_sealed([]PIIField{{"anchoraccountno", &v.AnchorAccountNo}, {"anchorifsc", &v.AnchorIFSCCode}})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("update_anchor_details: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	return nil, nil
+
+}
+
+//---------------------------------------------------------------------------------------------------------------------------------
+//   ADMIN UPDATE VENDOR FUNCTIONS
+//=================================================================================================================================
+//	 update_vendor_details
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_vendor_details(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation, id, limit, firstName, lastName, email, phone, address, pan, agreement, expiry, bank, bankAddress, account, ifsc, gstin, panDigest string) ([]byte, error) {
+	err := t.require_terms(stub, v) // The limits, interest and agreements are kept in the program's collection
+	if err != nil {
+		return nil, err
+	}
+
+	err = t.open_terms(stub, v, []PIIField{{"vendorlimit", &limit}, {"vendorAgreement", &agreement}})
+	if err != nil {
+		return nil, err
+	}
+
+	new_amount, _ := strconv.ParseFloat(string(limit), 64) // will return an error if the new purchase amount contains non numerical chars
+
+	if v.Status == STATE_TEMPLATE &&
+		v.Owner == string(callerAccount) &&
+		v.Settled == false {
+
+		v.VendorID = id
+		v.Vendorlimit = new_amount
+		v.VendorFName = firstName
+		v.VendorLName = lastName
+		v.Vendoremail = email
+		v.Vendorphone = phone
+		v.Vendoraddress = address
+		v.Vendorpanno = pan
+		v.Vendorgstin = gstin
+		v.VendorAgreement = agreement
+		v.VendorExpirydate = expiry
+		v.Vendorbank = bank
+		v.Vendorbaddress = bankAddress
+		v.Vendoraccountno = account
+		v.Vendorifsccode = ifsc
+
+	} else {
+
+		return nil, errors.New("Permission denied")
+
+	}
+
+	err = require_sealed(program_pii_fields(&v)[2:]) // The anchor's account is set by update_anchor_details
+	if err != nil {
+		return nil, err
+	}
+
+	if pan != "" && pan != "UNDEFINED" { // The chaincode cannot open the PAN on write, so the client sends its digest
+		digest, err := hex.DecodeString(panDigest)
+		if err != nil || len(digest) != sha256.Size || panDigest != strings.ToLower(panDigest) {
+			return nil, errors.New("The PAN digest must be sent with the PAN, see cmd/seal -pandigest")
+		}
+		v.VendorPanDigest = panDigest
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("update_vendor_details: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	return nil, nil
+
+}
+
+//---------------------------------------------------------------------------------------------------------------------------------
+//   ANCHOR UPDATE PO FUNCTIONS
+//=================================================================================================================================
+//	 update_anchor_purchase_order
+//=================================================================================================================================
+
+func (t *AssetManagementChaincode) update_anchor_purchase_order(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value, poDocument, poID, quantity string) ([]byte, error) {
+
+	new_amount, _ := strconv.ParseFloat(string(new_value), 64) // will return an error if the new purchase amount contains non numerical chars
+
+	document, err := t.parse_document(stub, poDocument, callerAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var new_quantity float64
+	if quantity != "" { // Quantity is optional, without it the three-way match only compares values
+		var err error
+		new_quantity, err = strconv.ParseFloat(quantity, 64)
+		if err != nil || new_quantity < 0 {
+			return nil, errors.New("Invalid PO quantity")
+		}
+	}
+
+	if !v.terms_loaded {
+		return nil, errors.New("The key for collection " + v.TermsCollection + " must be supplied to check the vendor limit")
+	}
+
+	if new_amount > v.Vendorlimit {
+		fmt.Println("Amount exceeds authorized vendor limit")
+		return nil, nil
+	} else if v.Status == STATE_PROGRAM_INITIATED &&
+		v.Owner == string(callerAccount) &&
+		v.AnchorPOAmount == 0 && // Can't change the purchase amount after its initial assignment
+		v.Settled == false {
+
+		v.AnchorPOAmount = new_amount // Update to the new value
+		v.AnchorPOQuantity = new_quantity
+		v.AnchorPoDocument = document
+		v.AnchorPoImage = ""
+		v.AnchorPoID = poID
+
+	} else {
+
+		return nil, errors.New("Permission denied")
+
+	}
+
+	_, err = t.save_changes(stub, v) // Save the changes in the blockchain
+
+	if err != nil {
+		fmt.Printf("update_anchor_purchase_order: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 update_anchor_goods_receipt
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_anchor_goods_receipt(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, grnID, quantity, value, date string) ([]byte, error) {
+
+	received_quantity, err := strconv.ParseFloat(quantity, 64)
+	if err != nil || received_quantity < 0 {
+		return nil, errors.New("Invalid received quantity")
+	}
+
+	received_value, err := strconv.ParseFloat(value, 64)
+	if err != nil || received_value <= 0 {
+		return nil, errors.New("Invalid received value")
+	}
+
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, errors.New("Invalid received date, expected YYYY-MM-DD")
+	}
+
+	if grnID == "" {
+		return nil, errors.New("Invalid GRN ID provided")
+	}
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		v.POraisedBy == string(callerAccount) && // The vendor owns the program once the PO is placed, the anchor raised it
+		v.Settled == false {
+
+		var total float64
+		for _, grn := range v.GoodsReceipts {
+			if grn.GRNID == grnID {
+				return nil, errors.New("GRN already exists")
+			}
+			total += grn.ReceivedValue
+		}
+
+		if total+received_value > v.AnchorPOAmount*(1+v.ValueTolerance/100) {
+			fmt.Println("Total received value cannot exceed the Purchase Order")
+			return nil, errors.New("Total received value cannot exceed the Purchase Order")
+		}
+
+		var grn GoodsReceiptNote
+
+		grn.GRNID = grnID
+		grn.AnchorPoID = v.AnchorPoID
+		grn.ReceivedQuantity = received_quantity
+		grn.ReceivedValue = received_value
+		grn.ReceivedDate = date
+		grn.RecordedBy = string(callerAccount)
+
+		v.GoodsReceipts = append(v.GoodsReceipts, grn)
+
+	} else {
+
+		return nil, errors.New("Permission denied")
+
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("update_anchor_goods_receipt: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 update_admin_match_tolerances - Percentages by which invoiced quantity and value may exceed the PO and GRNs
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_admin_match_tolerances(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, quantity_tolerance, value_tolerance string) ([]byte, error) {
+
+	new_quantity_tolerance, err := strconv.ParseFloat(quantity_tolerance, 64)
+	if err != nil || new_quantity_tolerance < 0 {
+		return nil, errors.New("Invalid quantity tolerance")
+	}
+
+	new_value_tolerance, err := strconv.ParseFloat(value_tolerance, 64)
+	if err != nil || new_value_tolerance < 0 {
+		return nil, errors.New("Invalid value tolerance")
+	}
+
+	if v.Settled == false {
+
+		v.QuantityTolerance = new_quantity_tolerance
+		v.ValueTolerance = new_value_tolerance
+
+	} else {
+
+		return nil, errors.New("Permission denied")
+
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("update_admin_match_tolerances: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 update_admin_approval_bands - Sets how many distinct payment checkers must approve payments in each amount band, e.g.
+//								   [{"minAmount":1000000,"approvals":2},{"minAmount":10000000,"approvals":3}]
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_admin_approval_bands(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, bands string) ([]byte, error) {
+
+	var new_bands []ApprovalBand
+
+	err := json.Unmarshal([]byte(bands), &new_bands)
+	if err != nil {
+		return nil, errors.New("Invalid approval bands, expected a JSON list of minAmount and approvals")
+	}
+
+	for _, band := range new_bands {
+		if band.MinAmount < 0 || band.Approvals < 1 {
+			return nil, errors.New("Every approval band needs a minAmount of 0 or more and at least 1 approval")
+		}
+	}
+
+	if v.Settled == false {
+
+		v.ApprovalBands = new_bands
+
+	} else {
+
+		return nil, errors.New("Permission denied")
+
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("update_admin_approval_bands: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 update_admin_program_type - Sets the program type checked against the program types each payment channel allows
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_admin_program_type(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, programType string) ([]byte, error) {
+
+	if v.Settled == false {
+
+		v.ProgramType = strings.TrimSpace(programType)
+
+	} else {
+
+		return nil, errors.New("Permission denied")
+
+	}
+
+	_, err := t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("update_admin_program_type: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 update_admin_max_payment_failures - Sets how many failed payment attempts escalate an invoice to the admin
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_admin_max_payment_failures(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value string) ([]byte, error) {
+
+	max_failures, err := strconv.Atoi(new_value)
+	if err != nil || max_failures < 1 {
+		return nil, errors.New("Invalid number of payment failures, expected 1 or more")
+	}
+
+	if v.Settled == false {
+
+		v.MaxPaymentFailures = max_failures
+
+	} else {
+
+		return nil, errors.New("Permission denied")
+
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("update_admin_max_payment_failures: Error saving changes: %s", err)
+		return nil, errors.New("Error saving changes")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 max_payment_failures - Number of failed payment attempts after which the program escalates an invoice
+//=================================================================================================================================
+func max_payment_failures(v AnchorProgram) int {
+
+	if v.MaxPaymentFailures > 0 {
+		return v.MaxPaymentFailures
+	}
+
+	return DEFAULT_MAX_PAYMENT_FAILURES
+}
+
+//=================================================================================================================================
+//	 required_approvals - Number of distinct payment checkers the program requires for a payment of amount
+//=================================================================================================================================
+func required_approvals(v AnchorProgram, amount float64) int {
+
+	required := 1
+	for _, band := range v.ApprovalBands {
+		if amount >= band.MinAmount && band.Approvals > required {
+			required = band.Approvals
+		}
+	}
+
+	return required
+}
+
+//=================================================================================================================================
+//	 three_way_match - Compares the amount the anchor is approving, together with what has already been approved on the
+//					   program, against the invoice, the purchase order and the goods received. Returns every check made
+//					   and whether they all fell within the program's tolerances.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) three_way_match(x MyBoxItem, v AnchorProgram, approved float64) ([]MatchVariance, bool) {
+
+	var received_quantity, received_value float64
+	for _, grn := range v.GoodsReceipts {
+		received_quantity += grn.ReceivedQuantity
+		received_value += grn.ReceivedValue
+	}
+
+	items := make(map[string]MyBoxItem)
+	for _, item := range v.Items {
+		items[item.MOID] = item
+	}
+
+	// Revisions share the original invoice as their root, they are one commercial invoice and must not be counted twice
+	root := func(item MyBoxItem) string {
+		id := item.MOID
+		for parent := item.MOParent; parent != "" && parent != "UNDEFINED"; parent = items[parent].MOParent {
+			id = parent
+			if _, ok := items[parent]; !ok {
+				break
+			}
+		}
+		return id
+	}
+
+	var approved_value, approved_quantity float64
+	for _, item := range v.Items {
+		if item.MOStatus == STATE_INVOICE_RETIRED ||
+			item.ApprovedInvoiceAmount == 0 ||
+			root(item) == root(x) {
+			continue
+		}
+		approved_value += item.ApprovedInvoiceAmount
+		approved_quantity += item.InvoiceQuantity
+	}
+
+	variances := []MatchVariance{
+		match_check("INVOICE_AMOUNT", x.MOAmount, approved, v.ValueTolerance),
+		match_check("PO_VALUE", v.AnchorPOAmount, approved_value+approved, v.ValueTolerance),
+		match_check("GRN_VALUE", received_value, approved_value+approved, v.ValueTolerance),
+	}
+
+	if x.InvoiceQuantity > 0 {
+		variances = append(variances, match_check("GRN_QUANTITY", received_quantity, approved_quantity+x.InvoiceQuantity, v.QuantityTolerance))
+
+		if v.AnchorPOQuantity > 0 {
+			variances = append(variances, match_check("PO_QUANTITY", v.AnchorPOQuantity, approved_quantity+x.InvoiceQuantity, v.QuantityTolerance))
+		}
+	}
+
+	matched := true
+	for _, variance := range variances {
+		if variance.WithinTolerance == false {
+			matched = false
+		}
+	}
+
+	return variances, matched
+}
+
+//=================================================================================================================================
+//	 match_check - Builds one line of the three-way match. Only an excess over the expected figure is a failure.
+//=================================================================================================================================
+func match_check(check string, expected, actual, tolerance float64) MatchVariance {
+
+	var variance MatchVariance
+
+	variance.Check = check
+	variance.Expected = expected
+	variance.Actual = actual
+	variance.Variance = actual - expected
+	variance.Tolerance = tolerance
+	variance.WithinTolerance = actual <= expected*(1+tolerance/100)+0.000001 // allow for float rounding on sums
+
+	return variance
+}
+
+//---------------------------------------------------------------------------------------------------------------------------------
+//   VENDOR UPDATE PO FUNCTIONS
+//=================================================================================================================================
+//	 update_vendor_po_acknowledgement
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_vendor_po_acknowledgement(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		v.Owner == string(callerAccount) &&
+		v.POAcknowledged == false &&
+		v.Settled == false {
+
+		v.POAcknowledged = true
+
+	} else {
+		return nil, errors.New("Permission denied")
+	}
+
+	_, err := t.save_changes(stub, v)
+	if err != nil {
+		fmt.Printf("UPDATE_VENDOR_PO_ACKNOWLEDGEMENT: Error saving changes: %s", err)
+		return nil, errors.New("UPDATE_VENDOR_PO_ACKNOWLEDGEMENT: Error saving changes")
+	}
+
+	return nil, nil
+
+}
+
+//---------------------------------------------------------------------------------------------------------------------------------
+//   VENDOR UPDATE INVOICE FUNCTIONS
+//=================================================================================================================================
+//	 update_vendor_invoice_details
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_vendor_invoice_details(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation, new_value, invID, invDocument, quantity, date, taxDetails string) ([]byte, error) {
+	new_amount, _ := strconv.ParseFloat(string(new_value), 64) // will return an error if the new purchase amount contains non numerical chars
+
+	document, err := t.parse_document(stub, invDocument, callerAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var new_quantity float64
+	if quantity != "" {
+		var err error
+		new_quantity, err = strconv.ParseFloat(quantity, 64)
+		if err != nil || new_quantity < 0 {
+			return nil, errors.New("Invalid invoice quantity")
+		}
+	}
+
+	if date != "" {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, errors.New("Invalid invoice date, expected YYYY-MM-DD")
+		}
+	}
+
+	var tax InvoiceTax
+	if taxDetails != "" {
+		tax, err = parse_invoice_tax(v, taxDetails, new_amount)
+		if err != nil {
+			return nil, err
+		}
+	} else if v.FinanceBase == FINANCE_NET_OF_TAX {
+		return nil, errors.New("The program finances invoices net of tax, so the invoice needs its tax details")
+	}
+
+	financeable := financeable_amount(v, new_amount, tax)
+
+	var inv float64
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		v.Owner == string(callerAccount) &&
+		v.Settled == false &&
+		x.MOStatus == STATE_TEMPLATE &&
+		x.MOOwner == string(callerAccount) &&
+		financeable <= v.AnchorPOAmount &&
+		x.MOPaid == false &&
+		x.MOSettled == false {
+
+		for i := range v.Items {
+			if v.Items[i].MOStatus == STATE_VENDOR_INVOICE_APPROVED {
+				inv += v.Items[i].ApprovedInvoiceAmount
+				continue
+			}
+		}
+
+		if inv+financeable > v.AnchorPOAmount {
+			fmt.Println("Total invoice amount cannot exceed the Purchase Order")
+			return nil, nil
+		} else {
+
+			fingerprint := invoice_fingerprint(v, invID, date, new_amount)
+
+			duplicate, err := t.find_live_duplicate(stub, x, fingerprint)
+			if err != nil {
+				return nil, err
+			}
+			if duplicate != "" {
+				fmt.Printf("UPDATE_VENDOR_INVOICE_DETAILS: Invoice %s duplicates live invoice %s", x.MOID, duplicate)
+				return nil, errors.New("Duplicate invoice, already raised as " + duplicate)
+			}
+
+			_, err = t.save_fingerprint(stub, x, fingerprint)
+			if err != nil {
+				return nil, err
+			}
+
+			for i := range v.Items {
+				if x.MOID == v.Items[i].MOID {
+
+					x.MOAmount = new_amount
+					v.Items[i].MOAmount = new_amount
+
+					x.InvoiceID = invID
+					v.Items[i].InvoiceID = invID
+
+					x.InvoiceDocument = document
+					v.Items[i].InvoiceDocument = document
+
+					x.InvoiceImage = ""
+					v.Items[i].InvoiceImage = ""
+
+					x.InvoiceQuantity = new_quantity
+					v.Items[i].InvoiceQuantity = new_quantity
+
+					x.InvoiceDate = date
+					v.Items[i].InvoiceDate = date
+
+					x.Fingerprint = fingerprint
+					v.Items[i].Fingerprint = fingerprint
+
+					x.Tax = tax
+					v.Items[i].Tax = tax
+
+					x.FinanceableAmount = financeable
+					v.Items[i].FinanceableAmount = financeable
+
+					break
+
+				}
+
+			}
+		}
+	}
+
+	_, err = t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("UPDATE_VENDOR_INVOICE_DETAILS: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("UPDATE_VENDOR_INVOICE_DETAILS: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 invoice_fingerprint - Identifies a commercial invoice independently of the program and MOID it was raised under, so
+//						   the same invoice cannot be financed twice. Built from the vendor's PAN and GSTIN, the invoice
+//						   number, the invoice date and the amount.
+//=================================================================================================================================
+func invoice_fingerprint(v AnchorProgram, invID, date string, amount float64) string {
+
+	parts := []string{
+		vendor_key(v),
+		strings.ToUpper(strings.TrimSpace(v.Vendorgstin)),
+		strings.ToUpper(strings.TrimSpace(invID)),
+		date,
+		strconv.FormatFloat(amount, 'f', 2, 64),
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join(parts, "|")))
+
+	return hex.EncodeToString(hash[:])
+}
+
+//=================================================================================================================================
+//	 invoice_root - Follows MOParent back to the invoice a chain of revisions started from
+//=================================================================================================================================
+func (t *AssetManagementChaincode) invoice_root(stub shim.ChaincodeStubInterface, x MyBoxItem) string {
+
+	root := x.MOID
+	seen := map[string]bool{x.MOID: true}
+
+	for parent := x.MOParent; parent != "" && parent != "UNDEFINED" && seen[parent] == false; {
+		seen[parent] = true
+		root = parent
+
+		f, err := t.retrieve_invoice(stub, parent)
+		if err != nil {
+			break
+		}
+		parent = f.MOParent
+	}
+
+	return root
+}
+
+//=================================================================================================================================
+//	 live_revision - Returns the MOID of moid or of one of its revisions that has not been retired, or "" if they all are
+//=================================================================================================================================
+func (t *AssetManagementChaincode) live_revision(stub shim.ChaincodeStubInterface, moid string, seen map[string]bool) string {
+
+	if seen[moid] {
+		return ""
+	}
+	seen[moid] = true
+
+	x, err := t.retrieve_invoice(stub, moid)
+	if err != nil {
+		return ""
+	}
+
+	if x.MOStatus != STATE_INVOICE_RETIRED {
+		return x.MOID
+	}
+
+	for _, fork := range x.MOForks {
+		if live := t.live_revision(stub, fork, seen); live != "" {
+			return live
+		}
+	}
+
+	return ""
+}
+
+//=================================================================================================================================
+//	 find_live_duplicate - Looks the fingerprint up in the ledger index. Returns the MOID of a live invoice, other than a
+//						   revision of x itself, that was raised with the same fingerprint.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) find_live_duplicate(stub shim.ChaincodeStubInterface, x MyBoxItem, fingerprint string) (string, error) {
+
+	existing, err := stub.GetState("invoiceFingerprint_" + fingerprint)
+	if err != nil {
+		return "", errors.New("Unable to get invoice fingerprint")
+	}
+
+	if existing == nil || string(existing) == x.MOID {
+		return "", nil
+	}
+
+	live := t.live_revision(stub, string(existing), make(map[string]bool))
+	if live == "" || live == x.MOID {
+		return "", nil
+	}
+
+	f, err := t.retrieve_invoice(stub, live)
+	if err != nil {
+		return "", errors.New("Error retrieving invoice " + live)
+	}
+
+	if t.invoice_root(stub, f) == t.invoice_root(stub, x) { // A revision of the same invoice being resubmitted
+		return "", nil
+	}
+
+	return live, nil
+}
+
+//=================================================================================================================================
+//	 save_fingerprint - Points the fingerprint index at x, dropping the entry for any fingerprint x was saved with before
+//=================================================================================================================================
+func (t *AssetManagementChaincode) save_fingerprint(stub shim.ChaincodeStubInterface, x MyBoxItem, fingerprint string) (bool, error) {
+
+	if x.Fingerprint != "" && x.Fingerprint != fingerprint {
+		previous, _ := stub.GetState("invoiceFingerprint_" + x.Fingerprint)
+		if string(previous) == x.MOID {
+			err := stub.DelState("invoiceFingerprint_" + x.Fingerprint)
+			if err != nil {
+				return false, errors.New("Unable to remove invoice fingerprint")
+			}
+		}
+	}
+
+	err := stub.PutState("invoiceFingerprint_"+fingerprint, []byte(x.MOID))
+	if err != nil {
+		return false, errors.New("Unable to store invoice fingerprint")
+	}
+
+	return true, nil
+}
+
+//---------------------------------------------------------------------------------------------------------------------------------
+//   ANCHOR UPDATE INVOICE FUNCTIONS
+//=================================================================================================================================
+//	 update_anchor_invoice_authorized_amount
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_anchor_invoice_authorized_amount(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value, override_reason string) ([]byte, error) {
+	new_amount, _ := strconv.ParseFloat(string(new_value), 64) // will return an error if the new purchase amount contains non numerical chars
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		// v.Owner 						== caller &&
+		v.Settled == false &&
+		(x.MOStatus == STATE_INVOICE_RAISED ||
+			x.MOStatus == STATE_VENDOR_INVOICE_APPROVED) &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false {
+
+		if x.FinanceableAmount > 0 && new_amount > x.FinanceableAmount {
+			return nil, fmt.Errorf("Approved amount %.2f exceeds the financeable amount %.2f", new_amount, x.FinanceableAmount)
+		}
+
+		variances, matched := t.three_way_match(x, v, new_amount)
+
+		if matched {
+			x.MatchResult = MATCH_PASSED
+			x.MatchOverrideReason = ""
+		} else if override_reason != "" {
+			x.MatchResult = MATCH_OVERRIDDEN
+			x.MatchOverrideReason = override_reason
+		} else {
+			result, _ := json.Marshal(variances)
+			fmt.Printf("UPDATE_ANCHOR_AUTHORIZED_INVOICE_AMOUNT: Three-way match failed: %s", result)
+			return nil, errors.New("Three-way match failed, approval requires an override reason: " + string(result))
+		}
+
+		x.MatchVariances = variances
+
+		for i := range v.Items {
+			if x.MOID == v.Items[i].MOID {
+
+				x.ApprovedInvoiceAmount = new_amount
+				v.Items[i].ApprovedInvoiceAmount = new_amount
+
+				x.MOStatus = STATE_VENDOR_INVOICE_APPROVED
+				v.Items[i].MOStatus = STATE_VENDOR_INVOICE_APPROVED
+
+				v.Items[i].MatchResult = x.MatchResult
+				v.Items[i].MatchOverrideReason = x.MatchOverrideReason
+				v.Items[i].MatchVariances = x.MatchVariances
+
+				break
+			}
+
+		}
+	}
+
+	_, err := t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("UPDATE_ANCHOR_AUTHORIZED_INVOICE_AMOUNT: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("UPDATE_ANCHOR_AUTHORIZED_INVOICE_AMOUNT: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+/*//=================================================================================================================================
+//	 update_rev_anchor_invoice_authorized_amount
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_rev_anchor_invoice_authorized_amount(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value string) ([]byte, error) {
+	new_amount, _ := strconv.ParseFloat(string(new_value), 64) // will return an error if the new purchase amount contains non numerical chars
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		// v.Owner 						== caller &&
+		v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_RAISED ||
+		x.MOStatus == STATE_VENDOR_INVOICE_APPROVED &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false {
+
+		for i := range v.Items {
+			if x.MOID == v.Items[i].MOID {
+
+				x.ApprovedInvoiceAmount = new_amount
+				v.Items[i].ApprovedInvoiceAmount = new_amount
+
+				x.MOStatus = STATE_VENDOR_INVOICE_APPROVED
+				v.Items[i].MOStatus = STATE_VENDOR_INVOICE_APPROVED
+
+				x.RevisedBy = string(callerAccount)
+				v.Items[i].RevisedBy = x.RevisedBy
+
+				break
+			}
+
+		}
+	}
+
+	_, err := t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("UPDATE_ANCHOR_AUTHORIZED_INVOICE_AMOUNT: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("UPDATE_ANCHOR_AUTHORIZED_INVOICE_AMOUNT: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+*/
+
+//---------------------------------------------------------------------------------------------------------------------------------
+//   PAYMENT MAKER UPDATE INVOICE FUNCTIONS
+//=================================================================================================================================
+//	 update_maker_invoice_payment
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_maker_invoice_payment(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value, channel string) ([]byte, error) {
+	new_amount, err := strconv.ParseFloat(string(new_value), 64)
+	if err != nil {
+		return nil, errors.New("Invalid receivable amount")
+	}
+
+	rule, err := t.check_payment_channel(stub, x, v, channel, new_amount)
+	if err != nil {
+		return nil, err
+	}
+	channel = rule.Channel
+
+	now, err := tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	base := x.ApprovedInvoiceAmount
+	if base == 0 {
+		base = x.MOAmount
+	}
+
+	fees, total_fees := fee_lines(v, FEE_DISBURSEMENT, base, now, stub.GetTxID())
+	net := round_amount(base - total_fees)
+
+	if len(fees) > 0 && round_amount(new_amount) != net {
+		return nil, fmt.Errorf("Receivable amount %.2f does not match the net disbursement %.2f after fees of %.2f", new_amount, net, total_fees)
+	}
+	if len(fees) == 0 {
+		net = round_amount(new_amount) // nothing is deducted, so the amount validated by the payment channel is disbursed
+	}
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		// v.Owner 						== caller &&
+		v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_PAYMENT_INITIATED &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false {
+
+		for i := range v.Items {
+			if x.MOID == v.Items[i].MOID {
+
+				x.MOReceivableAmount = new_amount
+				v.Items[i].MOReceivableAmount = new_amount
+
+				x.PaymentChannel = channel
+				v.Items[i].PaymentChannel = channel
+
+				x.PaymentMaker = string(callerAccount)
+				v.Items[i].PaymentMaker = string(callerAccount)
+
+				x.FeeLines = fees
+				v.Items[i].FeeLines = fees
+
+				x.NetDisbursement = net
+				v.Items[i].NetDisbursement = net
+
+				break
+			}
+
+		}
+	}
+
+	_, err = t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("UPDATE_MAKER_INVOICE_PAYMENT: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("UPDATE_MAKER_INVOICE_PAYMENT: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//---------------------------------------------------------------------------------------------------------------------------------
+//   PAYMENT CHECKER UPDATE INVOICE FUNCTIONS
+//=================================================================================================================================
+//	 update_checker_invoice_approval
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_checker_invoice_approval(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	//var pending = 0
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		// v.Owner 						== caller &&
+		v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_PAYMENT_PENDING_APPROVAL &&
+		(x.MOOwner == string(callerAccount) || len(x.Approvals) > 0) && // the owning checker approves first, others then co-sign
+		x.MOPaid == false &&
+		x.MOSettled == false {
+
+		for _, approval := range x.Approvals {
+			if approval.Account == string(callerAccount) {
+				return nil, errors.New("This account has already approved the payment")
+			}
+		}
+
+		approved_at, err := tx_timestamp(stub)
+		if err != nil {
+			return nil, err
+		}
+
+		amount := x.MOReceivableAmount
+		if amount == 0 {
+			amount = x.ApprovedInvoiceAmount
+		}
+
+		x.RequiredApprovals = required_approvals(v, amount)
+		x.Approvals = append(x.Approvals, PaymentApproval{Account: string(callerAccount), ApprovedAt: approved_at})
+
+		for i := range v.Items {
+			//			pending += v.Items[i].MOAmount
+			if x.MOID == v.Items[i].MOID {
+
+				v.Items[i].RequiredApprovals = x.RequiredApprovals
+				v.Items[i].Approvals = x.Approvals
+
+				if len(x.Approvals) < x.RequiredApprovals { // Stays pending until quorum
+					break
+				}
+
+				x.CheckerApprovedPayment = true
+				v.Items[i].CheckerApprovedPayment = true
+
+				x.MOStatus = STATE_INVOICE_PAYMENT_APPROVED
+				v.Items[i].MOStatus = STATE_INVOICE_PAYMENT_APPROVED
+
+				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
+					fmt.Println("QUERY: Error retrieving Parent")
+				} else {
+					f, err := t.retrieve_invoice(stub, x.MOParent)
+					if err != nil {
+						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
+						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
+					}
+
+					for j := range v.Items {
+						if f.MOID == v.Items[j].MOID {
+
+							f.MOStatus = STATE_INVOICE_RETIRED
+							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
+
+							break
+						}
+
+					}
+
+					_, errs := t.save_invoice(stub, f)
+
+					if errs != nil {
+						fmt.Printf("UPDATE_CHECKER_INVOICE_APPROVAL: $!^!* Error saving changes to Invoice: %s", errs)
+						return nil, errors.New(" %@((@& Error saving changes to invoice")
+					}
+
+				}
+
+				break
+
+			}
+
+		}
+
+	}
+
+	_, err := t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("UPDATE_CHECKER_INVOICE_APPROVAL: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("UPDATE_CHECKER_INVOICE_APPROVAL: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 update_rev_checker_invoice_approval
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_rev_checker_invoice_approval(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value string) ([]byte, error) {
+
+	var mobox MyBoxItem
+	mobox = x
+
+	//var pending = 0
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		// v.Owner 						== caller &&
+		v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_PAYMENT_APPROVED &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false {
+
+		mobox.MOID = x.MOID + "-RIU1"
+		mobox.CheckerApprovedPayment = false                    // then make the owner the new owner
+		mobox.MOStatus = STATE_INVOICE_PAYMENT_PENDING_APPROVAL // and mark it in the state of creating purchase order
+		mobox.Approvals = nil
+		mobox.MOParent = x.MOID
+		mobox.RevisedBy = string(callerAccount)
+		mobox.MORemarks = new_value
+		x.MOForks = append(x.MOForks, mobox.MOID)
+
+		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
+		if record != nil {
+			return nil, errors.New("Invoice already exists")
+		}
+
+	}
+
+	_, err := t.save_invoice(stub, mobox)
+
+	if err != nil {
+		fmt.Printf("UPDATE_REV_PAYMENT_CHECKER_INVOICE_APPROVAL: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	bytes, err := stub.GetState("invoiceIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get invoiceIDs")
+	}
+
+	var invoiceIDs Invoice_Holder
+
+	err = json.Unmarshal(bytes, &invoiceIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Invoice_Holder record")
+	}
+
+	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
+
+	bytes, err = json.Marshal(invoiceIDs)
+	if err != nil {
+		fmt.Print("Error creating Invoice_Holder record")
+	}
+
+	err = stub.PutState("invoiceIDs", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	v.Items = append(v.Items, (mobox))
+
+	_, errs := t.save_invoice(stub, x)
+
+	if errs != nil {
+		fmt.Printf("UPDATE_REV_PAYMENT_CHECKER_INVOICE_APPROVAL: $!^!* Error saving changes to Invoice: %s", errs)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("UPDATE_REV_PAYMENT_CHECKER_INVOICE_APPROVAL: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 update_checker_invoice_payment
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_checker_invoice_payment(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value0 string, new_value string, reason_code string) ([]byte, error) {
+
+	new_value, err := t.check_utr(stub, x, new_value0, new_value)
+	if err != nil {
+		fmt.Printf("UPDATE_CHECKER_INVOICE_PAYMENT: %s", err)
+		return nil, err
+	}
+
+	reason_code = strings.ToUpper(strings.TrimSpace(reason_code))
+	if new_value0 != "SUCCESS" && reason_code == "" {
+		reason_code = "UNSPECIFIED"
+	}
+
+	now, err := tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	//var pending = 0
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		// v.Owner 						== caller &&
+		v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_PAYMENT_APPROVED &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == false &&
+		x.MOSettled == false {
+
+		for i := range v.Items {
+			//			pending += v.Items[i].MOAmount
+			if x.MOID == v.Items[i].MOID {
+				
+				x.TxnStatus = new_value0
+				v.Items[i].TxnStatus = new_value0 
+
+				x.PaymentAttempts = append(x.PaymentAttempts, PaymentAttempt{Status: new_value0, ReasonCode: reason_code, UTR: new_value, At: now, By: string(callerAccount), TxID: stub.GetTxID()})
+				v.Items[i].PaymentAttempts = x.PaymentAttempts
+				
+				if new_value0 == "SUCCESS" {
+					
+					x.UTRNumber = new_value
+					v.Items[i].UTRNumber = new_value
+
+					x.MOPaid = true
+					v.Items[i].MOPaid = true
+
+					x.MOStatus = STATE_INVOICE_PAID
+					v.Items[i].MOStatus = STATE_INVOICE_PAID
+
+					key, err := t.disbursement_journal(stub, x, v, callerAccount)
+					if err != nil {
+						return nil, err
+					}
+					if key != "" {
+						x.JournalEntries = append(x.JournalEntries, key)
+						v.Items[i].JournalEntries = x.JournalEntries
+					}
+				} else {
+					
+					// The failed UTR stays in PaymentAttempts only
+					x.MOPaid = false
+					v.Items[i].MOPaid = false
+
+					x.PaymentFailures += 1
+					v.Items[i].PaymentFailures = x.PaymentFailures
+
+					if x.PaymentFailures >= max_payment_failures(v) {
+						x.MOStatus = STATE_INVOICE_PAYMENT_FAILED
+						v.Items[i].MOStatus = STATE_INVOICE_PAYMENT_FAILED
+					} else {
+						x.MOStatus = STATE_INVOICE_PAYMENT_APPROVED
+						v.Items[i].MOStatus = STATE_INVOICE_PAYMENT_APPROVED
+					}
+				}
+
+				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
+					fmt.Println("QUERY: Error retrieving Parent")
+				} else {
+					f, err := t.retrieve_invoice(stub, x.MOParent)
+					if err != nil {
+						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
+						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
+					}
+
+					for j := range v.Items {
+						if f.MOID == v.Items[j].MOID {
+
+							f.MOStatus = STATE_INVOICE_RETIRED
+							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
+
+							break
+						}
+
+					}
+
+					_, errs := t.save_invoice(stub, f)
+
+					if errs != nil {
+						fmt.Printf("UPDATE_CHECKER_INVOICE_PAYMENT: $!^!* Error saving changes to Invoice: %s", errs)
+						return nil, errors.New(" %@((@& Error saving changes to invoice")
+					}
+
+				}
+
+				break
+
+			}
+
+		}
+
+	}
+
+	_, err = t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("UPDATE_CHECKER_INVOICE_PAYMENT: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	if x.UTRNumber == new_value && new_value != "" { // Only index a UTR the invoice now carries
+		err = stub.PutState("utr_"+new_value, []byte(x.MOID))
+		if err != nil {
+			return nil, errors.New("Error indexing UTR")
+		}
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("UPDATE_CHECKER_INVOICE_PAYMENT: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 update_admin_invoice_payment_failure - Resolves an invoice escalated after repeated payment failures. RETRY returns it to
+//											its payment checker to pay again; RETURN_TO_MAKER returns it to its payment maker
+//											to enter the payment again, which then needs fresh approvals.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_admin_invoice_payment_failure(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, action string, reason string) ([]byte, error) {
+
+	action = strings.ToUpper(strings.TrimSpace(action))
+
+	if action != "RETRY" && action != "RETURN_TO_MAKER" {
+		return nil, errors.New("Invalid action, expected RETRY or RETURN_TO_MAKER")
+	}
+
+	if strings.TrimSpace(reason) == "" {
+		return nil, errors.New("A reason must be given")
+	}
+
+	if action == "RETURN_TO_MAKER" && x.PaymentMaker == "" {
+		return nil, errors.New("The invoice has no payment maker to return it to")
+	}
+
+	if v.Status != STATE_PURCHASE_ORDER_PLACED ||
+		v.Settled == true ||
+		x.MOStatus != STATE_INVOICE_PAYMENT_FAILED ||
+		x.MOPaid == true ||
+		x.MOSettled == true {
+		return nil, errors.New("Permission denied")
+	}
+
+	now, err := tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	x.PaymentResolutions = append(x.PaymentResolutions, PaymentResolution{Action: action, Reason: reason, At: now, By: string(callerAccount)})
+	x.PaymentFailures = 0
+
+	if action == "RETRY" {
+		x.MOStatus = STATE_INVOICE_PAYMENT_APPROVED
+	} else {
+		x.MOStatus = STATE_INVOICE_PAYMENT_INITIATED
+		x.MOOwner = x.PaymentMaker
+		x.Approvals = nil
+		x.CheckerApprovedPayment = false
+	}
+
+	for i := range v.Items {
+		if x.MOID == v.Items[i].MOID {
+
+			v.Items[i].MOStatus = x.MOStatus
+			v.Items[i].MOOwner = x.MOOwner
+			v.Items[i].Approvals = x.Approvals
+			v.Items[i].CheckerApprovedPayment = x.CheckerApprovedPayment
+			v.Items[i].PaymentFailures = x.PaymentFailures
+			v.Items[i].PaymentResolutions = x.PaymentResolutions
+
+			break
+		}
+	}
+
+	_, err = t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("UPDATE_ADMIN_INVOICE_PAYMENT_FAILURE: Error saving changes to Invoice: %s", err)
+		return nil, errors.New("Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("UPDATE_ADMIN_INVOICE_PAYMENT_FAILURE: Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New("Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 check_utr - Normalises a UTR and checks it against the format of the invoice's payment channel. A UTR indexed to
+//				 another live invoice is rejected, unless that invoice is a revision of the same one. A failed payment
+//				 may be recorded without a UTR.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) check_utr(stub shim.ChaincodeStubInterface, x MyBoxItem, status, utr string) (string, error) {
+
+	utr = strings.ToUpper(strings.TrimSpace(utr))
+
+	if utr == "" || utr == "UNDEFINED" {
+		if status == "SUCCESS" {
+			return "", errors.New("A UTR must be given for a successful payment")
+		}
+		return "", nil
+	}
+
+	channel, err := t.payment_channel(stub, x.PaymentChannel)
+	if err != nil {
+		return "", err
+	}
+
+	if channel.UTRFormat != "" {
+		format, err := regexp.Compile(channel.UTRFormat)
+		if err != nil || !format.MatchString(utr) {
+			return "", errors.New("UTR " + utr + " is not a valid " + channel.Channel + " UTR")
+		}
+	}
+
+	owner, err := stub.GetState("utr_" + utr)
+	if err != nil {
+		return "", errors.New("Unable to get UTR index")
+	}
+
+	if len(owner) != 0 && string(owner) != x.MOID {
+		other, err := t.retrieve_invoice(stub, string(owner))
+		if err == nil && other.MOStatus != STATE_INVOICE_RETIRED && t.invoice_root(stub, other) != t.invoice_root(stub, x) {
+			return "", errors.New("UTR " + utr + " is already recorded against invoice " + other.MOID)
+		}
+	}
+
+	return utr, nil
+}
+
+//=================================================================================================================================
+//	 update_rev_checker_invoice_payment - Reverses a payment the bank returned. The disbursement journal is reversed and
+//										  the invoice is raised again as a revision awaiting payment; the original is no
+//										  longer paid and is retired, so it cannot be settled or written off.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_rev_checker_invoice_payment(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value string) ([]byte, error) {
+
+	var mobox MyBoxItem
+	mobox = x
+
+	//var pending = 0
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		// v.Owner 						== caller &&
+		v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_PAID &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == true &&
+		x.MOSettled == false {
+
+		key, err := t.reverse_journal(stub, x, JOURNAL_DISBURSEMENT, callerAccount)
+		if err != nil {
+			return nil, err
+		}
+		if key != "" {
+			x.JournalEntries = append(x.JournalEntries, key)
+		}
+
+		x.MOPaid = false
+		x.MOStatus = STATE_INVOICE_RETIRED
+
+		for i := range v.Items {
+			if x.MOID == v.Items[i].MOID {
+
+				v.Items[i].MOPaid = x.MOPaid
+				v.Items[i].MOStatus = x.MOStatus
+				v.Items[i].JournalEntries = x.JournalEntries
+
+				break
+			}
+		}
+
+		mobox.MOID = x.MOID + "-RIU2"
+		mobox.MOPaid = false // then make the owner the new owner
+		mobox.UTRNumber = "UNDEFINED"
+		mobox.MOStatus = STATE_INVOICE_PAYMENT_APPROVED // and mark it in the state of creating purchase order
+		mobox.MOParent = x.MOID
+		mobox.RevisedBy = string(callerAccount)
+		mobox.MORemarks = new_value
+		x.MOForks = append(x.MOForks, mobox.MOID)
+
+		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
+		if record != nil {
+			return nil, errors.New("Invoice already exists")
+		}
+
+	}
+
+	_, err := t.save_invoice(stub, mobox)
+
+	if err != nil {
+		fmt.Printf("UPDATE_REV_CHECKER_INVOICE_PAYMENT: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	bytes, err := stub.GetState("invoiceIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get invoiceIDs")
+	}
+
+	var invoiceIDs Invoice_Holder
+
+	err = json.Unmarshal(bytes, &invoiceIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Invoice_Holder record")
+	}
+
+	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
+
+	bytes, err = json.Marshal(invoiceIDs)
+	if err != nil {
+		return nil, errors.New("Error creating Invoice_Holder record")
+	}
+
+	err = stub.PutState("invoiceIDs", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	v.Items = append(v.Items, (mobox))
+
+	_, errs := t.save_invoice(stub, x)
+
+	if errs != nil {
+		fmt.Printf("UPDATE_REV_CHECKER_INVOICE_PAYMENT: $!^!* Error saving changes to Invoice: %s", errs)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("UPDATE_REV_CHECKER_INVOICE_PAYMENT: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 update_checker_invoice_settlement
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_checker_invoice_settlement(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value string) ([]byte, error) {
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		// v.Owner 						== caller &&
+		v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_PAID &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == true &&
+		x.MOSettled == false {
+
+		for i := range v.Items {
+			if x.MOID == v.Items[i].MOID {
+
+				x.MOSettled = true
+				v.Items[i].MOSettled = true
+
+				x.MOStatus = STATE_INVOICE_SETTLED
+				v.Items[i].MOStatus = STATE_INVOICE_SETTLED
+
+				x.SettlementAmount = new_value
+				v.Items[i].SettlementAmount = new_value
+
+				key, err := t.settlement_journal(stub, x, v, callerAccount)
+				if err != nil {
+					return nil, err
+				}
+				if key != "" {
+					x.JournalEntries = append(x.JournalEntries, key)
+					v.Items[i].JournalEntries = x.JournalEntries
+				}
+
+				if x.MOParent == "" || x.MOParent == "UNDEFINED" {
+					fmt.Println("QUERY: Error retrieving Parent")
+				} else {
+					f, err := t.retrieve_invoice(stub, x.MOParent)
+					if err != nil {
+						fmt.Printf("QUERY: Error retrieving invoice: %s", err)
+						return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
+					}
+
+					for j := range v.Items {
+						if f.MOID == v.Items[j].MOID {
+
+							f.MOStatus = STATE_INVOICE_RETIRED
+							v.Items[j].MOStatus = STATE_INVOICE_RETIRED
+
+							break
+						}
+
+					}
+
+					_, errs := t.save_invoice(stub, f)
+
+					if errs != nil {
+						fmt.Printf("UPDATE_CHECKER_INVOICE_SETTLEMENT: $!^!* Error saving changes to Invoice: %s", errs)
+						return nil, errors.New(" %@((@& Error saving changes to invoice")
+					}
+
+				}
+
+				break
+
+			}
+
+		}
+
+	}
+
+	_, err := t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("UPDATE_CHECKER_INVOICE_SETTLEMENT: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("UPDATE_CHECKER_INVOICE_SETTLEMENT: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 update_rev_checker_invoice_settlement
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_rev_checker_invoice_settlement(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, new_value string) ([]byte, error) {
+
+	var mobox MyBoxItem
+	mobox = x
+
+	if v.Status == STATE_PURCHASE_ORDER_PLACED &&
+		// v.Owner 						== caller &&
+		v.Settled == false &&
+		x.MOStatus == STATE_INVOICE_SETTLED &&
+		x.MOOwner == string(callerAccount) &&
+		x.MOPaid == true &&
+		x.MOSettled == true {
+
+		key, err := t.reverse_journal(stub, x, JOURNAL_SETTLEMENT, callerAccount)
+		if err != nil {
+			return nil, err
+		}
+		if key != "" {
+			x.JournalEntries = append(x.JournalEntries, key)
+		}
+
+		mobox.MOID = x.MOID + "-RIU3"
+		mobox.MOSettled = false // then make the owner the new owner
+		mobox.SettlementAmount = "UNDEFINED"
+		mobox.MOStatus = STATE_INVOICE_PAID // and mark it in the state of creating purchase order
+		mobox.MOParent = x.MOID
+		mobox.RevisedBy = string(callerAccount)
+		mobox.MORemarks = new_value
+		x.MOForks = append(x.MOForks, mobox.MOID)
+
+		record, _ := stub.GetState(mobox.MOID) // If not an error then a record exists so cant create a new order with this AnchorProgramID as it must be unique
+		if record != nil {
+			return nil, errors.New("Invoice already exists")
+		}
+
+	}
+
+	_, err := t.save_invoice(stub, mobox)
+
+	if err != nil {
+		fmt.Printf("UPDATE_REV_CHECKER_INVOICE_SETTLEMENT: $!^!* Error saving changes to Invoice: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	bytes, err := stub.GetState("invoiceIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get invoiceIDs")
+	}
+
+	var invoiceIDs Invoice_Holder
+
+	err = json.Unmarshal(bytes, &invoiceIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Invoice_Holder record")
+	}
+
+	invoiceIDs.INVOICEs = append(invoiceIDs.INVOICEs, mobox.MOID)
+
+	bytes, err = json.Marshal(invoiceIDs)
+	if err != nil {
+		fmt.Print("Error creating Invoice_Holder record")
+	}
+
+	err = stub.PutState("invoiceIDs", bytes)
+	if err != nil {
+		return nil, errors.New("Unable to put the state")
+	}
+
+	v.Items = append(v.Items, (mobox))
+
+	_, errs := t.save_invoice(stub, x)
+
+	if errs != nil {
+		fmt.Printf("UPDATE_REV_CHECKER_INVOICE_SETTLEMENT: $!^!* Error saving changes to Invoice: %s", errs)
+		return nil, errors.New(" %@((@& Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("UPDATE_REV_CHECKER_INVOICE_SETTLEMENT: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 settlement_anchorprogram - Closes a program once every invoice in it is settled, retired or written off, and stores its
+//								closing statement. Otherwise the error is a JSON object listing the open invoices.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) settlement_anchorprogram(stub shim.ChaincodeStubInterface, v AnchorProgram, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	if v.Status != STATE_PURCHASE_ORDER_PLACED || v.Settled == true {
+		return nil, errors.New("Permission denied")
+	}
+
+	c := ClosingStatement{AnchorProgramID: v.AnchorProgramID, POValue: v.AnchorPOAmount, ClosedBy: string(callerAccount), TxID: stub.GetTxID()}
+
+	var open []OpenItem
+
+	for _, item := range v.Items {
+
+		x, err := t.retrieve_invoice(stub, item.MOID)
+		if err != nil {
+			return nil, errors.New("Error retrieving invoice " + item.MOID)
+		}
+
+		switch x.MOStatus {
+		case STATE_INVOICE_RETIRED:
+			c.Retired += 1
+			continue
+		case STATE_INVOICE_SETTLED:
+			c.Settled += 1
+		case STATE_INVOICE_WRITTEN_OFF:
+			c.WrittenOffCount += 1
+			c.WrittenOff += x.WriteOffAmount
+		default:
+			open = append(open, OpenItem{MOID: x.MOID, MOStatus: x.MOStatus, MOOwner: x.MOOwner})
+			continue
+		}
+
+		c.Invoices += 1
+		c.Invoiced += x.MOAmount
+		c.Approved += x.ApprovedInvoiceAmount
+
+		if x.MOPaid {
+			c.Disbursed += x.MOReceivableAmount
+		}
+
+		if x.MOSettled {
+			repaid, _ := strconv.ParseFloat(x.SettlementAmount, 64)
+			c.Repaid += repaid
+			c.Interest += repaid - x.MOReceivableAmount
+		}
+	}
+
+	if len(open) > 0 {
+		bytes, _ := json.Marshal(map[string]interface{}{"error": "Program has open invoices", "anchorProgramID": v.AnchorProgramID, "openItems": open})
+		return nil, errors.New(string(bytes))
+	}
+
+	var err error
+
+	c.ClosedAt, err = tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := json.Marshal(c)
+	if err != nil {
+		return nil, errors.New("Error converting ClosingStatement record")
+	}
+
+	err = stub.PutState("closing_"+v.AnchorProgramID, bytes)
+	if err != nil {
+		return nil, errors.New("Error storing ClosingStatement record")
+	}
+
+	v.Settled = true
+	v.Status = STATE_ANCHOR_PROGRAM_CLOSED
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("settlement_anchorprogram: $!^!* Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New(" %@((@& Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 update_admin_invoice_write_off - First step of writing off an invoice that will not be settled, so its program can
+//									  close. A second admin must approve it with update_admin_invoice_write_off_approval.
+//									  Invoices waiting in a payment batch or approved for payment cannot be written off.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_admin_invoice_write_off(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string, reasonCode string, reason string) ([]byte, error) {
+
+	if !contains(WRITE_OFF_REASON_CODES, reasonCode) {
+		return nil, errors.New("Invalid write-off reason code, expected one of " + strings.Join(WRITE_OFF_REASON_CODES, ", "))
+	}
+
+	if reasonCode == "OTHER" && strings.TrimSpace(reason) == "" {
+		return nil, errors.New("A reason must be given for a write-off coded OTHER")
+	}
+
+	err := write_off_allowed(x, v)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	x.WriteOffReasonCode = reasonCode
+	x.WriteOffReason = reason
+	x.WriteOffRequestedBy = string(callerAccount)
+	x.WriteOffRequestedAt = now
+
+	for i := range v.Items {
+		if x.MOID == v.Items[i].MOID {
+
+			v.Items[i].WriteOffReasonCode = x.WriteOffReasonCode
+			v.Items[i].WriteOffReason = x.WriteOffReason
+			v.Items[i].WriteOffRequestedBy = x.WriteOffRequestedBy
+			v.Items[i].WriteOffRequestedAt = x.WriteOffRequestedAt
+
+			break
+		}
+	}
+
+	_, err = t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("UPDATE_ADMIN_INVOICE_WRITE_OFF: Error saving changes to Invoice: %s", err)
+		return nil, errors.New("Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("UPDATE_ADMIN_INVOICE_WRITE_OFF: Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New("Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 update_admin_invoice_write_off_approval - Writes off an invoice once a different admin from the one who requested it
+//											   approves. A paid invoice writes off the amount financed against it.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) update_admin_invoice_write_off_approval(stub shim.ChaincodeStubInterface, x MyBoxItem, v AnchorProgram, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	if x.WriteOffRequestedBy == "" {
+		return nil, errors.New("No request to write off invoice " + x.MOID)
+	}
+
+	if x.WriteOffRequestedBy == string(callerAccount) {
+		return nil, errors.New("A write-off must be approved by a second admin")
+	}
+
+	err := write_off_allowed(x, v) // The invoice may have moved on since the request
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := tx_timestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	x.MOStatus = STATE_INVOICE_WRITTEN_OFF
+	x.WrittenOffBy = string(callerAccount)
+	x.WrittenOffAt = now
+	x.WriteOffAmount = 0
+	if x.MOPaid {
+		_, fees := fee_journal_lines(x.FeeLines)
+		x.WriteOffAmount = x.MOReceivableAmount + fees
+	}
+
+	key, err := t.post_journal(stub, JOURNAL_WRITE_OFF, v.AnchorProgramID, x.MOID, callerAccount, []JournalLine{
+		{Account: GL_WRITE_OFF, Debit: x.WriteOffAmount, Narrative: "Write-off " + x.MOID + " " + x.WriteOffReasonCode},
+		{Account: GL_RECEIVABLE, Credit: x.WriteOffAmount, Narrative: "Write-off " + x.MOID + " " + x.WriteOffReasonCode},
+	}, "")
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		x.JournalEntries = append(x.JournalEntries, key)
+	}
+
+	for i := range v.Items {
+		if x.MOID == v.Items[i].MOID {
+
+			v.Items[i].MOStatus = x.MOStatus
+			v.Items[i].WrittenOffBy = x.WrittenOffBy
+			v.Items[i].WrittenOffAt = x.WrittenOffAt
+			v.Items[i].WriteOffAmount = x.WriteOffAmount
+			v.Items[i].JournalEntries = x.JournalEntries
+
+			break
+		}
+	}
+
+	_, err = t.save_invoice(stub, x)
+
+	if err != nil {
+		fmt.Printf("UPDATE_ADMIN_INVOICE_WRITE_OFF_APPROVAL: Error saving changes to Invoice: %s", err)
+		return nil, errors.New("Error saving changes to invoice")
+	}
+
+	_, err = t.save_changes(stub, v)
+
+	if err != nil {
+		fmt.Printf("UPDATE_ADMIN_INVOICE_WRITE_OFF_APPROVAL: Error saving changes to AnchorProgram: %s", err)
+		return nil, errors.New("Error saving changes to AnchorProgram")
+	}
+
+	return nil, nil
+
+}
+
+//=================================================================================================================================
+//	 write_off_allowed - Returns an error unless the invoice can be written off
+//=================================================================================================================================
+func write_off_allowed(x MyBoxItem, v AnchorProgram) error {
+
+	if v.Status != STATE_PURCHASE_ORDER_PLACED ||
+		v.Settled == true ||
+		x.MOSettled == true ||
+		x.MOStatus == STATE_INVOICE_SETTLED ||
+		x.MOStatus == STATE_INVOICE_RETIRED ||
+		x.MOStatus == STATE_INVOICE_WRITTEN_OFF {
+		return errors.New("Permission denied")
+	}
+
+	if x.PaymentBatch != "" || x.MOStatus == STATE_INVOICE_PAYMENT_APPROVED {
+		return errors.New("Invoice " + x.MOID + " is on its way to payment and cannot be written off")
+	}
+
+	return nil
+}
+
+//=================================================================================================================================
+//	Query - Called on chaincode query. Takes a function name passed and calls that function. Passes the
+//  		initial arguments passed are passed on to the called function.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) Query(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	callerRole, err := stub.ReadCertAttribute("role")
+	if err != nil {
+		fmt.Printf("Error reading attribute 'role' [%v] \n", err)
+		return nil, fmt.Errorf("Failed fetching caller role. Error was [%v]", err)
+	}
+	caller_affiliation := string(callerRole[:])
+
+	callerAccount, err := stub.ReadCertAttribute("account")
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching caller account. Error was [%v]", err)
+	}
+
+	if function == "get_anchorprogram_details" {
+		if len(args) != 1 {
+			fmt.Printf("Incorrect number of arguments passed")
+			return nil, errors.New("QUERY: Incorrect number of arguments passed")
+		}
+
+		v, err := t.retrieve_anchorprogram(stub, args[0])
+		if err != nil {
+			fmt.Printf("QUERY: Error retrieving po: %s", err)
+			return nil, errors.New("QUERY: Error retrieving anchor program " + err.Error())
+		}
+
+		w, err := t.viewer(stub, callerAccount, caller_affiliation)
+		if err != nil {
+			return nil, err
+		}
+
+		return t.get_anchorprogram_details(stub, v, w)
+
+	} else if function == "get_invoice_details" {
+		if len(args) != 1 {
+			fmt.Printf("Incorrect number of arguments passed")
+			return nil, errors.New("QUERY: Incorrect number of arguments passed")
+		}
+
+		x, errs := t.retrieve_invoice(stub, args[0])
+		if errs != nil {
+			fmt.Printf("QUERY: Error retrieving invoice: %s", errs)
+			return nil, errors.New("QUERY: Error retrieving invoice " + errs.Error())
+		}
+
+		w, err := t.viewer(stub, callerAccount, caller_affiliation)
+		if err != nil {
+			return nil, err
+		}
+
+		return t.get_invoice_details(stub, x, w)
+
+	} else if function == "get_anchorprograms" {
+		return t.get_anchorprograms(stub, callerAccount, caller_affiliation)
+	} else if function == "get_anchorprogramIDs" {
+		return t.get_anchorprogramIDs(stub, callerAccount, caller_affiliation)
+	} else if function == "get_invoiceIDs" {
+		return t.get_invoiceIDs(stub, callerAccount, caller_affiliation)
+	} else if function == "get_invoices" {
+		return t.get_invoices(stub, callerAccount, caller_affiliation)
+	} else if function == "get_suspected_duplicate_invoices" {
+		return t.get_suspected_duplicate_invoices(stub, callerAccount, caller_affiliation)
+	} else if function == "verify_document" {
+		if len(args) != 2 && len(args) != 3 {
+			fmt.Printf("Incorrect number of arguments passed")
+			return nil, errors.New("QUERY: Incorrect number of arguments passed")
+		}
+
+		w, err := t.viewer(stub, callerAccount, caller_affiliation)
+		if err != nil {
+			return nil, err
+		}
+
+		return t.verify_document(stub, args[0], args[1], optional_arg(args, 2), w)
+	} else if function == "get_access_policy" {
+		return t.get_access_policy(stub, optional_arg(args, 0))
+	} else if function == "get_participant" {
+		if len(args) != 1 {
+			fmt.Printf("Incorrect number of arguments passed")
+			return nil, errors.New("QUERY: Incorrect number of arguments passed")
+		}
+
+		p, err := t.retrieve_participant(stub, args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(p)
+	} else if function == "find_participants" {
+		return t.find_participants(stub, optional_arg(args, 0), optional_arg(args, 1))
+	} else if function == "get_kyc" {
+		if len(args) != 1 {
+			fmt.Printf("Incorrect number of arguments passed")
+			return nil, errors.New("QUERY: Incorrect number of arguments passed")
+		}
+
+		return t.get_kyc(stub, args[0], callerAccount, caller_affiliation)
+	} else if function == "get_kyc_due" {
+		return t.get_kyc_due(stub, optional_arg(args, 0), callerAccount, caller_affiliation)
+	} else if function == "get_admin_set" {
+		admins, err := t.retrieve_admin_set(stub)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(admins)
+	} else if function == "get_admin_proposal" {
+		if len(args) != 1 {
+			fmt.Printf("Incorrect number of arguments passed")
+			return nil, errors.New("QUERY: Incorrect number of arguments passed")
+		}
+
+		proposal, err := t.retrieve_admin_proposal(stub, args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(proposal)
+	} else if function == "find_by_utr" {
+		if len(args) != 1 {
+			fmt.Printf("Incorrect number of arguments passed")
+			return nil, errors.New("QUERY: Incorrect number of arguments passed")
+		}
+
+		moid, err := stub.GetState("utr_" + strings.ToUpper(strings.TrimSpace(args[0])))
+		if err != nil || len(moid) == 0 {
+			return nil, errors.New("QUERY: No invoice recorded with UTR " + args[0])
+		}
+
+		x, err := t.retrieve_invoice(stub, string(moid))
+		if err != nil {
+			return nil, errors.New("QUERY: Error retrieving invoice " + err.Error())
+		}
+
+		w, err := t.viewer(stub, callerAccount, caller_affiliation)
+		if err != nil {
+			return nil, err
+		}
+
+		return t.get_invoice_details(stub, x, w)
+	} else if function == "get_failed_payments" {
+		return t.get_failed_payments(stub, callerAccount, caller_affiliation)
+	} else if function == "get_journals" {
+		if len(args) != 2 {
+			fmt.Printf("Incorrect number of arguments passed")
+			return nil, errors.New("QUERY: Incorrect number of arguments passed")
+		}
+
+		return t.get_journals(stub, args[0], args[1], callerAccount, caller_affiliation)
+	} else if function == "get_gl_accounts" {
+		err := t.authorize(stub, PolicyRequest{Function: "view_journals", CallerAccount: string(callerAccount), CallerRole: caller_affiliation, State: -1, Admin: t.is_admin(stub, string(callerAccount), caller_affiliation)})
+		if err != nil {
+			return nil, err
+		}
+
+		accounts, err := t.retrieve_gl_accounts(stub)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(accounts)
+	} else if function == "get_closing_statement" {
+		if len(args) != 1 {
+			fmt.Printf("Incorrect number of arguments passed")
+			return nil, errors.New("QUERY: Incorrect number of arguments passed")
+		}
+
+		v, err := t.retrieve_anchorprogram(stub, args[0])
+		if err != nil {
+			return nil, errors.New("QUERY: Error retrieving anchor program " + err.Error())
+		}
+
+		w, err := t.viewer(stub, callerAccount, caller_affiliation)
+		if err != nil {
+			return nil, err
+		}
+
+		if !whole_program(w, v) { // The totals cover every invoice of the program
+			return nil, errors.New("Permission Denied")
+		}
+
+		bytes, err := stub.GetState("closing_" + v.AnchorProgramID)
+		if err != nil || len(bytes) == 0 {
+			return nil, errors.New("QUERY: Program " + args[0] + " has not been closed")
+		}
+
+		fields := w.Redact
+		if !w.All && v.Owner != w.Account && v.POraisedBy != w.Account { // Interest is between the bank and the anchor
+			fields = append([]string{"interest"}, fields...)
+		}
+
+		return redact(bytes, fields)
+	} else if function == "get_payment_batches" {
+		if len(args) != 1 {
+			fmt.Printf("Incorrect number of arguments passed")
+			return nil, errors.New("QUERY: Incorrect number of arguments passed")
+		}
+
+		return t.get_payment_batches(stub, args[0], callerAccount, caller_affiliation)
+	} else if function == "get_freezes" {
+		return t.get_freezes(stub, callerAccount, caller_affiliation)
+	} else if function == "get_channel_catalogue" {
+		err := t.authorize(stub, PolicyRequest{Function: "view_channel_catalogue", CallerAccount: string(callerAccount), CallerRole: caller_affiliation, State: -1, Admin: t.is_admin(stub, string(callerAccount), caller_affiliation)})
+		if err != nil {
+			return nil, err
+		}
+
+		catalogue, err := t.retrieve_channel_catalogue(stub)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(catalogue)
+	} else if function == "get_sod_rules" {
+		rules, err := t.retrieve_sod_rules(stub)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(rules)
+	} else if function == "get_delegations" {
+		if len(args) != 1 {
+			fmt.Printf("Incorrect number of arguments passed")
+			return nil, errors.New("QUERY: Incorrect number of arguments passed")
+		}
+
+		return t.get_delegations(stub, args[0], callerAccount, caller_affiliation)
+	} else if function == "get_action_log" {
+		if len(args) != 1 {
+			fmt.Printf("Incorrect number of arguments passed")
+			return nil, errors.New("QUERY: Incorrect number of arguments passed")
+		}
+
+		return t.get_action_log(stub, args[0], callerAccount, caller_affiliation)
+	}
+
+	return nil, errors.New("Received unknown function invocation")
+
+}
+
+//=================================================================================================================================
+//	 Read Functions
+//=================================================================================================================================
+//	 get_invoice_details -----> get transaction details for a particular order invoice
+//=================================================================================================================================
+func (t *AssetManagementChaincode) get_invoice_details(stub shim.ChaincodeStubInterface, x MyBoxItem, w Viewer) ([]byte, error) {
+
+	v, _ := t.retrieve_anchorprogram(stub, x.POID) // Parties to the program can see its invoices
+
+	if !sees_invoice(w, x, v) {
+		return nil, errors.New("Permission Denied")
+	}
+
+	err := t.load_terms(stub, &v)
+	if err != nil {
+		return nil, err
+	}
+	invoice_terms(&x, v)
+
+	x.Frozen, err = t.program_freeze(stub, v)
+	if err != nil {
+		return nil, err
+	}
+
+	reveal_fields(w.Key, true, invoice_pii_fields(&x))
+
+	bytes, err := json.Marshal(x)
+	if err != nil {
+		return nil, errors.New("GET_INVOICE_DETAILS: Invalid Invoice object")
+	}
+
+	return redact(bytes, w.Redact)
+}
+
+//=================================================================================================================================
+//	 get_anchorprogram_details -----> get transaction details for a particular order
+//=================================================================================================================================
+func (t *AssetManagementChaincode) get_anchorprogram_details(stub shim.ChaincodeStubInterface, v AnchorProgram, w Viewer) ([]byte, error) {
+
+	if !sees_program(w, v) {
+		return nil, errors.New("Permission Denied")
+	}
+
+	err := t.load_terms(stub, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Frozen, err = t.program_freeze(stub, v)
+	if err != nil {
+		return nil, err
+	}
+
+	whole := whole_program(w, v)
+	if !whole {
+		v.Items = own_invoices(w, v)
+	}
+
+	reveal_fields(w.Key, whole, program_pii_fields(&v))
+	for i := range v.Items {
+		reveal_fields(w.Key, true, invoice_pii_fields(&v.Items[i]))
+		invoice_terms(&v.Items[i], v)
+	}
+
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.New("GET_ANCHORPROGRAM_DETAILS: Invalid AnchorProgram object")
+	}
+
+	return redact(bytes, w.Redact)
+}
+
+//=================================================================================================================================
+//	 Visibility - The one set of rules deciding who can read a program or invoice, used by every query
+//=================================================================================================================================
+//	 viewer - Works out what the caller can see. Roles with a view_all_records policy rule see every record; everyone
+//			  else sees the records they are a party to. Fields listed against the caller's role in the policy's
+//			  redactions are removed.
+//=================================================================================================================================
+func (t *AssetManagementChaincode) viewer(stub shim.ChaincodeStubInterface, callerAccount []byte, caller_affiliation string) (Viewer, error) {
+
+	w := Viewer{Account: string(callerAccount), Role: caller_affiliation}
+
+	policy, err := t.retrieve_access_policy(stub, "")
+	if err != nil {
+		return w, err
+	}
+
+	request := PolicyRequest{Function: "view_all_records", CallerAccount: w.Account, CallerRole: w.Role, State: -1}
+	request.Admin = t.is_admin(stub, w.Account, w.Role)
+
+	for _, rule := range policy.Rules {
+		if rule.Function == request.Function && rule_allows(rule, request) {
+			w.All = true
+		}
+	}
+
+	w.Redact = policy.Redactions[caller_affiliation]
+
+	w.Key, err = t.pii_key(stub)
+	if err != nil { // Details are masked rather than the query refused
+		fmt.Printf("VIEWER: %s", err)
+		w.Key = nil
+	}
+
+	return w, nil
+}
+
+//=================================================================================================================================
+//	 program_party / invoice_party - Whether account takes part in the program or invoice, now or earlier in its life
+//=================================================================================================================================
+func program_party(v AnchorProgram, account string) bool {
+
+	return account != "" &&
+		(v.Owner == account ||
+			v.POraisedBy == account ||
+			v.PORaisedAgainst == account)
+}
+
+func invoice_party(x MyBoxItem, account string) bool {
+
+	if account == "" {
+		return false
+	}
+
+	if x.MOOwner == account ||
+		x.InvoiceRaisedBy == account ||
+		x.InvoiceRaisedAgainst == account ||
+		x.PaymentMaker == account {
+		return true
+	}
+
+	for _, approval := range x.Approvals {
+		if approval.Account == account {
+			return true
+		}
+	}
+
+	return false
+}
+
+//=================================================================================================================================
+//	 sees_program / sees_invoice - Whether the viewer can read the record. Invoice parties see the program, but only
+//								   their own invoices in it, see whole_program; program parties see all its invoices.
+//=================================================================================================================================
+func sees_program(w Viewer, v AnchorProgram) bool {
+
+	if w.All || program_party(v, w.Account) {
+		return true
+	}
+
+	for _, item := range v.Items {
+		if invoice_party(item, w.Account) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sees_invoice(w Viewer, x MyBoxItem, v AnchorProgram) bool {
+
+	return w.All || invoice_party(x, w.Account) || program_party(v, w.Account)
+}
+
+//=================================================================================================================================
+//	 whole_program - Whether the viewer sees all of a program rather than only the invoices it takes part in
+//=================================================================================================================================
+func whole_program(w Viewer, v AnchorProgram) bool {
+
+	return w.All || program_party(v, w.Account)
+}
+
+//=================================================================================================================================
+//	 own_invoices - The invoices of a program the viewer takes part in
+//=================================================================================================================================
+func own_invoices(w Viewer, v AnchorProgram) []MyBoxItem {
+
+	items := []MyBoxItem{}
+
+	for _, item := range v.Items {
+		if invoice_party(item, w.Account) {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+//=================================================================================================================================
+//	 redact - Removes the named JSON fields from a record wherever they appear in it, including the invoices listed in a
+//			  program and any other nested objects and lists
+//=================================================================================================================================
+func redact(bytes []byte, fields []string) ([]byte, error) {
+
+	if len(fields) == 0 {
+		return bytes, nil
+	}
+
+	var record interface{}
+
+	err := json.Unmarshal(bytes, &record)
+	if err != nil {
+		return nil, errors.New("Unable to redact record")
+	}
+
+	redact_value(record, fields)
+
+	return json.Marshal(record)
+}
+
+func redact_value(value interface{}, fields []string) {
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for _, field := range fields {
+			delete(value, field)
+		}
+		for _, nested := range value {
+			redact_value(nested, fields)
+		}
+	case []interface{}:
+		for _, nested := range value {
+			redact_value(nested, fields)
+		}
+	}
+}
+
+//=================================================================================================================================
+//	 get_invoices ----> get details of all invoices
+//=================================================================================================================================
+
+func (t *AssetManagementChaincode) get_invoices(stub shim.ChaincodeStubInterface, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	bytes, err := stub.GetState("invoiceIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get invoiceIDs")
+	}
+
+	var invoiceIDs Invoice_Holder
+
+	err = json.Unmarshal(bytes, &invoiceIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Invoice_Holder")
+	}
+
+	w, err := t.viewer(stub, callerAccount, caller_affiliation)
+	if err != nil {
+		return nil, err
+	}
+
+	result := "["
+
+	var temp []byte
+	var v MyBoxItem
+
+	for _, po := range invoiceIDs.INVOICEs {
+
+		v, err = t.retrieve_invoice(stub, po)
+		if err != nil {
+			return nil, errors.New("Failed to retrieve Invoice")
+		}
+
+		temp, err = t.get_invoice_details(stub, v, w)
+		if err == nil {
+			result += string(temp) + ","
+		}
+	}
+
+	if len(result) == 1 {
+		result = "[]"
+	} else {
+		result = result[:len(result)-1] + "]"
+	}
+
+	return []byte(result), nil
+}
+
+//=================================================================================================================================
+//	 get_anchorprograms ----> get details of all orders
+//=================================================================================================================================
+
+func (t *AssetManagementChaincode) get_anchorprograms(stub shim.ChaincodeStubInterface, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	bytes, err := stub.GetState("anchorProgramIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get anchorProgramIDs")
+	}
+
+	var anchorProgramIDs Anchor_Program_Holder
+
+	err = json.Unmarshal(bytes, &anchorProgramIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Anchor_Program_Holder")
+	}
+
+	w, err := t.viewer(stub, callerAccount, caller_affiliation)
+	if err != nil {
+		return nil, err
+	}
+
+	result := "["
+
+	var temp []byte
+	var v AnchorProgram
+
+	for _, po := range anchorProgramIDs.ANCHOR_PROGRAMs {
+
+		v, err = t.retrieve_anchorprogram(stub, po)
+		if err != nil {
+			return nil, errors.New("Failed to retrieve Anchor Program")
+		}
+
+		temp, err = t.get_anchorprogram_details(stub, v, w)
+		if err == nil {
+			result += string(temp) + ","
+		}
+	}
+
+	if len(result) == 1 {
+		result = "[]"
+	} else {
+		result = result[:len(result)-1] + "]"
+	}
+
+	return []byte(result), nil
+}
+
+//=================================================================================================================================
+//	 get_anchorprogramIDs ----> get ID details of all orders
+//=================================================================================================================================
+
+func (t *AssetManagementChaincode) get_anchorprogramIDs(stub shim.ChaincodeStubInterface, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	bytes, err := stub.GetState("anchorProgramIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get anchorProgramIDs")
+	}
+
+	var anchorProgramIDs Anchor_Program_Holder
+
+	err = json.Unmarshal(bytes, &anchorProgramIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Anchor_Program_Holder")
+	}
+
+	w, err := t.viewer(stub, callerAccount, caller_affiliation)
+	if err != nil {
+		return nil, err
+	}
+
+	result := "["
+
+	var v AnchorProgram
+	var list ProgramIDs
+
+	for _, po := range anchorProgramIDs.ANCHOR_PROGRAMs {
+
+		v, err = t.retrieve_anchorprogram(stub, po)
+		if err != nil {
+			return nil, errors.New("Failed to retrieve Anchor Program")
+		}
+
+		if sees_program(w, v) {
+
+			err = t.load_terms(stub, &v)
+			if err != nil {
+				return nil, err
+			}
+
+			allowed := w.All || // Only those who can see the program itself see the details in clear
+				v.Owner == w.Account ||
+				v.POraisedBy == w.Account
+
+			reveal_fields(w.Key, allowed, program_pii_fields(&v))
+
+			list.AnchorID = v.AnchorID
+			list.POraisedBy = v.POraisedBy
+			list.AnchorName = v.AnchorName
+			list.AnchorAccountNo = v.AnchorAccountNo
+			list.AnchorPOAmount = v.AnchorPOAmount
+			list.AnchorIFSCCode = v.AnchorIFSCCode
+			list.AnchorLimit = v.AnchorLimit
+			list.AnchorExpiryDate = v.AnchorExpiryDate
+			list.AnchorInterest = v.AnchorInterest
+			list.AnchorGarceInterest = v.AnchorGarceInterest
+			list.AnchorGarceInterestperiod = v.AnchorGarceInterestperiod
+			list.AnchorPenalInterest = v.AnchorPenalInterest
+			list.AnchorLiquidation = v.AnchorLiquidation
+			list.AnchorPoID = v.AnchorPoID
+			list.VendorID = v.VendorID
+			list.VendorFName = v.VendorFName
+			list.VendorLName = v.VendorLName
+			list.Vendoremail = v.Vendoremail
+			list.Vendorphone = v.Vendorphone
+			list.Vendorpanno = v.Vendorpanno
+			list.Vendorgstin = v.Vendorgstin
+			list.Vendoraddress = v.Vendoraddress
+			list.Vendorbank = v.Vendorbank
+			list.Vendorbaddress = v.Vendorbaddress
+			list.Vendoraccountno = v.Vendoraccountno
+			list.Vendorifsccode = v.Vendorifsccode
+			list.Vendorlimit = v.Vendorlimit
+			list.VendorExpirydate = v.VendorExpirydate
+			list.POAcknowledged = v.POAcknowledged
+			//list.POTimestamp = v.POTimestamp
+			list.Status = v.Status
+			list.AnchorProgramID = v.AnchorProgramID
+			list.Settled = v.Settled
+			list.Frozen, _ = t.program_freeze(stub, v)
+
+			temp, err := json.Marshal(list)
+			if err == nil {
+				temp, err = redact(temp, w.Redact)
+			}
+
+			if err == nil {
+				result += string(temp) + ","
+			}
+		}
+	}
+
+	if len(result) == 1 {
+		result = "[]"
+	} else {
+		result = result[:len(result)-1] + "]"
+	}
+
+	return []byte(result), nil
+}
+
+//=================================================================================================================================
+//	 get_invoiceIDs ----> get ID details of all orders
+//=================================================================================================================================
+
+func (t *AssetManagementChaincode) get_invoiceIDs(stub shim.ChaincodeStubInterface, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	bytes, err := stub.GetState("invoiceIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get invoiceIDs")
+	}
+
+	var invoiceIDs Invoice_Holder
+
+	err = json.Unmarshal(bytes, &invoiceIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Invoice_Holder")
+	}
+
+	w, err := t.viewer(stub, callerAccount, caller_affiliation)
+	if err != nil {
+		return nil, err
+	}
+
+	programs := make(map[string]AnchorProgram)
+
+	result := "["
+
+	var v MyBoxItem
+	var list InvoiceIDs
+
+	for _, po := range invoiceIDs.INVOICEs {
+
+		v, err = t.retrieve_invoice(stub, po)
+		if err != nil {
+			return nil, errors.New("Failed to retrieve Invoice")
+		}
+
+		program, ok := programs[v.POID]
+		if !ok {
+			program, _ = t.retrieve_anchorprogram(stub, v.POID)
+			err = t.load_terms(stub, &program)
+			if err != nil {
+				return nil, err
+			}
+			programs[v.POID] = program
+		}
+
+		if sees_invoice(w, v, program) {
+
+			allowed := w.All || // Only those who can see the invoice itself see the details in clear
+				v.MOOwner == w.Account ||
+				v.InvoiceRaisedBy == w.Account
+
+			reveal_fields(w.Key, allowed, invoice_pii_fields(&v))
+			invoice_terms(&v, program)
+
+			list.POID = v.POID
+			list.MOID = v.MOID
+			list.MOOwner = v.MOOwner
+			list.AnchorName = v.AnchorName
+			list.AnchorAccountNo = v.AnchorAccountNo
+			list.AnchorPOAmount = v.AnchorPOAmount
+			list.AnchorIFSCCode = v.AnchorIFSCCode
+			list.AnchorInterest = v.AnchorInterest
+			list.InvoiceRaisedBy = v.InvoiceRaisedBy
+			list.InvoiceRaisedAgainst = v.InvoiceRaisedAgainst
+			list.MOAmount = v.MOAmount
+			list.InvoiceID = v.InvoiceID
+			list.ApprovedInvoiceAmount = v.ApprovedInvoiceAmount
+			list.MOStatus = v.MOStatus
+			list.CheckerApprovedPayment = v.CheckerApprovedPayment
+			//list.MOTimestamp = v.MOTimestamp
+			list.MOPaid = v.MOPaid
+			list.UTRNumber = v.UTRNumber
+			list.MOSettled = v.MOSettled
+			list.MatchResult = v.MatchResult
+			list.InvoiceDate = v.InvoiceDate
+			list.Frozen, _ = t.program_freeze(stub, program)
+			list.Vendorfname = v.Vendorfname
+			list.Vendorbank = v.Vendorbank
+			list.Vendorifsccode = v.Vendorifsccode
+			list.AnchorPoID = v.AnchorPoID
+
+			temp, err := json.Marshal(list)
+			if err == nil {
+				temp, err = redact(temp, w.Redact)
+			}
+
+			if err == nil {
+				result += string(temp) + ","
+			}
+		}
+	}
+
+	if len(result) == 1 {
+		result = "[]"
+	} else {
+		result = result[:len(result)-1] + "]"
+	}
+
+	return []byte(result), nil
+}
+
+//=================================================================================================================================
+//	 get_access_policy ----> get the current access policy, or an earlier version of it
+//=================================================================================================================================
+
+func (t *AssetManagementChaincode) get_access_policy(stub shim.ChaincodeStubInterface, version string) ([]byte, error) {
+
+	policy, err := t.retrieve_access_policy(stub, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(policy)
+}
+
+//=================================================================================================================================
+//	 find_participants ----> get the active participants with a role and/or in an organization, to look up counterparties
+//=================================================================================================================================
+
+func (t *AssetManagementChaincode) find_participants(stub shim.ChaincodeStubInterface, role, org string) ([]byte, error) {
+
+	bytes, err := stub.GetState("participantIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get participantIDs")
+	}
+
+	var participantIDs Participant_Holder
+
+	if len(bytes) != 0 {
+		err = json.Unmarshal(bytes, &participantIDs)
+		if err != nil {
+			return nil, errors.New("Corrupt Participant_Holder")
+		}
+	}
+
+	participants := []Participant{}
+
+	for _, account := range participantIDs.PARTICIPANTs {
+
+		p, err := t.retrieve_participant(stub, account)
+		if err != nil {
+			return nil, err
+		}
+
+		has_role := role == ""
+		for _, r := range p.Roles {
+			if r == role {
+				has_role = true
+			}
+		}
+
+		if p.Status == PARTICIPANT_ACTIVE && has_role && (org == "" || p.Organization == org) {
+			participants = append(participants, p)
+		}
+	}
+
+	return json.Marshal(participants)
+}
+
+//=================================================================================================================================
+//	 get_kyc ----> get a vendor's KYC record, for the vendor's account and roles with a view_kyc_records policy rule
+//=================================================================================================================================
+
+func (t *AssetManagementChaincode) get_kyc(stub shim.ChaincodeStubInterface, vendorID string, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	r, err := t.retrieve_kyc(stub, vendorID)
+	if err != nil {
+		return nil, err
+	}
+
+	officer := t.authorize(stub, PolicyRequest{Function: "view_kyc_records", CallerAccount: string(callerAccount), CallerRole: caller_affiliation, State: -1, Admin: t.is_admin(stub, string(callerAccount), caller_affiliation)}) == nil
+
+	if !officer && r.Account != string(callerAccount) {
+		return nil, errors.New("Permission Denied")
+	}
+
+	key, err := t.pii_key(stub)
+	if err != nil { // Masked rather than refused, see reveal_fields
+		fmt.Printf("GET_KYC: %s", err)
+		key = nil
+	}
+
+	for i := range r.Items {
+		reveal_fields(key, true, []PIIField{{Name: "kyc_" + r.Items[i].ItemType, Value: &r.Items[i].Data}})
+	}
+
+	return json.Marshal(r)
+}
+
+//=================================================================================================================================
+//	 get_kyc_due ----> get the vendors whose KYC must be redone by a date (YYYY-MM-DD, default today), with those not
+//					   verified at all, for roles with a view_kyc_records policy rule
+//=================================================================================================================================
+
+func (t *AssetManagementChaincode) get_kyc_due(stub shim.ChaincodeStubInterface, by string, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	err := t.authorize(stub, PolicyRequest{Function: "view_kyc_records", CallerAccount: string(callerAccount), CallerRole: caller_affiliation, State: -1, Admin: t.is_admin(stub, string(callerAccount), caller_affiliation)})
+	if err != nil {
+		return nil, err
+	}
+
+	if by == "" {
+		now, err := tx_timestamp(stub)
+		if err != nil {
+			return nil, err
+		}
+		by = now[:10]
+	}
+
+	bytes, err := stub.GetState("kycIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get kycIDs")
+	}
+
+	var kycIDs KYC_Holder
+
+	if len(bytes) != 0 {
+		err = json.Unmarshal(bytes, &kycIDs)
+		if err != nil {
+			return nil, errors.New("Corrupt KYC_Holder")
+		}
+	}
+
+	due := []KYCDue{}
+
+	for _, vendorID := range kycIDs.VENDORs {
+
+		r, err := t.retrieve_kyc(stub, vendorID)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.Status != KYC_VERIFIED || r.ExpiryDate <= by {
+			due = append(due, KYCDue{VendorID: r.VendorID, Account: r.Account, Status: r.Status, ExpiryDate: r.ExpiryDate})
+		}
+	}
+
+	return json.Marshal(due)
+}
+
+//=================================================================================================================================
+//	 get_failed_payments ----> get every invoice the caller can see with a failed payment attempt, escalated or not
+//=================================================================================================================================
+func (t *AssetManagementChaincode) get_failed_payments(stub shim.ChaincodeStubInterface, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	bytes, err := stub.GetState("anchorProgramIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get anchorProgramIDs")
+	}
+
+	var anchorProgramIDs Anchor_Program_Holder
+
+	err = json.Unmarshal(bytes, &anchorProgramIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Anchor_Program_Holder")
+	}
+
+	w, err := t.viewer(stub, callerAccount, caller_affiliation)
+	if err != nil {
+		return nil, err
+	}
+
+	result := "["
+
+	for _, po := range anchorProgramIDs.ANCHOR_PROGRAMs {
+
+		v, err := t.retrieve_anchorprogram(stub, po)
+		if err != nil {
+			return nil, errors.New("Failed to retrieve Anchor Program")
+		}
+
+		for _, item := range v.Items {
+
+			x, err := t.retrieve_invoice(stub, item.MOID)
+			if err != nil {
+				continue
+			}
+
+			failed := false
+			for _, attempt := range x.PaymentAttempts {
+				if attempt.Status != "SUCCESS" {
+					failed = true
+				}
+			}
+
+			if !failed {
+				continue
+			}
+
+			temp, err := t.get_invoice_details(stub, x, w)
+			if err == nil {
+				result += string(temp) + ","
+			}
+		}
+	}
+
+	if len(result) == 1 {
+		result = "[]"
+	} else {
+		result = result[:len(result)-1] + "]"
+	}
+
+	return []byte(result), nil
+}
+
+//=================================================================================================================================
+//	 get_payment_batches ----> get the payment batches of a program the caller can see
+//=================================================================================================================================
+func (t *AssetManagementChaincode) get_payment_batches(stub shim.ChaincodeStubInterface, programID string, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	v, err := t.retrieve_anchorprogram(stub, programID)
+	if err != nil {
+		return nil, errors.New("QUERY: Error retrieving anchorprogram " + err.Error())
+	}
+
+	w, err := t.viewer(stub, callerAccount, caller_affiliation)
+	if err != nil {
+		return nil, err
+	}
+
+	if !sees_program(w, v) {
+		return nil, errors.New("Permission Denied")
+	}
+
+	batches := []PaymentBatch{}
+
+	for _, batchID := range v.PaymentBatches {
+
+		b, err := t.retrieve_batch(stub, batchID)
+		if err != nil {
+			return nil, err
+		}
+
+		if whole_program(w, v) || b.Maker == w.Account || b.Checker == w.Account {
+			batches = append(batches, b)
+		}
+	}
+
+	return json.Marshal(batches)
+}
+
+//=================================================================================================================================
+//	 get_journals ----> get the journal entries posted between two dates, inclusive, given as YYYY-MM-DD
+//=================================================================================================================================
+func (t *AssetManagementChaincode) get_journals(stub shim.ChaincodeStubInterface, from string, to string, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	err := t.authorize(stub, PolicyRequest{Function: "view_journals", CallerAccount: string(callerAccount), CallerRole: caller_affiliation, State: -1, Admin: t.is_admin(stub, string(callerAccount), caller_affiliation)})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, date := range []string{from, to} {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, errors.New("Invalid date " + date + ", expected YYYY-MM-DD")
+		}
+	}
+
+	iter, err := stub.RangeQueryState("journal_"+from, "journal_"+to+"_~") // "~" sorts after every transaction ID
+	if err != nil {
+		return nil, errors.New("Unable to read journal entries")
+	}
+	defer iter.Close()
+
+	entries := []JournalEntry{}
+
+	for iter.HasNext() {
+
+		_, bytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read journal entries")
+		}
+
+		var e JournalEntry
+
+		err = json.Unmarshal(bytes, &e)
+		if err != nil {
+			return nil, errors.New("Corrupt journal entry")
+		}
+
+		entries = append(entries, e)
+	}
+
+	return json.Marshal(entries)
+}
+
+//=================================================================================================================================
+//	 get_freezes ----> get every freeze, in force or lifted, for roles with a view_freezes policy rule
+//=================================================================================================================================
+
+func (t *AssetManagementChaincode) get_freezes(stub shim.ChaincodeStubInterface, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	err := t.authorize(stub, PolicyRequest{Function: "view_freezes", CallerAccount: string(callerAccount), CallerRole: caller_affiliation, State: -1, Admin: t.is_admin(stub, string(callerAccount), caller_affiliation)})
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := stub.GetState("freezeIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get freezeIDs")
+	}
+
+	var freezeIDs Freeze_Holder
+
+	if len(bytes) != 0 {
+		err = json.Unmarshal(bytes, &freezeIDs)
+		if err != nil {
+			return nil, errors.New("Corrupt Freeze_Holder")
+		}
+	}
+
+	freezes := []Freeze{}
+
+	for _, id := range freezeIDs.FREEZEs {
+
+		bytes, err := stub.GetState("freeze_" + id)
+		if err != nil {
+			return nil, errors.New("Unable to get freeze " + id)
+		}
+
+		var f Freeze
+
+		err = json.Unmarshal(bytes, &f)
+		if err != nil {
+			return nil, errors.New("Corrupt freeze record " + id)
+		}
+
+		freezes = append(freezes, f)
+	}
+
+	return json.Marshal(freezes)
+}
+
+//=================================================================================================================================
+//	 get_delegations ----> get the delegations granted by a principal, as seen by the principal, their delegates and admins
+//=================================================================================================================================
+
+func (t *AssetManagementChaincode) get_delegations(stub shim.ChaincodeStubInterface, principal string, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	bytes, err := stub.GetState("delegations_" + principal)
+	if err != nil {
+		return nil, errors.New("Unable to get delegations")
+	}
+
+	var holder Delegation_Holder
+
+	if len(bytes) != 0 {
+		err = json.Unmarshal(bytes, &holder)
+		if err != nil {
+			return nil, errors.New("Corrupt Delegation_Holder")
+		}
+	}
+
+	delegations := []Delegation{}
+
+	for _, id := range holder.DELEGATIONs {
+
+		d, err := t.retrieve_delegation(stub, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if d.Principal == string(callerAccount) ||
+			d.Delegate == string(callerAccount) ||
+			t.is_admin(stub, string(callerAccount), caller_affiliation) {
+			delegations = append(delegations, d)
+		}
+	}
+
+	return json.Marshal(delegations)
+}
+
+//=================================================================================================================================
+//	 get_action_log ----> get who acted on a program or invoice, and for whom, for the accounts in the log and admins
+//=================================================================================================================================
+
+func (t *AssetManagementChaincode) get_action_log(stub shim.ChaincodeStubInterface, recordID string, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	bytes, err := stub.GetState("actions_" + recordID)
+	if err != nil {
+		return nil, errors.New("Unable to get action log")
+	}
+
+	actions := []ActionRecord{}
+
+	if len(bytes) != 0 {
+		err = json.Unmarshal(bytes, &actions)
+		if err != nil {
+			return nil, errors.New("Corrupt action log for " + recordID)
+		}
+	}
+
+	allowed := t.is_admin(stub, string(callerAccount), caller_affiliation)
+	for _, action := range actions {
+		if action.Account == string(callerAccount) || action.Delegate == string(callerAccount) {
+			allowed = true
+		}
+	}
+
+	if !allowed {
+		return nil, errors.New("Permission Denied")
+	}
+
+	return json.Marshal(actions)
+}
+
+//=================================================================================================================================
+//	 verify_document ----> check a file's SHA-256 against the document recorded on a program or invoice, or against the
+//						   one it held after the transaction txID, for the parties who can see the record
+//=================================================================================================================================
+
+func (t *AssetManagementChaincode) verify_document(stub shim.ChaincodeStubInterface, recordID string, hash string, txID string, w Viewer) ([]byte, error) {
+
+	var document DocumentRef
+	var legacy string
+
+	// Programs and invoices share the key space, an invoice record is the one that carries its own ID in moID
+	x, err := t.retrieve_invoice(stub, recordID)
+	if err == nil && x.MOID == recordID {
+		v, err := t.retrieve_anchorprogram(stub, x.POID)
+		if err != nil || !sees_invoice(w, x, v) {
+			return nil, errors.New("Permission Denied")
+		}
+		document, legacy = x.InvoiceDocument, x.InvoiceImage
+	} else {
+		v, err := t.retrieve_anchorprogram(stub, recordID)
+		if err != nil || v.AnchorProgramID != recordID {
+			return nil, errors.New("QUERY: No program or invoice with ID " + recordID)
+		}
+		if !whole_program(w, v) {
+			return nil, errors.New("Permission Denied")
+		}
+		document, legacy = v.AnchorPoDocument, v.AnchorPoImage
+	}
+
+	var result DocumentVerification
+
+	if txID != "" {
+		bytes, err := stub.GetState("actions_" + recordID)
+		if err != nil {
+			return nil, errors.New("Unable to get action log")
+		}
+
+		var actions []ActionRecord
+
+		if len(bytes) != 0 {
+			err = json.Unmarshal(bytes, &actions)
+			if err != nil {
+				return nil, errors.New("Corrupt action log for " + recordID)
+			}
+		}
+
+		found := false
+		for _, action := range actions {
+			if action.TxID == txID {
+				document, legacy, found = action.Document, "", true
+				result.TxID = txID
+				result.Function = action.Function
+			}
+		}
+
+		if !found {
+			return nil, errors.New("QUERY: No action on " + recordID + " in transaction " + txID)
+		}
+	}
+
+	if document.Hash == "" {
+		if legacy != "" && legacy != "UNDEFINED" {
+			return nil, errors.New("QUERY: The document on " + recordID + " was attached before document references and has no hash to verify")
+		}
+		return nil, errors.New("QUERY: No document recorded on " + recordID)
+	}
+
+	result.RecordID = recordID
+	result.Matches = strings.ToLower(strings.TrimSpace(hash)) == document.Hash
+	result.Hash = document.Hash
+	result.MediaType = document.MediaType
+	result.Size = document.Size
+	result.RecordedAt = document.RecordedAt
+	result.RecordedBy = document.RecordedBy
+
+	return json.Marshal(result)
+}
+
+//=================================================================================================================================
+//	 get_suspected_duplicate_invoices ----> get live invoices that look alike but were not caught by the fingerprint index
+//=================================================================================================================================
+
+func (t *AssetManagementChaincode) get_suspected_duplicate_invoices(stub shim.ChaincodeStubInterface, callerAccount []byte, caller_affiliation string) ([]byte, error) {
+
+	if !t.is_admin(stub, string(callerAccount), caller_affiliation) {
+		return nil, errors.New("Permission Denied")
+	}
+
+	bytes, err := stub.GetState("invoiceIDs")
+	if err != nil {
+		return nil, errors.New("Unable to get invoiceIDs")
+	}
+
+	var invoiceIDs Invoice_Holder
+
+	err = json.Unmarshal(bytes, &invoiceIDs)
+	if err != nil {
+		return nil, errors.New("Corrupt Invoice_Holder")
+	}
+
+	programs := make(map[string]AnchorProgram)
+	groups := make(map[string]*SuspectedDuplicate)
+	roots := make(map[string]map[string]bool)
+	var keys []string
+
+	add := func(reason, key string, x MyBoxItem, root string) {
+		id := reason + "|" + key
+		if groups[id] == nil {
+			groups[id] = &SuspectedDuplicate{Reason: reason, Key: key}
+			roots[id] = make(map[string]bool)
+			keys = append(keys, id)
+		}
+		if roots[id][root] { // Revisions of one invoice are not duplicates of each other
+			return
+		}
+		roots[id][root] = true
+		groups[id].Invoices = append(groups[id].Invoices, DuplicateInvoice{x.POID, x.MOID, x.InvoiceID, x.InvoiceDate, x.MOAmount, x.MOStatus})
+	}
+
+	for _, moid := range invoiceIDs.INVOICEs {
+
+		x, err := t.retrieve_invoice(stub, moid)
+		if err != nil {
+			return nil, errors.New("Failed to retrieve Invoice")
+		}
+
+		if x.MOStatus == STATE_INVOICE_RETIRED ||
+			x.InvoiceID == "" ||
+			x.InvoiceID == "UNDEFINED" {
+			continue
+		}
+
+		v, ok := programs[x.POID]
+		if !ok {
+			v, err = t.retrieve_anchorprogram(stub, x.POID)
+			if err != nil {
+				return nil, errors.New("Failed to retrieve Anchor Program")
+			}
+			programs[x.POID] = v
+		}
+
+		vendor := vendor_key(v)
+		root := t.invoice_root(stub, x)
+
+		add("SIMILAR_INVOICE_NUMBER", vendor+"|"+loose_invoice_number(x.InvoiceID), x, root)
+
+		if x.InvoiceDate != "" {
+			add("SAME_AMOUNT_AND_DATE", vendor+"|"+x.InvoiceDate+"|"+strconv.FormatFloat(x.MOAmount, 'f', 2, 64), x, root)
+		}
+	}
+
+	var suspects []SuspectedDuplicate
+	for _, id := range keys {
+		if len(groups[id].Invoices) > 1 {
+			suspects = append(suspects, *groups[id])
+		}
+	}
+
+	if suspects == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal(suspects)
+}
+
+//=================================================================================================================================
+//	 loose_invoice_number - Reduces an invoice number to upper case letters and digits with leading zeros dropped from each
+//							run of digits, so "inv/0042" and "INV-42" compare equal
+//=================================================================================================================================
+func loose_invoice_number(invID string) string {
+
+	var result []rune
+	in_digits := false
+
+	for _, r := range strings.ToUpper(invID) {
+		switch {
+		case r >= '0' && r <= '9':
+			if r == '0' && !in_digits {
+				continue
+			}
+			in_digits = true
+			result = append(result, r)
+		case r >= 'A' && r <= 'Z':
+			in_digits = false
+			result = append(result, r)
+		default:
+			in_digits = false
+		}
+	}
+
+	return string(result)
+}
+
+//=================================================================================================================================
+//	 Main - main - Starts up the chaincode
+//=================================================================================================================================
+func main() {
+	err := shim.Start(new(AssetManagementChaincode))
+	if err != nil {
+		fmt.Printf("Error starting AssetManagementChaincode: %s", err)
+	}
+}